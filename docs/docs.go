@@ -1,6 +1,6 @@
 // GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
 // This file was generated by swaggo/swag at
-// 2025-01-17 03:37:15.925012523 +0000 UTC m=+0.038760387
+// 2026-10-17 21:20:14.113620332 +0000 UTC m=+0.175060065
 
 package docs
 
@@ -81,7 +81,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -147,7 +147,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -213,7 +213,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -270,7 +270,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -337,7 +337,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -406,7 +406,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -472,7 +472,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -541,7 +541,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -607,7 +607,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -673,7 +673,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -818,7 +818,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -965,7 +965,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1034,7 +1034,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1091,7 +1091,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1210,7 +1210,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1291,7 +1291,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1311,7 +1311,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List images in nchcai/train dockerhub repo",
+                "description": "List images in repositories of configured registries, which is nchcai/train dockerhub repo by default,\nuser's repository and images committed by user. Size, digest, architectures, last updated time, description\nand gpu are given as far as registry tells. Pinned name@digest can be used as course image instead of mutable tag.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1321,7 +1321,21 @@ var doc = `{
                 "tags": [
                     "Image"
                 ],
-                "summary": "List images in nchcai/train dockerhub repo",
+                "summary": "List images in configured registries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only list images built for architecture, eg: amd64",
+                        "name": "arch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "recent: most recently updated first",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1341,7 +1355,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1361,7 +1375,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Commit current container into new image",
+                "description": "Commit container of running job into new image, which is pushed to configured registry by a builder job in background.\nOnly owner of the course can commit the job. Committed image is listed in images of course owner once it is succeeded.\nImage is pushed under repository of course owner, which is prefixed to image name if it is not given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1389,7 +1403,14 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericOKResponse"
+                            "$ref": "#/definitions/docs.ImageCommitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
                         }
                     },
                     "401": {
@@ -1403,7 +1424,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1416,14 +1437,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/job/delete/{id}": {
-            "delete": {
+        "/beta/images/commit/get/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a running job deployment in user namespace",
+                "description": "Get status of an image commit, message is set if it is failed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1431,13 +1452,13 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Job"
+                    "Image"
                 ],
-                "summary": "Delete a course CRD in user namespace",
+                "summary": "Get status of an image commit",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "description": "image commit uuid",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1448,7 +1469,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericOKResponse"
+                            "$ref": "#/definitions/docs.ImageCommitResponse"
                         }
                     },
                     "400": {
@@ -1469,7 +1490,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1482,14 +1503,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/job/launch": {
-            "post": {
+        "/beta/images/commit/list": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Create a course CRD in kubernetes",
+                "description": "List image commits of user, newest first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1497,19 +1518,15 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Job"
+                    "Image"
                 ],
-                "summary": "Create a course CRD in kubernetes",
+                "summary": "List someone's image commits",
                 "parameters": [
                     {
-                        "description": "course want to launch",
-                        "name": "launch_course",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/docs.LaunchCourseRequest"
-                        }
+                        "type": "string",
+                        "description": "user name, only used when secure api is disabled",
+                        "name": "user",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1517,7 +1534,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.LaunchCourseResponse"
+                            "$ref": "#/definitions/docs.ImageCommitListResponse"
                         }
                     },
                     "400": {
@@ -1538,7 +1555,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1551,42 +1568,41 @@ var doc = `{
                 }
             }
         },
-        "/beta/job/list": {
-            "post": {
+        "/beta/images/commit/logs/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "List all running course deployment for a user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get logs of builder job in plain text. Logs are streamed from builder job while commit is running,\nand the end of logs kept after it is finished is returned otherwise.",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "tags": [
-                    "Job"
+                    "Image"
                 ],
-                "summary": "List all running course deployment for a user",
+                "summary": "Get logs of an image commit",
                 "parameters": [
                     {
-                        "description": "search user's job",
-                        "name": "list_user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/docs.OauthUser"
-                        }
+                        "type": "string",
+                        "description": "image commit uuid",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "stream new logs while commit is running",
+                        "name": "follow",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "builder logs",
                         "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/docs.JobListResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -1607,7 +1623,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1620,14 +1636,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/changePW": {
-            "post": {
+        "/beta/images/policy/delete": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Change user password",
+                "description": "Delete an image pattern from global allowlist, or allowlist of classroom.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1635,18 +1651,18 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Image"
                 ],
-                "summary": "Change user password",
+                "summary": "Delete image rule",
                 "parameters": [
                     {
-                        "description": "new password",
-                        "name": "user_password",
+                        "description": "scope, target and pattern of rule",
+                        "name": "rule",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.PasswordInfo"
+                            "$ref": "#/definitions/docs.ImageRuleTarget"
                         }
                     }
                 ],
@@ -1655,7 +1671,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.PlainResponse"
+                            "$ref": "#/definitions/docs.GenericOKResponse"
                         }
                     },
                     "400": {
@@ -1676,7 +1692,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1689,9 +1705,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/introspection": {
-            "post": {
-                "description": "Get token meta information from provider",
+        "/beta/images/policy/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List global image rules maintained by superuser, and rules of classroom if classroom is given.\nAll images are allowed if there is no global rule, and classroom rules narrow global rules.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1699,19 +1720,15 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Image"
                 ],
-                "summary": "Get token meta information from provider",
+                "summary": "List image allowlist",
                 "parameters": [
                     {
-                        "description": "Introspection token",
-                        "name": "Introspection_token",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/docs.IntrospectionReq"
-                        }
+                        "type": "string",
+                        "description": "classroom id",
+                        "name": "classroom",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1719,16 +1736,23 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.IntrospectResponse"
+                            "$ref": "#/definitions/docs.ImageRuleListResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "$ref": "#/definitions/docs.GenericErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1739,14 +1763,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/logout": {
-            "post": {
+        "/beta/images/policy/set": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Revoke all tokens of a user",
+                "description": "Add or update an image pattern in global allowlist, or allowlist of classroom. Global rule can only be set by superuser,\nand classroom rule by teacher of classroom. Pattern is image reference with optional wildcards, eg: nchcai/train:v1,\nnchcai/train, nchcai/train:v1.*, nchcai/*, harbor.example.com/ai/** or nchcai/train@sha256:\u003cdigest\u003e.\nDocker Hub names are normalized, eg: docker.io/library/ubuntu is ubuntu, and docker.io/** matches any image on Docker Hub.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1754,18 +1778,18 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Image"
                 ],
-                "summary": "Revoke all tokens of a user",
+                "summary": "Add image rule",
                 "parameters": [
                     {
-                        "description": "user token",
-                        "name": "user_token",
+                        "description": "scope is global or classroom, target is classroom id for classroom scope",
+                        "name": "rule",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.IntrospectionReq"
+                            "$ref": "#/definitions/docs.ImageRule"
                         }
                     }
                 ],
@@ -1774,7 +1798,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.PlainResponse"
+                            "$ref": "#/definitions/docs.GenericOKResponse"
                         }
                     },
                     "400": {
@@ -1795,7 +1819,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1808,14 +1832,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/query": {
-            "get": {
+        "/beta/images/refresh": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "query a existing user information",
+                "description": "Fetch images of repositories from registries and update cache right away. All repositories listed so far are refreshed\nif registry and repository are not given. Cache is kept for repositories failed to be fetched.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1823,22 +1847,29 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Image"
+                ],
+                "summary": "Refresh image catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name of registry to refresh",
+                        "name": "registry",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "repository to refresh",
+                        "name": "repository",
+                        "in": "query"
+                    }
                 ],
-                "summary": "query a existing user information",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.UserInfo"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ImageCatalogRefreshResponse"
                         }
                     },
                     "401": {
@@ -1852,7 +1883,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -1865,9 +1896,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/refresh": {
+        "/beta/job/classroom/launch": {
             "post": {
-                "description": "Refresh token with provider",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Launch container course for every student of classroom, with quota and classroom schedule checked for each student.\nStudents already running the course are skipped. Jobs wait in launch queue like jobs launched by students,\nif free GPU is not enough. Result of each student is reported.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1875,18 +1911,18 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Job"
                 ],
-                "summary": "Refresh token with provider",
+                "summary": "Launch a course for all students of classroom",
                 "parameters": [
                     {
-                        "description": "refresh token",
-                        "name": "refresh_token",
+                        "description": "classroom and course to launch",
+                        "name": "launch_classroom",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.RefreshTokenReq"
+                            "$ref": "#/definitions/docs.ClassroomJobRequest"
                         }
                     }
                 ],
@@ -1895,7 +1931,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.TokenResp"
+                            "$ref": "#/definitions/docs.ClassroomJobResponse"
                         }
                     },
                     "400": {
@@ -1905,6 +1941,20 @@ var doc = `{
                             "$ref": "#/definitions/docs.GenericErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1915,9 +1965,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/register": {
-            "post": {
-                "description": "Register a new user",
+        "/beta/job/classroom/list/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List every job running in classroom with owner, status, start time, course, gpu and access URL, for classroom teacher.\nJob can be stopped by teacher with /beta/job/delete/{id}.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1925,19 +1980,16 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Job"
                 ],
-                "summary": "Register a new user",
+                "summary": "List jobs of all members in classroom",
                 "parameters": [
                     {
-                        "description": "user information",
-                        "name": "new_user_info",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "$ref": "#/definitions/docs.UserInfo"
-                        }
+                        "type": "string",
+                        "description": "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1945,7 +1997,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.PlainResponse"
+                            "$ref": "#/definitions/docs.ClassroomJobListResponse"
                         }
                     },
                     "400": {
@@ -1955,6 +2007,20 @@ var doc = `{
                             "$ref": "#/definitions/docs.GenericErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1965,9 +2031,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/token": {
-            "post": {
-                "description": "Exchange token from Provider",
+        "/beta/job/classroom/stop": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete every running job of course in classroom, and cancel every launch request of the course still waiting in launch queue.\nResult of each job is reported.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1975,18 +2046,18 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Job"
                 ],
-                "summary": "Exchange token from Provider",
+                "summary": "Stop a course of all members in classroom",
                 "parameters": [
                     {
-                        "description": "token request",
-                        "name": "token_request",
+                        "description": "classroom and course to stop",
+                        "name": "stop_classroom",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.TokenReq"
+                            "$ref": "#/definitions/docs.ClassroomJobRequest"
                         }
                     }
                 ],
@@ -1995,7 +2066,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.TokenResp"
+                            "$ref": "#/definitions/docs.ClassroomJobResponse"
                         }
                     },
                     "400": {
@@ -2005,9 +2076,23 @@ var doc = `{
                             "$ref": "#/definitions/docs.GenericErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
                             "type": "object",
                             "$ref": "#/definitions/docs.GenericErrorResponse"
                         }
@@ -2015,14 +2100,244 @@ var doc = `{
                 }
             }
         },
-        "/beta/proxy/update": {
+        "/beta/job/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a running job deployment in user namespace",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Delete a course CRD in user namespace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/download/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stream a tar or zip archive of path under writable mount point of job, by exec-ing tar in job pod.\nSize of path is checked with du before download.",
+                "produces": [
+                    "application/x-tar",
+                    "application/zip"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Download files in workspace of container job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "path relative to writable mount point, default is whole workspace",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "tar or zip, default tar",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod name, default is the first running pod of job",
+                        "name": "pod",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "container name, default is the only container of pod",
+                        "name": "container",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "archive of path",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/exec/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upgrade to websocket and attach a TTY shell to job pod. Messages are TerminalMessage in json.\nBrowser sends {\"op\":\"stdin\",\"data\":\"ls\\r\"} and {\"op\":\"resize\",\"cols\":80,\"rows\":24}, server sends {\"op\":\"stdout\",\"data\":\"...\"}.\nSince browser can not set Authorization header of websocket, token is passed in access_token query when secure api is enabled.\nAccess token is redacted from request log, and websocket is only accepted from origin of UI, which is origin of oauth redirect url.\nTerminal is closed after no input for idle timeout in api server config.",
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Open terminal in container job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod name, default is the first running pod of job",
+                        "name": "pod",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "container name, default is the only container of pod",
+                        "name": "container",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "bearer token",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/extend/{id}": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Update a existing user information",
+                "description": "Extend maximum runtime of a container job within limit of classroom, extension is unlimited if maxExtension of classroom is zero.\nEvery minute extended must be in classroom schedule, and at most 1440 minutes are extended at a time.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2030,18 +2345,25 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Proxy"
+                    "Job"
                 ],
-                "summary": "Update a existing user information",
+                "summary": "Extend maximum runtime of a running job",
                 "parameters": [
                     {
-                        "description": "user information",
-                        "name": "user_info",
+                        "type": "string",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "minutes to extend",
+                        "name": "extend",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.UpdatedUser"
+                            "$ref": "#/definitions/docs.ExtendJobRequest"
                         }
                     }
                 ],
@@ -2050,7 +2372,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.PlainResponse"
+                            "$ref": "#/definitions/docs.ExtendJobResponse"
                         }
                     },
                     "400": {
@@ -2069,6 +2391,13 @@ var doc = `{
                     },
                     "403": {
                         "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "$ref": "#/definitions/docs.GenericErrorResponse"
@@ -2084,14 +2413,14 @@ var doc = `{
                 }
             }
         },
-        "/beta/user/role/{roleid}": {
+        "/beta/job/get/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Get all users' id have the same role",
+                "description": "Get Course CRD spec and status, pods, pod conditions and kubernetes events of a job.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2099,14 +2428,14 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Job"
                 ],
-                "summary": "Get all users' id have the same role",
+                "summary": "Get detail of a container job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "student or teacher",
-                        "name": "roleid",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -2116,7 +2445,7 @@ var doc = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.RoleListResponse"
+                            "$ref": "#/definitions/docs.JobDetailResponse"
                         }
                     },
                     "400": {
@@ -2137,7 +2466,7 @@ var doc = `{
                         "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
                         }
                     },
                     "500": {
@@ -2149,23 +2478,2221 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "docs.AccountLabelValue": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "student1@gmail.com"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "莊小明"
-                }
-            }
         },
-        "docs.AddClassroom": {
+        "/beta/job/launch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a course CRD in kubernetes\nIf launch queue is enabled and free GPU is not enough, GPU job is queued with status Queued and its queue position.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Create a course CRD in kubernetes",
+                "parameters": [
+                    {
+                        "description": "course want to launch",
+                        "name": "launch_course",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.LaunchCourseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.LaunchCourseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/list": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all running container jobs and vm jobs in rfstack for a user, type of job is CONTAINER or VM.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "List all running course deployment for a user",
+                "parameters": [
+                    {
+                        "description": "search user's job",
+                        "name": "list_user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.OauthUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.JobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/logs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get container logs of job pod in plain text. Set follow to stream logs until job is stopped or client disconnects.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Get container logs of a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod name, default is the first pod of job",
+                        "name": "pod",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "container name, default is the only container of pod",
+                        "name": "container",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of lines from the end of logs, default 100",
+                        "name": "tail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "stream new logs",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "container logs",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/upload/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload a file into directory under writable mount point of job. Tar, tar.gz and zip archives are extracted,\nother files are copied as is. Uncompressed size is limited, and only regular files and directories are allowed in archive.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Upload files into workspace of container job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "directory relative to writable mount point, default is mount point",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod name, default is the first running pod of job",
+                        "name": "pod",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "container name, default is the only container of pod",
+                        "name": "container",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "file or archive to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/job/watch": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of job status. Each \"status\" event is a JobStatus in json with id.\n\"warning\" event is a JobWarning sent before job is deleted for reaching maximum runtime or idle timeout.\nReconnect with Last-Event-ID header to receive missed events, otherwise current status of all jobs is sent first.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Watch status change of user's container jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user to watch, only used when secure api is disabled",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id of last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.JobStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/changePW": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change user password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Change user password",
+                "parameters": [
+                    {
+                        "description": "new password",
+                        "name": "user_password",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.PasswordInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.PlainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/introspection": {
+            "post": {
+                "description": "Get token meta information from provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Get token meta information from provider",
+                "parameters": [
+                    {
+                        "description": "Introspection token",
+                        "name": "Introspection_token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.IntrospectionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.IntrospectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke all tokens of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Revoke all tokens of a user",
+                "parameters": [
+                    {
+                        "description": "user token",
+                        "name": "user_token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.IntrospectionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.PlainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/query": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "query a existing user information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "query a existing user information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.UserInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/refresh": {
+            "post": {
+                "description": "Refresh token with provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Refresh token with provider",
+                "parameters": [
+                    {
+                        "description": "refresh token",
+                        "name": "refresh_token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.RefreshTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/register": {
+            "post": {
+                "description": "Register a new user. Only authenticated superuser can register teacher or superuser when secure api is enabled,\notherwise new user is registered as student.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "user information",
+                        "name": "new_user_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.UserInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.PlainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/token": {
+            "post": {
+                "description": "Exchange token from Provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Exchange token from Provider",
+                "parameters": [
+                    {
+                        "description": "token request",
+                        "name": "token_request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.TokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/proxy/update": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a existing user information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proxy"
+                ],
+                "summary": "Update a existing user information",
+                "parameters": [
+                    {
+                        "description": "user information",
+                        "name": "user_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.UpdatedUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.PlainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/quota/delete": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete quota override of a role, classroom or user, fall back to less specific scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quota"
+                ],
+                "summary": "Delete quota override",
+                "parameters": [
+                    {
+                        "description": "scope and target of quota",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.QuotaTarget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/quota/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List quota overrides of role, classroom and user. Default quota is defined in api-server config.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quota"
+                ],
+                "summary": "List all quota overrides",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.QuotaListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/quota/set": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create or update quota override of a role, classroom or user. Omitted limit is inherited from less specific scope.\nNegative limit means unlimited, and zero limit allows nothing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quota"
+                ],
+                "summary": "Create or update quota override",
+                "parameters": [
+                    {
+                        "description": "scope is one of role, classroom and user. user target is in provider:user format",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.Quota"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/reconcile/run": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Report job rows without Course CRD, Course CRDs without job row, classroom namespaces without classroom\nand PVs of deleted classroom. Resources are only reported in dry run, which is the default, otherwise they are deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reconcile"
+                ],
+                "summary": "Find and repair drift between database and kubernetes",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "only report drift, default true",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ReconcileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/reservation/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reserve GPU for jobs of classroom. Time window must be in classroom schedule, and GPU reserved\nat the same time by all classrooms can not exceed GPU of cluster. Reserved GPU can not be used by other classrooms in the window.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservation"
+                ],
+                "summary": "Reserve GPU for classroom in a time window",
+                "parameters": [
+                    {
+                        "description": "classroom, gpu number and time window",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ReservationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/reservation/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancel GPU reservation, reserved GPU is released to all classrooms.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservation"
+                ],
+                "summary": "Cancel GPU reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "reservation uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/reservation/list/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List GPU reservations of classroom which are not yet ended, in order of start time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservation"
+                ],
+                "summary": "List GPU reservations of classroom",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ReservationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/schedule/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Launch container job at startAt, or beforeClass minutes before next class window of classroom.\nJob is stopped at stopAt, or at the end of class window if stopAtClassEnd is set. Quota and classroom schedule are checked at launch time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Schedule launch and stop of a container job",
+                "parameters": [
+                    {
+                        "description": "course to launch and time to launch and stop",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ScheduleJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ScheduledJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/schedule/delete/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancel pending scheduled launch, or planned stop of launched job. Job already launched keeps running.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Delete scheduled job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "scheduled job uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/schedule/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List scheduled launch and stop of container jobs for a user, in order of start time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "List scheduled jobs of user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user name, only used when secure api is disabled",
+                        "name": "user",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ScheduledJobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/schedule/update/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update course and time of pending scheduled job. Only stop time can be updated after job is launched.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Update scheduled job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "scheduled job uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "course to launch and time to launch and stop",
+                        "name": "schedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ScheduleJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ScheduledJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/user/role/{roleid}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get all users' id have the same role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get all users' id have the same role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "student or teacher",
+                        "name": "roleid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.RoleListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/workspace/delete/{classroom}/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete writable volume and all files in it. A new empty volume is created when course is launched again in the classroom.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspace"
+                ],
+                "summary": "Delete workspace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "classroom",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "workspace name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/workspace/gc": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Find writable volumes whose owner is neither student nor teacher of the classroom, or is not a user anymore\nin public and teacher classroom. Workspaces are only reported in dry run, which is the default, otherwise they are deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspace"
+                ],
+                "summary": "Delete workspaces of users who are no longer enrolled",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "only report workspaces, default true",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.WorkspaceGCResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/workspace/list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List writable volumes of user in all classrooms, with size and last time used by container job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspace"
+                ],
+                "summary": "List someone's workspaces",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user name, only used when secure api is disabled",
+                        "name": "user",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.WorkspaceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/beta/workspace/reset/{classroom}/{name}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove all files in writable volume by a job running in classroom namespace, volume itself is kept.\nReset is done in background, course should not be launched in the classroom until it is finished.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspace"
+                ],
+                "summary": "Reset workspace to clean state",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41",
+                        "name": "classroom",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "workspace name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericOKResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/docs.GenericErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "docs.AccountLabelValue": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "student1@gmail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "莊小明"
+                }
+            }
+        },
+        "docs.AddClassroom": {
+            "type": "object",
+            "properties": {
+                "calendar": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.CalendarTime"
+                    }
+                },
+                "courses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.CourseLabelValue"
+                    }
+                },
+                "description": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "國衛院教室說明"
+                },
+                "idleTimeout": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 30
+                },
+                "maxExtension": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 60
+                },
+                "maxRuntime": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 120
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "國衛院教室"
+                },
+                "public": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                },
+                "schedule": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.Schedule"
+                },
+                "students": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.UserLabelValue"
+                    }
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.UserLabelValue"
+                    }
+                }
+            }
+        },
+        "docs.AddCourseBeta": {
+            "type": "object",
+            "properties": {
+                "accessType": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Ingress"
+                },
+                "cpuLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2000
+                },
+                "cpuRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1000
+                },
+                "datasets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.DatasetLabelValue"
+                    }
+                },
+                "envs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.EnvVar"
+                    }
+                },
+                "gpu": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.GPULabelValue"
+                },
+                "gpuModel": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tesla-V100-SXM2-32GB"
+                },
+                "idleTimeout": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 30
+                },
+                "image": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.ImageLabelValue"
+                },
+                "introduction": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "課程說明"
+                },
+                "level": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "basic"
+                },
+                "maxRuntime": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 120
+                },
+                "memoryLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 4096
+                },
+                "memoryRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2048
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "jimmy的課"
+                },
+                "nodeSelector": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "zone=lab1"
+                },
+                "ports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.PortLabelValue"
+                    }
+                },
+                "storageLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 10240
+                },
+                "storageRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1024
+                },
+                "user": {
+                    "type": "string",
+                    "example": "user@gamil.com"
+                },
+                "writablePath": {
+                    "type": "string",
+                    "example": "/tmp/work"
+                }
+            }
+        },
+        "docs.CRDWritableVolume": {
+            "type": "object",
+            "properties": {
+                "mountPoint": {
+                    "type": "string",
+                    "example": "/tmp/work"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "jimmy191@teacher"
+                },
+                "storageclass": {
+                    "type": "string",
+                    "example": "nchc-ai-nfs"
+                },
+                "uid": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2000620001
+                }
+            }
+        },
+        "docs.CalendarTime": {
+            "type": "object",
+            "properties": {
+                "endDate": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2019-01-25"
+                },
+                "length": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 3
+                },
+                "startDate": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "2019-01-22"
+                },
+                "startMonth": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 2
+                }
+            }
+        },
+        "docs.CatalogRepository": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "format": "string"
+                },
+                "fetchedAt": {
+                    "type": "string",
+                    "example": "2018-06-20T02:00:00Z"
+                },
+                "images": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 12
+                },
+                "registry": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "dockerhub"
+                },
+                "repository": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "nchcai"
+                }
+            }
+        },
+        "docs.ClassRoomInfo": {
+            "type": "object",
+            "properties": {
+                "courseInfo": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.ClassroomCourse"
+                    }
+                },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-25T09:24:38Z"
+                },
+                "description": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "description"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "default"
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Public Course"
+                },
+                "public": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                },
+                "studentCount": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 18
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.UserLabelValue"
+                    }
+                }
+            }
+        },
+        "docs.ClassRoomInfoWithStudent": {
             "type": "object",
             "properties": {
                 "calendar": {
@@ -2174,21 +4701,30 @@ var doc = `{
                         "$ref": "#/definitions/docs.CalendarTime"
                     }
                 },
-                "courses": {
+                "courseInfo": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/docs.CourseLabelValue"
+                        "$ref": "#/definitions/docs.ClassroomCourse"
                     }
                 },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-25T09:24:38Z"
+                },
                 "description": {
                     "type": "string",
                     "format": "string",
-                    "example": "國衛院教室說明"
+                    "example": "description"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "default"
                 },
                 "name": {
                     "type": "string",
                     "format": "string",
-                    "example": "國衛院教室"
+                    "example": "Public Course"
                 },
                 "public": {
                     "type": "boolean",
@@ -2199,6 +4735,11 @@ var doc = `{
                     "type": "object",
                     "$ref": "#/definitions/docs.Schedule"
                 },
+                "studentCount": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 18
+                },
                 "students": {
                     "type": "array",
                     "items": {
@@ -2213,32 +4754,16 @@ var doc = `{
                 }
             }
         },
-        "docs.AddCourseBeta": {
+        "docs.ClassroomCourse": {
             "type": "object",
             "properties": {
-                "accessType": {
+                "createAt": {
                     "type": "string",
-                    "format": "string",
-                    "example": "Ingress"
-                },
-                "datasets": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/docs.DatasetLabelValue"
-                    }
-                },
-                "gpu": {
-                    "type": "object",
-                    "$ref": "#/definitions/docs.GPULabelValue"
-                },
-                "image": {
-                    "type": "object",
-                    "$ref": "#/definitions/docs.ImageLabelValue"
+                    "example": "2018-06-25T09:24:38Z"
                 },
-                "introduction": {
+                "id": {
                     "type": "string",
-                    "format": "string",
-                    "example": "課程說明"
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
                 },
                 "level": {
                     "type": "string",
@@ -2250,259 +4775,392 @@ var doc = `{
                     "format": "string",
                     "example": "jimmy的課"
                 },
-                "ports": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/docs.PortLabelValue"
-                    }
-                },
-                "user": {
+                "roomId": {
                     "type": "string",
-                    "example": "user@gamil.com"
+                    "format": "string",
+                    "example": "d385a235-e1a1-49de-8b65-0b0d6a5783e5"
                 },
-                "writablePath": {
+                "type": {
                     "type": "string",
-                    "example": "/tmp/work"
+                    "format": "string",
+                    "example": "CONTAINER"
                 }
             }
         },
-        "docs.CalendarTime": {
+        "docs.ClassroomCourseWithType": {
             "type": "object",
             "properties": {
-                "endDate": {
+                "createAt": {
                     "type": "string",
-                    "format": "string",
-                    "example": "2019-01-25"
+                    "example": "2018-06-25T09:24:38Z"
                 },
-                "length": {
-                    "type": "integer",
-                    "format": "int",
-                    "example": 3
+                "id": {
+                    "type": "string",
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
                 },
-                "startDate": {
+                "level": {
                     "type": "string",
                     "format": "string",
-                    "example": "2019-01-22"
+                    "example": "basic"
                 },
-                "startMonth": {
-                    "type": "integer",
-                    "format": "int",
-                    "example": 2
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "jimmy的課"
+                },
+                "type": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "CONTAINER"
                 }
             }
         },
-        "docs.ClassRoomInfo": {
+        "docs.ClassroomJobInfo": {
             "type": "object",
             "properties": {
-                "courseInfo": {
+                "canSnapshot": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "course_id": {
+                    "type": "string",
+                    "example": "b86b2893-b876-45c2-a3f6-5e099c15d638"
+                },
+                "expireAt": {
+                    "type": "string",
+                    "example": "2018-06-25T10:24:38Z"
+                },
+                "gpu": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1
+                },
+                "id": {
+                    "type": "string",
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                },
+                "image": {
+                    "type": "string",
+                    "example": "nvidia/caffe:latest"
+                },
+                "introduction": {
+                    "type": "string",
+                    "example": "markdown text with escape"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "basic"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "mage process"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "go-oauth"
+                },
+                "queuePosition": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 3
+                },
+                "service": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/docs.ClassroomCourse"
+                        "$ref": "#/definitions/docs.SVCLabelValue"
                     }
                 },
-                "createAt": {
+                "startAt": {
                     "type": "string",
                     "example": "2018-06-25T09:24:38Z"
                 },
-                "description": {
+                "status": {
                     "type": "string",
-                    "format": "string",
-                    "example": "description"
+                    "example": "Ready"
                 },
-                "id": {
+                "type": {
                     "type": "string",
-                    "format": "string",
-                    "example": "default"
+                    "example": "CONTAINER"
                 },
-                "name": {
+                "user": {
                     "type": "string",
-                    "format": "string",
-                    "example": "Public Course"
-                },
-                "public": {
+                    "example": "student1@gmail.com"
+                }
+            }
+        },
+        "docs.ClassroomJobListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "boolean",
                     "format": "bool",
-                    "example": true
-                },
-                "studentCount": {
-                    "type": "integer",
-                    "format": "int",
-                    "example": 18
+                    "example": false
                 },
-                "teachers": {
+                "jobs": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/docs.UserLabelValue"
+                        "$ref": "#/definitions/docs.ClassroomJobInfo"
                     }
                 }
             }
         },
-        "docs.ClassRoomInfoWithStudent": {
+        "docs.ClassroomJobRequest": {
             "type": "object",
             "properties": {
-                "calendar": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/docs.CalendarTime"
-                    }
+                "classroom_id": {
+                    "type": "string",
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
                 },
-                "courseInfo": {
+                "course_id": {
+                    "type": "string",
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
+                }
+            }
+        },
+        "docs.ClassroomJobResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "results": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/docs.ClassroomCourse"
+                        "$ref": "#/definitions/docs.ClassroomJobResult"
                     }
+                }
+            }
+        },
+        "docs.ClassroomJobResult": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string",
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
                 },
-                "createAt": {
+                "message": {
+                    "type": "string"
+                },
+                "provider": {
                     "type": "string",
-                    "example": "2018-06-25T09:24:38Z"
+                    "example": "go-oauth"
                 },
-                "description": {
+                "status": {
                     "type": "string",
-                    "format": "string",
-                    "example": "description"
+                    "example": "Created"
                 },
+                "user": {
+                    "type": "string",
+                    "example": "student1@gmail.com"
+                }
+            }
+        },
+        "docs.CommitImage": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "string",
-                    "format": "string",
-                    "example": "default"
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
                 },
                 "name": {
                     "type": "string",
-                    "format": "string",
-                    "example": "Public Course"
-                },
-                "public": {
-                    "type": "boolean",
-                    "format": "bool",
-                    "example": true
+                    "example": "tensorflow/tensorflow:v3"
+                }
+            }
+        },
+        "docs.CourseCRDSpec": {
+            "type": "object",
+            "properties": {
+                "accessType": {
+                    "type": "string",
+                    "example": "NodePort"
                 },
-                "schedule": {
-                    "type": "object",
-                    "$ref": "#/definitions/docs.Schedule"
+                "dataset": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dataset-cifar-10"
+                    ]
                 },
-                "studentCount": {
+                "gpu": {
                     "type": "integer",
-                    "format": "int",
-                    "example": 18
+                    "format": "int32",
+                    "example": 1
                 },
-                "students": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/docs.UserLabelValue"
-                    }
+                "image": {
+                    "type": "string",
+                    "example": "nvidia/caffe:latest"
                 },
-                "teachers": {
+                "schedule": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/docs.UserLabelValue"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "* * * * * *"
+                    ]
+                },
+                "writableVolume": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.CRDWritableVolume"
                 }
             }
         },
-        "docs.ClassroomCourse": {
+        "docs.CourseCRDStatus": {
             "type": "object",
             "properties": {
-                "createAt": {
-                    "type": "string",
-                    "example": "2018-06-25T09:24:38Z"
+                "accessible": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
                 },
-                "id": {
+                "service": {
                     "type": "string",
-                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
-                },
-                "level": {
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c-svc"
+                }
+            }
+        },
+        "docs.CourseLabelValue": {
+            "type": "object",
+            "properties": {
+                "label": {
                     "type": "string",
-                    "format": "string",
-                    "example": "basic"
+                    "example": "tensorflow introduction"
                 },
-                "name": {
+                "value": {
                     "type": "string",
-                    "format": "string",
-                    "example": "jimmy的課"
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                }
+            }
+        },
+        "docs.CourseNameListResponse": {
+            "type": "object",
+            "properties": {
+                "courses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.CourseLabelValue"
+                    }
                 },
-                "roomId": {
-                    "type": "string",
-                    "format": "string",
-                    "example": "d385a235-e1a1-49de-8b65-0b0d6a5783e5"
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                }
+            }
+        },
+        "docs.CourseTypeResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
                 },
                 "type": {
                     "type": "string",
-                    "format": "string",
-                    "example": "CONTAINER"
+                    "example": "container"
                 }
             }
         },
-        "docs.ClassroomCourseWithType": {
+        "docs.DatasetLabelValue": {
             "type": "object",
             "properties": {
-                "createAt": {
+                "label": {
                     "type": "string",
-                    "example": "2018-06-25T09:24:38Z"
+                    "example": "cifar-10"
                 },
-                "id": {
+                "value": {
                     "type": "string",
-                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                    "example": "dataset-cifar-10"
+                }
+            }
+        },
+        "docs.DatasetsListResponse": {
+            "type": "object",
+            "properties": {
+                "datasets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.DatasetLabelValue"
+                    }
                 },
-                "level": {
+                "error": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "docs.Drift": {
+            "type": "object",
+            "properties": {
+                "error": {
                     "type": "string",
-                    "format": "string",
-                    "example": "basic"
+                    "format": "string"
                 },
-                "name": {
+                "kind": {
                     "type": "string",
                     "format": "string",
-                    "example": "jimmy的課"
+                    "example": "job"
                 },
-                "type": {
+                "name": {
                     "type": "string",
                     "format": "string",
-                    "example": "CONTAINER"
-                }
-            }
-        },
-        "docs.CommitImage": {
-            "type": "object",
-            "properties": {
-                "id": {
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
+                },
+                "namespace": {
                     "type": "string",
-                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                    "format": "string",
+                    "example": "aitrain-public"
                 },
-                "name": {
+                "reason": {
                     "type": "string",
-                    "example": "tensorflow/tensorflow:v3"
+                    "format": "string",
+                    "example": "Course CRD is not found"
+                },
+                "repaired": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
                 }
             }
         },
-        "docs.CourseLabelValue": {
+        "docs.EnvVar": {
             "type": "object",
             "properties": {
-                "label": {
+                "name": {
                     "type": "string",
-                    "example": "tensorflow introduction"
+                    "example": "LICENSE_SERVER"
+                },
+                "secretKey": {
+                    "type": "string",
+                    "example": "token"
+                },
+                "secretName": {
+                    "type": "string",
+                    "example": "matlab-license"
                 },
                 "value": {
                     "type": "string",
-                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                    "example": "27000@license.example.com"
                 }
             }
         },
-        "docs.CourseNameListResponse": {
+        "docs.ExtendJobRequest": {
             "type": "object",
             "properties": {
-                "courses": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/docs.CourseLabelValue"
-                    }
-                },
-                "error": {
-                    "type": "boolean",
-                    "format": "bool",
-                    "example": false
+                "minutes": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 30
                 }
             }
         },
-        "docs.CourseTypeResponse": {
+        "docs.ExtendJobResponse": {
             "type": "object",
             "properties": {
                 "error": {
@@ -2510,36 +5168,33 @@ var doc = `{
                     "format": "bool",
                     "example": false
                 },
-                "type": {
+                "expireAt": {
                     "type": "string",
-                    "example": "container"
-                }
-            }
-        },
-        "docs.DatasetLabelValue": {
-            "type": "object",
-            "properties": {
-                "label": {
-                    "type": "string",
-                    "example": "cifar-10"
+                    "example": "2018-06-25T10:54:38Z"
                 },
-                "value": {
+                "job_id": {
                     "type": "string",
-                    "example": "dataset-cifar-10"
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
                 }
             }
         },
-        "docs.DatasetsListResponse": {
+        "docs.ForbiddenResponse": {
             "type": "object",
             "properties": {
-                "datasets": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/docs.DatasetLabelValue"
-                    }
-                },
                 "error": {
-                    "type": "boolean"
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "error response message"
+                },
+                "reason": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "ROLE_NOT_ALLOWED"
                 }
             }
         },
@@ -2615,6 +5270,16 @@ var doc = `{
                     "type": "string",
                     "example": "NodePort"
                 },
+                "cpuLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2000
+                },
+                "cpuRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1000
+                },
                 "createAt": {
                     "type": "string",
                     "example": "2018-06-25T09:24:38Z"
@@ -2625,14 +5290,30 @@ var doc = `{
                         "$ref": "#/definitions/docs.DatasetLabelValue"
                     }
                 },
+                "envs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.EnvVar"
+                    }
+                },
                 "gpu": {
                     "type": "object",
                     "$ref": "#/definitions/docs.GPULabelValue"
                 },
+                "gpuModel": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tesla-V100-SXM2-32GB"
+                },
                 "id": {
                     "type": "string",
                     "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
                 },
+                "idleTimeout": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 30
+                },
                 "image": {
                     "type": "object",
                     "$ref": "#/definitions/docs.ImageLabelValue"
@@ -2647,17 +5328,47 @@ var doc = `{
                     "format": "string",
                     "example": "basic"
                 },
+                "maxRuntime": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 120
+                },
+                "memoryLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 4096
+                },
+                "memoryRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2048
+                },
                 "name": {
                     "type": "string",
                     "format": "string",
                     "example": "jimmy的課"
                 },
+                "nodeSelector": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "zone=lab1"
+                },
                 "ports": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/docs.PortLabelValue"
                     }
                 },
+                "storageLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 10240
+                },
+                "storageRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1024
+                },
                 "user": {
                     "type": "string",
                     "example": "jimmy191@teacher"
@@ -2723,16 +5434,227 @@ var doc = `{
                 }
             }
         },
-        "docs.ImageLabelValue": {
+        "docs.ImageCatalogRefreshResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "repositories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.CatalogRepository"
+                    }
+                }
+            }
+        },
+        "docs.ImageCommit": {
+            "type": "object",
+            "properties": {
+                "builderJob": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "image-commit-7c1b2f6e"
+                },
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "course_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
+                },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-20T02:00:00Z"
+                },
+                "finishedAt": {
+                    "type": "string",
+                    "example": "2018-06-20T02:05:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "7c1b2f6e-3a0d-4d55-9f3e-2b8a4c6d9e10"
+                },
+                "image": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "registry.example.com/aitrain/tensorflow:v3"
+                },
+                "job_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Running"
+                },
+                "user": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "teacher1"
+                }
+            }
+        },
+        "docs.ImageCommitListResponse": {
+            "type": "object",
+            "properties": {
+                "commits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.ImageCommit"
+                    }
+                },
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                }
+            }
+        },
+        "docs.ImageCommitResponse": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.ImageCommit"
+                },
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                }
+            }
+        },
+        "docs.ImageLabelValue": {
+            "type": "object",
+            "properties": {
+                "architectures": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "amd64",
+                        "arm64"
+                    ]
+                },
+                "description": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "tensorflow with jupyter"
+                },
+                "digest": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "sha256:4a4a9e2c7d2d3c7b1f0c8e1f9b2a7d6c5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b"
+                },
+                "gpu": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "label": {
+                    "type": "string",
+                    "example": "tensorflow/tensorflow:1.5.1"
+                },
+                "lastUpdated": {
+                    "type": "string",
+                    "example": "2018-06-20T02:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "tensorflow/tensorflow"
+                },
+                "pinned": {
+                    "type": "string",
+                    "example": "tensorflow/tensorflow@sha256:4a4a9e2c7d2d3c7b1f0c8e1f9b2a7d6c5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b"
+                },
+                "size": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 524288000
+                },
+                "tag": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "1.5.1"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "tensorflow/tensorflow:1.5.1"
+                }
+            }
+        },
+        "docs.ImageRule": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "approved training images"
+                },
+                "pattern": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "nchcai/train:v1.*"
+                },
+                "scope": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "classroom"
+                },
+                "target": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                }
+            }
+        },
+        "docs.ImageRuleListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.ImageRule"
+                    }
+                }
+            }
+        },
+        "docs.ImageRuleTarget": {
             "type": "object",
             "properties": {
-                "label": {
+                "pattern": {
                     "type": "string",
-                    "example": "tensorflow/tensorflow:1.5.1"
+                    "format": "string",
+                    "example": "harbor.example.com/ai/**"
                 },
-                "value": {
+                "scope": {
                     "type": "string",
-                    "example": "tensorflow/tensorflow:1.5.1"
+                    "format": "string",
+                    "example": "global"
+                },
+                "target": {
+                    "type": "string",
+                    "format": "string"
                 }
             }
         },
@@ -2796,6 +5718,69 @@ var doc = `{
                 }
             }
         },
+        "docs.JobDetail": {
+            "type": "object",
+            "properties": {
+                "classroom_id": {
+                    "type": "string",
+                    "example": "aaa-d385a235-e1a1-49de-8b65-0b0d6a5783e5"
+                },
+                "course_id": {
+                    "type": "string",
+                    "example": "b86b2893-b876-45c2-a3f6-5e099c15d638"
+                },
+                "crdStatus": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.CourseCRDStatus"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.KubernetesEvent"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
+                },
+                "pods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.JobPod"
+                    }
+                },
+                "spec": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.CourseCRDSpec"
+                },
+                "startAt": {
+                    "type": "string",
+                    "example": "2018-06-25T09:24:38Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Pending"
+                },
+                "user": {
+                    "type": "string",
+                    "example": "jimmy191@teacher"
+                }
+            }
+        },
+        "docs.JobDetailResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "job": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.JobDetail"
+                }
+            }
+        },
         "docs.JobInfo": {
             "type": "object",
             "properties": {
@@ -2818,6 +5803,10 @@ var doc = `{
                         "mnist"
                     ]
                 },
+                "expireAt": {
+                    "type": "string",
+                    "example": "2018-06-25T10:24:38Z"
+                },
                 "gpu": {
                     "type": "integer",
                     "format": "int64",
@@ -2843,6 +5832,11 @@ var doc = `{
                     "type": "string",
                     "example": "mage process"
                 },
+                "queuePosition": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 3
+                },
                 "service": {
                     "type": "array",
                     "items": {
@@ -2856,6 +5850,10 @@ var doc = `{
                 "status": {
                     "type": "string",
                     "example": "Ready"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "CONTAINER"
                 }
             }
         },
@@ -2875,6 +5873,35 @@ var doc = `{
                 }
             }
         },
+        "docs.JobPod": {
+            "type": "object",
+            "properties": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.PodCondition"
+                    }
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.KubernetesEvent"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c-5c6b7d9f4-abcde"
+                },
+                "nodeName": {
+                    "type": "string",
+                    "example": "gpu-node-1"
+                },
+                "phase": {
+                    "type": "string",
+                    "example": "Pending"
+                }
+            }
+        },
         "docs.JobStatus": {
             "type": "object",
             "properties": {
@@ -2882,6 +5909,11 @@ var doc = `{
                     "type": "string",
                     "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
                 },
+                "queuePosition": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 3
+                },
                 "ready": {
                     "type": "boolean",
                     "format": "bool",
@@ -2893,6 +5925,32 @@ var doc = `{
                 }
             }
         },
+        "docs.KubernetesEvent": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 3
+                },
+                "lastTimestamp": {
+                    "type": "string",
+                    "example": "2018-06-25T09:25:38Z"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "0/3 nodes are available: 3 Insufficient nvidia.com/gpu."
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "FailedScheduling"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Warning"
+                }
+            }
+        },
         "docs.LaunchCourseRequest": {
             "type": "object",
             "properties": {
@@ -3013,6 +6071,27 @@ var doc = `{
                 }
             }
         },
+        "docs.PodCondition": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "0/3 nodes are available: 3 Insufficient nvidia.com/gpu."
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Unschedulable"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "False"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "PodScheduled"
+                }
+            }
+        },
         "docs.PortLabelValue": {
             "type": "object",
             "properties": {
@@ -3027,6 +6106,93 @@ var doc = `{
                 }
             }
         },
+        "docs.Quota": {
+            "type": "object",
+            "properties": {
+                "maxCpu": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 4000
+                },
+                "maxGpu": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 1
+                },
+                "maxJobs": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 2
+                },
+                "maxMemory": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 8192
+                },
+                "scope": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "classroom"
+                },
+                "target": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                }
+            }
+        },
+        "docs.QuotaListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "quotas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.Quota"
+                    }
+                }
+            }
+        },
+        "docs.QuotaTarget": {
+            "type": "object",
+            "properties": {
+                "scope": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "user"
+                },
+                "target": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "go-oauth:student1"
+                }
+            }
+        },
+        "docs.ReconcileResponse": {
+            "type": "object",
+            "properties": {
+                "drifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.Drift"
+                    }
+                },
+                "dryRun": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                },
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                }
+            }
+        },
         "docs.RefreshTokenReq": {
             "type": "object",
             "properties": {
@@ -3036,6 +6202,96 @@ var doc = `{
                 }
             }
         },
+        "docs.Reservation": {
+            "type": "object",
+            "properties": {
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-20T02:00:00Z"
+                },
+                "endAt": {
+                    "type": "string",
+                    "example": "2018-06-25T04:00:00Z"
+                },
+                "gpu": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 4
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "8a4c3f0e-5b1d-4e57-9d37-0c3b8f8a2d61"
+                },
+                "startAt": {
+                    "type": "string",
+                    "example": "2018-06-25T01:00:00Z"
+                },
+                "user": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "teacher1"
+                }
+            }
+        },
+        "docs.ReservationListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "reservations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.Reservation"
+                    }
+                }
+            }
+        },
+        "docs.ReservationRequest": {
+            "type": "object",
+            "properties": {
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "endAt": {
+                    "type": "string",
+                    "example": "2018-06-25T04:00:00Z"
+                },
+                "gpu": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 4
+                },
+                "startAt": {
+                    "type": "string",
+                    "example": "2018-06-25T01:00:00Z"
+                }
+            }
+        },
+        "docs.ReservationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "reservation": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.Reservation"
+                }
+            }
+        },
         "docs.RoleListResponse": {
             "type": "object",
             "properties": {
@@ -3106,6 +6362,125 @@ var doc = `{
                 }
             }
         },
+        "docs.ScheduleJobRequest": {
+            "type": "object",
+            "properties": {
+                "beforeClass": {
+                    "type": "integer",
+                    "format": "int",
+                    "example": 5
+                },
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "course_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
+                },
+                "startAt": {
+                    "type": "string",
+                    "example": "2018-06-25T00:55:00Z"
+                },
+                "stopAt": {
+                    "type": "string",
+                    "example": "2018-06-25T04:00:00Z"
+                },
+                "stopAtClassEnd": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                },
+                "user": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "student1"
+                }
+            }
+        },
+        "docs.ScheduledJob": {
+            "type": "object",
+            "properties": {
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "course_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "5ab02011-9ab7-40c3-b691-d335f93a12ee"
+                },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-20T02:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "3e0c1b8a-6f55-4d36-a2a8-1f0e6c9d7b42"
+                },
+                "job_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "131ba8a9-b60b-44f9-83b5-46590f756f41"
+                },
+                "message": {
+                    "type": "string",
+                    "format": "string"
+                },
+                "startAt": {
+                    "type": "string",
+                    "example": "2018-06-25T00:55:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Pending"
+                },
+                "stopAt": {
+                    "type": "string",
+                    "example": "2018-06-25T04:00:00Z"
+                },
+                "user": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "student1"
+                }
+            }
+        },
+        "docs.ScheduledJobListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.ScheduledJob"
+                    }
+                }
+            }
+        },
+        "docs.ScheduledJobResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "schedule": {
+                    "type": "object",
+                    "$ref": "#/definitions/docs.ScheduledJob"
+                }
+            }
+        },
         "docs.Search": {
             "type": "object",
             "properties": {
@@ -3218,6 +6593,21 @@ var doc = `{
                     "type": "string",
                     "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
                 },
+                "idleTimeout": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 30
+                },
+                "maxExtension": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 60
+                },
+                "maxRuntime": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 120
+                },
                 "name": {
                     "type": "string",
                     "format": "string",
@@ -3249,20 +6639,46 @@ var doc = `{
         "docs.UpdateCourseBeta": {
             "type": "object",
             "properties": {
+                "cpuLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2000
+                },
+                "cpuRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1000
+                },
                 "datasets": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/docs.DatasetLabelValue"
                     }
                 },
+                "envs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.EnvVar"
+                    }
+                },
                 "gpu": {
                     "type": "object",
                     "$ref": "#/definitions/docs.GPULabelValue"
                 },
+                "gpuModel": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Tesla-V100-SXM2-32GB"
+                },
                 "id": {
                     "type": "string",
                     "example": "49a31009-7d1b-4ff2-badd-e8c717e2256c"
                 },
+                "idleTimeout": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 30
+                },
                 "image": {
                     "type": "object",
                     "$ref": "#/definitions/docs.ImageLabelValue"
@@ -3277,17 +6693,47 @@ var doc = `{
                     "format": "string",
                     "example": "basic"
                 },
+                "maxRuntime": {
+                    "type": "integer",
+                    "format": "int32",
+                    "example": 120
+                },
+                "memoryLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 4096
+                },
+                "memoryRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 2048
+                },
                 "name": {
                     "type": "string",
                     "format": "string",
                     "example": "jimmy的課"
                 },
+                "nodeSelector": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "zone=lab1"
+                },
                 "ports": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/docs.PortLabelValue"
                     }
                 },
+                "storageLimit": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 10240
+                },
+                "storageRequest": {
+                    "type": "integer",
+                    "format": "int64",
+                    "example": 1024
+                },
                 "writablePath": {
                     "type": "string",
                     "example": "/tmp/work"
@@ -3398,6 +6844,173 @@ var doc = `{
                     "example": "student1@gmail.com"
                 }
             }
+        },
+        "docs.WorkspaceGC": {
+            "type": "object",
+            "properties": {
+                "classroomName": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "深度學習入門"
+                },
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-25T09:24:38Z"
+                },
+                "deleted": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "error": {
+                    "type": "string",
+                    "format": "string"
+                },
+                "inUse": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "lastUsedAt": {
+                    "type": "string",
+                    "example": "2018-06-26T04:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "student1-workspace"
+                },
+                "owner": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "student1"
+                },
+                "provider": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "go-oauth"
+                },
+                "reason": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "user {student1} is not enrolled in classroom {0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1}"
+                },
+                "size": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "10Gi"
+                },
+                "status": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Bound"
+                },
+                "storageClass": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "nchc-ai-nfs"
+                }
+            }
+        },
+        "docs.WorkspaceGCResponse": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": true
+                },
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "workspaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.WorkspaceGC"
+                    }
+                }
+            }
+        },
+        "docs.WorkspaceInfo": {
+            "type": "object",
+            "properties": {
+                "classroomName": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "深度學習入門"
+                },
+                "classroom_id": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1"
+                },
+                "createAt": {
+                    "type": "string",
+                    "example": "2018-06-25T09:24:38Z"
+                },
+                "inUse": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "lastUsedAt": {
+                    "type": "string",
+                    "example": "2018-06-26T04:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "student1-workspace"
+                },
+                "owner": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "student1"
+                },
+                "provider": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "go-oauth"
+                },
+                "size": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "10Gi"
+                },
+                "status": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "Bound"
+                },
+                "storageClass": {
+                    "type": "string",
+                    "format": "string",
+                    "example": "nchc-ai-nfs"
+                }
+            }
+        },
+        "docs.WorkspaceListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean",
+                    "format": "bool",
+                    "example": false
+                },
+                "workspaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/docs.WorkspaceInfo"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -50,6 +50,9 @@ type AddClassroom struct {
 	Students     []UserLabelValue   `json:"students"`
 	Courses      []CourseLabelValue `json:"courses"`
 	CalendarTime []CalendarTime     `json:"calendar"`
+	MaxRuntime   int32              `json:"maxRuntime,omitempty" example:"120" format:"int32"`
+	IdleTimeout  int32              `json:"idleTimeout,omitempty" example:"30" format:"int32"`
+	MaxExtension int32              `json:"maxExtension,omitempty" example:"60" format:"int32"`
 }
 
 type UpdateClassroom struct {
@@ -59,7 +62,7 @@ type UpdateClassroom struct {
 
 type UploadUserResponse struct {
 	Error bool                `json:"error" example:"false" format:"bool"`
-	users []AccountLabelValue `json:"users"`
+	Users []AccountLabelValue `json:"users"`
 }
 
 type GetClassroomResponse struct {
@@ -24,7 +24,11 @@ type AddCourseBeta struct {
 	AccessType   string              `json:"accessType" example:"Ingress" format:"string"`
 	Datasets     []DatasetLabelValue `json:"datasets"`
 	Ports        []PortLabelValue    `json:"ports"`
+	Envs         []EnvVar            `json:"envs,omitempty"`
 	WritablePath string              `json:"writablePath" example:"/tmp/work"`
+	MaxRuntime   int32               `json:"maxRuntime,omitempty" example:"120" format:"int32"`
+	IdleTimeout  int32               `json:"idleTimeout,omitempty" example:"30" format:"int32"`
+	ResourceProfile
 }
 
 type UpdateCourseBeta struct {
@@ -36,7 +40,11 @@ type UpdateCourseBeta struct {
 	Level        string              `json:"level" example:"basic" format:"string"`
 	Datasets     []DatasetLabelValue `json:"datasets"`
 	Ports        []PortLabelValue    `json:"ports"`
+	Envs         []EnvVar            `json:"envs,omitempty"`
 	WritablePath string              `json:"writablePath" example:"/tmp/work"`
+	MaxRuntime   int32               `json:"maxRuntime,omitempty" example:"120" format:"int32"`
+	IdleTimeout  int32               `json:"idleTimeout,omitempty" example:"30" format:"int32"`
+	ResourceProfile
 }
 
 type GetCourse struct {
@@ -50,8 +58,12 @@ type GetCourse struct {
 	Level        string              `json:"level" example:"basic" format:"string"`
 	Datasets     []DatasetLabelValue `json:"datasets"`
 	Ports        []PortLabelValue    `json:"ports"`
+	Envs         []EnvVar            `json:"envs,omitempty"`
 	WritablePath string              `json:"writablePath" example:"/tmp/work"`
 	AccessType   string              `json:"accessType" example:"NodePort"`
+	MaxRuntime   int32               `json:"maxRuntime,omitempty" example:"120" format:"int32"`
+	IdleTimeout  int32               `json:"idleTimeout,omitempty" example:"30" format:"int32"`
+	ResourceProfile
 }
 
 type PortLabelValue struct {
@@ -59,6 +71,13 @@ type PortLabelValue struct {
 	Port uint   `json:"port" example:"8080" format:"int64"`
 }
 
+type EnvVar struct {
+	Name       string `json:"name" example:"LICENSE_SERVER"`
+	Value      string `json:"value,omitempty" example:"27000@license.example.com"`
+	SecretName string `json:"secretName,omitempty" example:"matlab-license"`
+	SecretKey  string `json:"secretKey,omitempty" example:"token"`
+}
+
 type CourseTypeResponse struct {
 	Error bool   `json:"error" example:"false" format:"bool"`
 	Type  string `json:"type" example:"container"`
@@ -78,3 +97,14 @@ type GPULabelValue struct {
 	Label string `json:"label" example:"0"`
 	Value uint   `json:"value" example:"0" format:"int64"`
 }
+
+type ResourceProfile struct {
+	CpuRequest     int64  `json:"cpuRequest,omitempty" example:"1000" format:"int64"`
+	CpuLimit       int64  `json:"cpuLimit,omitempty" example:"2000" format:"int64"`
+	MemoryRequest  int64  `json:"memoryRequest,omitempty" example:"2048" format:"int64"`
+	MemoryLimit    int64  `json:"memoryLimit,omitempty" example:"4096" format:"int64"`
+	StorageRequest int64  `json:"storageRequest,omitempty" example:"1024" format:"int64"`
+	StorageLimit   int64  `json:"storageLimit,omitempty" example:"10240" format:"int64"`
+	NodeSelector   string `json:"nodeSelector,omitempty" example:"zone=lab1" format:"string"`
+	GpuModel       string `json:"gpuModel,omitempty" example:"Tesla-V100-SXM2-32GB" format:"string"`
+}
@@ -13,3 +13,9 @@ type GenericOKResponse struct {
 type OauthUser struct {
 	User string `json:"user" example:"user@gamil.com"`
 }
+
+type ForbiddenResponse struct {
+	Error   bool   `json:"error" example:"true" format:"bool"`
+	Message string `json:"message" example:"error response message" format:"string"`
+	Reason  string `json:"reason" example:"ROLE_NOT_ALLOWED" format:"string"`
+}
@@ -6,11 +6,75 @@ type ImagesListResponse struct {
 }
 
 type ImageLabelValue struct {
-	Label string `json:"label" example:"tensorflow/tensorflow:1.5.1"`
-	Value string `json:"value" example:"tensorflow/tensorflow:1.5.1"`
+	Label         string   `json:"label" example:"tensorflow/tensorflow:1.5.1"`
+	Value         string   `json:"value" example:"tensorflow/tensorflow:1.5.1"`
+	Pinned        string   `json:"pinned,omitempty" example:"tensorflow/tensorflow@sha256:4a4a9e2c7d2d3c7b1f0c8e1f9b2a7d6c5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b"`
+	Name          string   `json:"name" example:"tensorflow/tensorflow" format:"string"`
+	Tag           string   `json:"tag" example:"1.5.1" format:"string"`
+	Digest        string   `json:"digest,omitempty" example:"sha256:4a4a9e2c7d2d3c7b1f0c8e1f9b2a7d6c5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b" format:"string"`
+	Size          int64    `json:"size,omitempty" example:"524288000" format:"int64"`
+	Architectures []string `json:"architectures,omitempty" example:"amd64,arm64"`
+	LastUpdated   string   `json:"lastUpdated,omitempty" example:"2018-06-20T02:00:00Z"`
+	Description   string   `json:"description,omitempty" example:"tensorflow with jupyter" format:"string"`
+	Gpu           bool     `json:"gpu" example:"false" format:"bool"`
+}
+
+type ImageRule struct {
+	Scope       string `json:"scope" example:"classroom" format:"string"`
+	Target      string `json:"target" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	Pattern     string `json:"pattern" example:"nchcai/train:v1.*" format:"string"`
+	Description string `json:"description,omitempty" example:"approved training images" format:"string"`
+}
+
+type ImageRuleTarget struct {
+	Scope   string `json:"scope" example:"global" format:"string"`
+	Target  string `json:"target,omitempty" example:"" format:"string"`
+	Pattern string `json:"pattern" example:"harbor.example.com/ai/**" format:"string"`
+}
+
+type ImageRuleListResponse struct {
+	Error bool        `json:"error" example:"false" format:"bool"`
+	Rules []ImageRule `json:"rules"`
 }
 
 type CommitImage struct {
 	ID   string `json:"id" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c"`
 	Name string `json:"name" example:"tensorflow/tensorflow:v3"`
 }
+
+type ImageCommit struct {
+	Id          string `json:"id" example:"7c1b2f6e-3a0d-4d55-9f3e-2b8a4c6d9e10" format:"string"`
+	CreateAt    string `json:"createAt" example:"2018-06-20T02:00:00Z"`
+	User        string `json:"user" example:"teacher1" format:"string"`
+	JobId       string `json:"job_id" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c" format:"string"`
+	CourseId    string `json:"course_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee" format:"string"`
+	ClassroomId string `json:"classroom_id" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	Image       string `json:"image" example:"registry.example.com/aitrain/tensorflow:v3" format:"string"`
+	Status      string `json:"status" example:"Running" format:"string"`
+	BuilderJob  string `json:"builderJob,omitempty" example:"image-commit-7c1b2f6e" format:"string"`
+	Message     string `json:"message,omitempty" example:"" format:"string"`
+	FinishedAt  string `json:"finishedAt,omitempty" example:"2018-06-20T02:05:00Z"`
+}
+
+type ImageCommitResponse struct {
+	Error  bool        `json:"error" example:"false" format:"bool"`
+	Commit ImageCommit `json:"commit"`
+}
+
+type ImageCommitListResponse struct {
+	Error   bool          `json:"error" example:"false" format:"bool"`
+	Commits []ImageCommit `json:"commits"`
+}
+
+type CatalogRepository struct {
+	Registry   string `json:"registry" example:"dockerhub" format:"string"`
+	Repository string `json:"repository" example:"nchcai" format:"string"`
+	Images     int    `json:"images" example:"12" format:"int"`
+	FetchedAt  string `json:"fetchedAt,omitempty" example:"2018-06-20T02:00:00Z"`
+	Error      string `json:"error,omitempty" example:"" format:"string"`
+}
+
+type ImageCatalogRefreshResponse struct {
+	Error        bool                `json:"error" example:"false" format:"bool"`
+	Repositories []CatalogRepository `json:"repositories"`
+}
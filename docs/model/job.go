@@ -11,10 +11,107 @@ type LaunchCourseResponse struct {
 	Job   JobStatus `json:"job"`
 }
 
+type ClassroomJobRequest struct {
+	CourseId    string `json:"course_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
+	ClassroomId string `json:"classroom_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
+}
+
+type ClassroomJobResponse struct {
+	Error   bool                 `json:"error" example:"false" format:"bool"`
+	Results []ClassroomJobResult `json:"results"`
+}
+
+type ClassroomJobResult struct {
+	User     string `json:"user" example:"student1@gmail.com"`
+	Provider string `json:"provider" example:"go-oauth"`
+	JobId    string `json:"job_id,omitempty" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
+	Status   string `json:"status" example:"Created"`
+	Message  string `json:"message,omitempty" example:""`
+}
+
 type JobStatus struct {
-	JobId  string `json:"job_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
-	Ready  bool   `json:"ready" example:"false" format:"bool"`
-	Status string `json:"status" example:"Created"`
+	JobId         string `json:"job_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
+	Ready         bool   `json:"ready" example:"false" format:"bool"`
+	Status        string `json:"status" example:"Created"`
+	QueuePosition int    `json:"queuePosition,omitempty" example:"3" format:"int"`
+}
+
+type ExtendJobRequest struct {
+	Minutes int32 `json:"minutes" example:"30" format:"int32"`
+}
+
+type ExtendJobResponse struct {
+	Error    bool   `json:"error" example:"false" format:"bool"`
+	JobId    string `json:"job_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
+	ExpireAt string `json:"expireAt" example:"2018-06-25T10:54:38Z"`
+}
+
+type JobWarning struct {
+	JobId    string `json:"job_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee"`
+	Reason   string `json:"reason" example:"IDLE"`
+	Deadline string `json:"deadline" example:"2018-06-25T10:24:38Z"`
+}
+
+type JobDetailResponse struct {
+	Error bool      `json:"error" example:"false" format:"bool"`
+	Job   JobDetail `json:"job"`
+}
+
+type JobDetail struct {
+	Id          string            `json:"id" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c"`
+	CourseID    string            `json:"course_id" example:"b86b2893-b876-45c2-a3f6-5e099c15d638"`
+	ClassroomID string            `json:"classroom_id" example:"aaa-d385a235-e1a1-49de-8b65-0b0d6a5783e5"`
+	User        string            `json:"user" example:"jimmy191@teacher"`
+	StartAt     string            `json:"startAt" example:"2018-06-25T09:24:38Z"`
+	Status      string            `json:"status" example:"Pending"`
+	Spec        CourseCRDSpec     `json:"spec"`
+	CRDStatus   CourseCRDStatus   `json:"crdStatus"`
+	Events      []KubernetesEvent `json:"events"`
+	Pods        []JobPod          `json:"pods"`
+}
+
+type CourseCRDSpec struct {
+	Schedule       []string          `json:"schedule" example:"* * * * * *"`
+	WritableVolume CRDWritableVolume `json:"writableVolume"`
+	Gpu            int32             `json:"gpu" example:"1" format:"int32"`
+	Image          string            `json:"image" example:"nvidia/caffe:latest"`
+	Dataset        []string          `json:"dataset" example:"dataset-cifar-10"`
+	AccessType     string            `json:"accessType" example:"NodePort"`
+}
+
+type CRDWritableVolume struct {
+	Owner        string `json:"owner" example:"jimmy191@teacher"`
+	StorageClass string `json:"storageclass" example:"nchc-ai-nfs"`
+	Uid          int64  `json:"uid" example:"2000620001" format:"int64"`
+	MountPoint   string `json:"mountPoint" example:"/tmp/work"`
+}
+
+type CourseCRDStatus struct {
+	Accessible  bool   `json:"accessible" example:"false" format:"bool"`
+	ServiceName string `json:"service" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c-svc"`
+}
+
+type JobPod struct {
+	Name       string            `json:"name" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c-5c6b7d9f4-abcde"`
+	Phase      string            `json:"phase" example:"Pending"`
+	NodeName   string            `json:"nodeName" example:"gpu-node-1"`
+	Conditions []PodCondition    `json:"conditions"`
+	Events     []KubernetesEvent `json:"events"`
+}
+
+type PodCondition struct {
+	Type    string `json:"type" example:"PodScheduled"`
+	Status  string `json:"status" example:"False"`
+	Reason  string `json:"reason" example:"Unschedulable"`
+	Message string `json:"message" example:"0/3 nodes are available: 3 Insufficient nvidia.com/gpu."`
+}
+
+type KubernetesEvent struct {
+	Type          string `json:"type" example:"Warning"`
+	Reason        string `json:"reason" example:"FailedScheduling"`
+	Message       string `json:"message" example:"0/3 nodes are available: 3 Insufficient nvidia.com/gpu."`
+	Count         int32  `json:"count" example:"3" format:"int32"`
+	LastTimestamp string `json:"lastTimestamp" example:"2018-06-25T09:25:38Z"`
 }
 
 type JobListResponse struct {
@@ -23,18 +120,45 @@ type JobListResponse struct {
 }
 
 type JobInfo struct {
-	Id           string          `json:"id" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c"`
-	CourseID     string          `json:"course_id" example:"b86b2893-b876-45c2-a3f6-5e099c15d638"`
-	StartAt      string          `json:"startAt" example:"2018-06-25T09:24:38Z"`
-	Status       string          `json:"status" example:"Ready"`
-	Name         string          `json:"name" example:"mage process"`
-	Introduction string          `json:"introduction" example:"markdown text with escape"`
-	Image        string          `json:"image" example:"nvidia/caffe:latest"`
-	GPU          uint8           `json:"gpu" example:"1" format:"int64"`
-	Level        string          `json:"level" example:"basic"`
-	CanSnapshot  bool            `json:"canSnapshot" example:"true" format:"bool"`
-	Dataset      []string        `json:"dataset" example:"cifar-10,mnist"`
-	Service      []SVCLabelValue `json:"service"`
+	Id            string          `json:"id" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c"`
+	Type          string          `json:"type" example:"CONTAINER"`
+	CourseID      string          `json:"course_id" example:"b86b2893-b876-45c2-a3f6-5e099c15d638"`
+	StartAt       string          `json:"startAt" example:"2018-06-25T09:24:38Z"`
+	Status        string          `json:"status" example:"Ready"`
+	Name          string          `json:"name" example:"mage process"`
+	Introduction  string          `json:"introduction" example:"markdown text with escape"`
+	Image         string          `json:"image" example:"nvidia/caffe:latest"`
+	GPU           uint8           `json:"gpu" example:"1" format:"int64"`
+	Level         string          `json:"level" example:"basic"`
+	CanSnapshot   bool            `json:"canSnapshot" example:"true" format:"bool"`
+	Dataset       []string        `json:"dataset" example:"cifar-10,mnist"`
+	Service       []SVCLabelValue `json:"service"`
+	ExpireAt      string          `json:"expireAt,omitempty" example:"2018-06-25T10:24:38Z"`
+	QueuePosition int             `json:"queuePosition,omitempty" example:"3" format:"int"`
+}
+
+type ClassroomJobListResponse struct {
+	Error bool               `json:"error" example:"false" format:"bool"`
+	Jobs  []ClassroomJobInfo `json:"jobs"`
+}
+
+type ClassroomJobInfo struct {
+	User          string          `json:"user" example:"student1@gmail.com"`
+	Provider      string          `json:"provider" example:"go-oauth"`
+	Id            string          `json:"id" example:"49a31009-7d1b-4ff2-badd-e8c717e2256c"`
+	Type          string          `json:"type" example:"CONTAINER"`
+	CourseID      string          `json:"course_id" example:"b86b2893-b876-45c2-a3f6-5e099c15d638"`
+	StartAt       string          `json:"startAt" example:"2018-06-25T09:24:38Z"`
+	Status        string          `json:"status" example:"Ready"`
+	Name          string          `json:"name" example:"mage process"`
+	Introduction  string          `json:"introduction" example:"markdown text with escape"`
+	Image         string          `json:"image" example:"nvidia/caffe:latest"`
+	GPU           uint8           `json:"gpu" example:"1" format:"int64"`
+	Level         string          `json:"level" example:"basic"`
+	CanSnapshot   bool            `json:"canSnapshot" example:"false" format:"bool"`
+	Service       []SVCLabelValue `json:"service"`
+	ExpireAt      string          `json:"expireAt,omitempty" example:"2018-06-25T10:24:38Z"`
+	QueuePosition int             `json:"queuePosition,omitempty" example:"3" format:"int"`
 }
 
 type SVCLabelValue struct {
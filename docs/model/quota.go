@@ -0,0 +1,20 @@
+package docs
+
+type Quota struct {
+	Scope     string `json:"scope" example:"classroom" format:"string"`
+	Target    string `json:"target" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	MaxJobs   int    `json:"maxJobs,omitempty" example:"2" format:"int"`
+	MaxGpu    int32  `json:"maxGpu,omitempty" example:"1" format:"int32"`
+	MaxCpu    int64  `json:"maxCpu,omitempty" example:"4000" format:"int64"`
+	MaxMemory int64  `json:"maxMemory,omitempty" example:"8192" format:"int64"`
+}
+
+type QuotaListResponse struct {
+	Error  bool    `json:"error" example:"false" format:"bool"`
+	Quotas []Quota `json:"quotas"`
+}
+
+type QuotaTarget struct {
+	Scope  string `json:"scope" example:"user" format:"string"`
+	Target string `json:"target" example:"go-oauth:student1" format:"string"`
+}
@@ -0,0 +1,16 @@
+package docs
+
+type ReconcileResponse struct {
+	Error  bool    `json:"error" example:"false" format:"bool"`
+	DryRun bool    `json:"dryRun" example:"true" format:"bool"`
+	Drifts []Drift `json:"drifts"`
+}
+
+type Drift struct {
+	Kind      string `json:"kind" example:"job" format:"string"`
+	Namespace string `json:"namespace,omitempty" example:"aitrain-public" format:"string"`
+	Name      string `json:"name" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee" format:"string"`
+	Reason    string `json:"reason" example:"Course CRD is not found" format:"string"`
+	Repaired  bool   `json:"repaired" example:"false" format:"bool"`
+	Error     string `json:"error,omitempty" example:"" format:"string"`
+}
@@ -0,0 +1,28 @@
+package docs
+
+type ReservationRequest struct {
+	ClassroomId string `json:"classroom_id" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	Gpu         int32  `json:"gpu" example:"4" format:"int32"`
+	StartAt     string `json:"startAt" example:"2018-06-25T01:00:00Z"`
+	EndAt       string `json:"endAt" example:"2018-06-25T04:00:00Z"`
+}
+
+type Reservation struct {
+	Id          string `json:"id" example:"8a4c3f0e-5b1d-4e57-9d37-0c3b8f8a2d61" format:"string"`
+	CreateAt    string `json:"createAt" example:"2018-06-20T02:00:00Z"`
+	User        string `json:"user" example:"teacher1" format:"string"`
+	ClassroomId string `json:"classroom_id" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	Gpu         int32  `json:"gpu" example:"4" format:"int32"`
+	StartAt     string `json:"startAt" example:"2018-06-25T01:00:00Z"`
+	EndAt       string `json:"endAt" example:"2018-06-25T04:00:00Z"`
+}
+
+type ReservationListResponse struct {
+	Error        bool          `json:"error" example:"false" format:"bool"`
+	Reservations []Reservation `json:"reservations"`
+}
+
+type ReservationResponse struct {
+	Error       bool        `json:"error" example:"false" format:"bool"`
+	Reservation Reservation `json:"reservation"`
+}
@@ -0,0 +1,34 @@
+package docs
+
+type ScheduleJobRequest struct {
+	User           string `json:"user" example:"student1" format:"string"`
+	CourseId       string `json:"course_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee" format:"string"`
+	ClassroomId    string `json:"classroom_id" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	StartAt        string `json:"startAt,omitempty" example:"2018-06-25T00:55:00Z"`
+	StopAt         string `json:"stopAt,omitempty" example:"2018-06-25T04:00:00Z"`
+	BeforeClass    int    `json:"beforeClass,omitempty" example:"5" format:"int"`
+	StopAtClassEnd bool   `json:"stopAtClassEnd,omitempty" example:"true" format:"bool"`
+}
+
+type ScheduledJob struct {
+	Id          string `json:"id" example:"3e0c1b8a-6f55-4d36-a2a8-1f0e6c9d7b42" format:"string"`
+	CreateAt    string `json:"createAt" example:"2018-06-20T02:00:00Z"`
+	User        string `json:"user" example:"student1" format:"string"`
+	CourseId    string `json:"course_id" example:"5ab02011-9ab7-40c3-b691-d335f93a12ee" format:"string"`
+	ClassroomId string `json:"classroom_id" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	StartAt     string `json:"startAt" example:"2018-06-25T00:55:00Z"`
+	StopAt      string `json:"stopAt,omitempty" example:"2018-06-25T04:00:00Z"`
+	Status      string `json:"status" example:"Pending" format:"string"`
+	JobId       string `json:"job_id,omitempty" example:"131ba8a9-b60b-44f9-83b5-46590f756f41" format:"string"`
+	Message     string `json:"message,omitempty" example:"" format:"string"`
+}
+
+type ScheduledJobListResponse struct {
+	Error     bool           `json:"error" example:"false" format:"bool"`
+	Schedules []ScheduledJob `json:"schedules"`
+}
+
+type ScheduledJobResponse struct {
+	Error    bool         `json:"error" example:"false" format:"bool"`
+	Schedule ScheduledJob `json:"schedule"`
+}
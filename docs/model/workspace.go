@@ -0,0 +1,33 @@
+package docs
+
+type WorkspaceInfo struct {
+	ClassroomId   string `json:"classroom_id" example:"0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1" format:"string"`
+	ClassroomName string `json:"classroomName,omitempty" example:"深度學習入門" format:"string"`
+	Name          string `json:"name" example:"student1-workspace" format:"string"`
+	Owner         string `json:"owner" example:"student1" format:"string"`
+	Provider      string `json:"provider" example:"go-oauth" format:"string"`
+	Size          string `json:"size" example:"10Gi" format:"string"`
+	StorageClass  string `json:"storageClass,omitempty" example:"nchc-ai-nfs" format:"string"`
+	Status        string `json:"status" example:"Bound" format:"string"`
+	CreatedAt     string `json:"createAt" example:"2018-06-25T09:24:38Z"`
+	LastUsedAt    string `json:"lastUsedAt,omitempty" example:"2018-06-26T04:00:00Z"`
+	InUse         bool   `json:"inUse" example:"false" format:"bool"`
+}
+
+type WorkspaceListResponse struct {
+	Error      bool            `json:"error" example:"false" format:"bool"`
+	Workspaces []WorkspaceInfo `json:"workspaces"`
+}
+
+type WorkspaceGCResponse struct {
+	Error      bool          `json:"error" example:"false" format:"bool"`
+	DryRun     bool          `json:"dryRun" example:"true" format:"bool"`
+	Workspaces []WorkspaceGC `json:"workspaces"`
+}
+
+type WorkspaceGC struct {
+	WorkspaceInfo
+	Reason  string `json:"reason" example:"user {student1} is not enrolled in classroom {0f54fe5d-3cf2-4d5b-9bd6-1b5e2a9cf4b1}" format:"string"`
+	Deleted bool   `json:"deleted" example:"false" format:"bool"`
+	Error   string `json:"error,omitempty" example:"" format:"string"`
+}
@@ -3,17 +3,21 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	goredis "github.com/go-redis/redis/v8"
 	beta "github.com/nchc-ai/backend-api/pkg/appsbeta"
+	"github.com/nchc-ai/backend-api/pkg/consts"
 	"github.com/nchc-ai/backend-api/pkg/model/db"
-	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
+	"github.com/nchc-ai/backend-api/pkg/util"
 	"github.com/nitishm/go-rejson/v4"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/golang/glog"
+	"github.com/gorilla/websocket"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
 	"github.com/nchc-ai/backend-api/pkg/model/config"
@@ -23,6 +27,7 @@ import (
 	provider_inerface "github.com/nchc-ai/oauth-provider/pkg/provider"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 type APIServer struct {
@@ -31,6 +36,7 @@ type APIServer struct {
 	db                        *gorm.DB
 	redis                     *rejson.Handler
 	isSecure                  bool
+	webhook                   config.WebhookConfig
 	authMiddleware            gin.HandlerFunc
 	corsMiddleware            gin.HandlerFunc
 	addProviderNameMiddleware gin.HandlerFunc
@@ -76,8 +82,9 @@ func NewAPIServer(config *config.Config) *APIServer {
 		db:        dbclient,
 		redis:     rh,
 		clientSet: NewClientset(kclient, crdclient, config, dbclient, providerProxy, rh),
-		router:    gin.Default(),
+		router:    newRouter(),
 		isSecure:  config.APIConfig.EnableSecureAPI,
+		webhook:   config.APIConfig.Webhook,
 
 		corsMiddleware: func(c *gin.Context) {
 			c.Header("Access-Control-Allow-Origin", "*")
@@ -85,12 +92,30 @@ func NewAPIServer(config *config.Config) *APIServer {
 			c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
 			c.Next()
 		},
-		authMiddleware:            authMiddleware(providerProxy),
+		authMiddleware:            authMiddleware(providerProxy, dbclient),
 		addProviderNameMiddleware: addProviderNameMiddleware(providerProxy),
 	}
 
-	log.Info("Check pending jobRoute after api server restart")
-	go server.resume(crdclient)
+	log.Info("Start job status controller")
+	go server.Beta().JobStatusController().Run(wait.NeverStop)
+
+	log.Info("Start job reaper")
+	go server.Beta().JobReaper().Run(wait.NeverStop)
+
+	log.Info("Start reconciler")
+	go server.Beta().Reconciler().Run(wait.NeverStop)
+
+	log.Info("Start launch queue")
+	go server.Beta().LaunchQueue().Run(wait.NeverStop)
+
+	log.Info("Start job scheduler")
+	go server.Beta().JobScheduler().Run(wait.NeverStop)
+
+	log.Info("Start image committer")
+	go server.Beta().ImageCommitter().Run(wait.NeverStop)
+
+	log.Info("Start image catalog refresher")
+	go server.Beta().ImageCatalog().Run(wait.NeverStop)
 
 	return server
 }
@@ -108,6 +133,10 @@ func (s *APIServer) RunServer(port int) error {
 	s.addAPIRoute(s.isSecure)
 	s.addSwaggerRoute()
 
+	if s.webhook.CertFile != "" {
+		go s.runWebhook()
+	}
+
 	err := s.router.Run(":" + strconv.Itoa(port))
 	if err != nil {
 		return err
@@ -115,6 +144,22 @@ func (s *APIServer) RunServer(port int) error {
 	return nil
 }
 
+// runWebhook serves mutating admission webhook with TLS on its own port, without middleware of API routes.
+func (s *APIServer) runWebhook() {
+	port := s.webhook.Port
+	if port <= 0 {
+		port = 8443
+	}
+
+	router := newRouter()
+	router.POST("/webhook/mutate/deployment", s.Beta().Webhook().MutateDeployment)
+
+	log.Infof("Start admission webhook on port %d", port)
+	if err := router.RunTLS(":"+strconv.Itoa(port), s.webhook.CertFile, s.webhook.KeyFile); err != nil {
+		log.Fatalf("start admission webhook error: %s", err.Error())
+	}
+}
+
 func (s *APIServer) addAPIRoute(isSecure bool) {
 	s.courseRoute(isSecure)
 	s.jobRoute(isSecure)
@@ -124,6 +169,11 @@ func (s *APIServer) addAPIRoute(isSecure bool) {
 	s.proxyRoute(isSecure)
 	s.imageRoute(isSecure)
 	s.userRoute(isSecure)
+	s.quotaRoute(isSecure)
+	s.reservationRoute(isSecure)
+	s.scheduleRoute(isSecure)
+	s.reconcileRoute(isSecure)
+	s.workspaceRoute(isSecure)
 }
 
 func (s *APIServer) courseRoute(isSecure bool) {
@@ -154,11 +204,11 @@ func (s *APIServer) courseRoute(isSecure bool) {
 	if isSecure {
 		courseAuth := s.router.Group("/api").Group("/beta").Group("/course").Use(s.authMiddleware)
 		{
-			courseAuth.POST("/create", s.Beta().Course().Add)
-			courseAuth.POST("/list", s.Beta().Course().ListUserCourse)
-			courseAuth.DELETE("/delete/:id", s.Beta().Course().Delete)
-			courseAuth.GET("/get/:id", s.Beta().Course().Get)
-			courseAuth.PUT("/update", s.Beta().Course().Update)
+			courseAuth.POST("/create", s.authorize(OpCourseWrite), s.Beta().Course().Add)
+			courseAuth.POST("/list", s.authorize(OpCourseRead), s.Beta().Course().ListUserCourse)
+			courseAuth.DELETE("/delete/:id", s.authorize(OpCourseWrite), s.Beta().Course().Delete)
+			courseAuth.GET("/get/:id", s.authorize(OpCourseRead), s.Beta().Course().Get)
+			courseAuth.PUT("/update", s.authorize(OpCourseWrite), s.Beta().Course().Update)
 		}
 	}
 
@@ -170,20 +220,50 @@ func (s *APIServer) jobRoute(isSecure bool) {
 		jobBeta.OPTIONS("/list", handleOption)
 		jobBeta.OPTIONS("/delete/:id", handleOption)
 		jobBeta.OPTIONS("/launch", handleOption)
+		jobBeta.OPTIONS("/watch", handleOption)
+		jobBeta.OPTIONS("/extend/:id", handleOption)
+		jobBeta.OPTIONS("/get/:id", handleOption)
+		jobBeta.OPTIONS("/logs/:id", handleOption)
+		jobBeta.OPTIONS("/exec/:id", handleOption)
+		jobBeta.OPTIONS("/download/:id", handleOption)
+		jobBeta.OPTIONS("/upload/:id", handleOption)
+		jobBeta.OPTIONS("/classroom/launch", handleOption)
+		jobBeta.OPTIONS("/classroom/stop", handleOption)
+		jobBeta.OPTIONS("/classroom/list/:id", handleOption)
 
 		if !isSecure {
 			jobBeta.POST("/list", s.Beta().Job().List)
 			jobBeta.DELETE("/delete/:id", s.Beta().Job().Delete)
 			jobBeta.POST("/launch", s.Beta().Job().Launch)
+			jobBeta.GET("/watch", s.Beta().Job().Watch)
+			jobBeta.POST("/extend/:id", s.Beta().Job().Extend)
+			jobBeta.GET("/get/:id", s.Beta().Job().Get)
+			jobBeta.GET("/logs/:id", s.Beta().Job().Logs)
+			jobBeta.GET("/exec/:id", s.Beta().Job().Exec)
+			jobBeta.GET("/download/:id", s.Beta().Job().Download)
+			jobBeta.POST("/upload/:id", s.Beta().Job().Upload)
+			jobBeta.POST("/classroom/launch", s.Beta().Job().LaunchClassroom)
+			jobBeta.DELETE("/classroom/stop", s.Beta().Job().StopClassroom)
+			jobBeta.GET("/classroom/list/:id", s.Beta().Job().ListClassroom)
 		}
 	}
 
 	if isSecure {
 		jobBetaAuth := s.router.Group("/api").Group("/beta").Group("/job").Use(s.authMiddleware)
 		{
-			jobBetaAuth.POST("/list", s.Beta().Job().List)
-			jobBetaAuth.DELETE("/delete/:id", s.Beta().Job().Delete)
-			jobBetaAuth.POST("/launch", s.Beta().Job().Launch)
+			jobBetaAuth.POST("/list", s.authorize(OpJobRead), s.Beta().Job().List)
+			jobBetaAuth.DELETE("/delete/:id", s.authorize(OpJobWrite), s.Beta().Job().Delete)
+			jobBetaAuth.POST("/launch", s.authorize(OpJobWrite), s.Beta().Job().Launch)
+			jobBetaAuth.GET("/watch", s.authorize(OpJobRead), s.Beta().Job().Watch)
+			jobBetaAuth.POST("/extend/:id", s.authorize(OpJobWrite), s.Beta().Job().Extend)
+			jobBetaAuth.GET("/get/:id", s.authorize(OpJobRead), s.Beta().Job().Get)
+			jobBetaAuth.GET("/logs/:id", s.authorize(OpJobRead), s.Beta().Job().Logs)
+			jobBetaAuth.GET("/exec/:id", s.authorize(OpJobWrite), s.Beta().Job().Exec)
+			jobBetaAuth.GET("/download/:id", s.authorize(OpJobRead), s.Beta().Job().Download)
+			jobBetaAuth.POST("/upload/:id", s.authorize(OpJobWrite), s.Beta().Job().Upload)
+			jobBetaAuth.POST("/classroom/launch", s.authorize(OpJobClassroom), s.Beta().Job().LaunchClassroom)
+			jobBetaAuth.DELETE("/classroom/stop", s.authorize(OpJobClassroom), s.Beta().Job().StopClassroom)
+			jobBetaAuth.GET("/classroom/list/:id", s.authorize(OpJobClassroom), s.Beta().Job().ListClassroom)
 		}
 	}
 }
@@ -212,13 +292,13 @@ func (s *APIServer) classroomRoute(isSecure bool) {
 	if isSecure {
 		classroomBetaAuth := s.router.Group("/api").Group("/beta").Group("/classroom").Use(s.authMiddleware)
 		{
-			classroomBetaAuth.POST("/list", s.Beta().Classroom().List)
-			classroomBetaAuth.GET("/list", s.Beta().Classroom().ListAll)
-			classroomBetaAuth.DELETE("/delete/:id", s.Beta().Classroom().Delete)
-			classroomBetaAuth.POST("/create", s.Beta().Classroom().Add)
-			classroomBetaAuth.POST("/upload", s.Beta().Classroom().UploadUserAccount)
-			classroomBetaAuth.GET("/get/:id", s.Beta().Classroom().Get)
-			classroomBetaAuth.PUT("/update", s.Beta().Classroom().Update)
+			classroomBetaAuth.POST("/list", s.authorize(OpClassroomRead), s.Beta().Classroom().List)
+			classroomBetaAuth.GET("/list", s.authorize(OpClassroomAdmin), s.Beta().Classroom().ListAll)
+			classroomBetaAuth.DELETE("/delete/:id", s.authorize(OpClassroomWrite), s.Beta().Classroom().Delete)
+			classroomBetaAuth.POST("/create", s.authorize(OpClassroomWrite), s.Beta().Classroom().Add)
+			classroomBetaAuth.POST("/upload", s.authorize(OpClassroomWrite), s.Beta().Classroom().UploadUserAccount)
+			classroomBetaAuth.GET("/get/:id", s.authorize(OpClassroomRead), s.Beta().Classroom().Get)
+			classroomBetaAuth.PUT("/update", s.authorize(OpClassroomWrite), s.Beta().Classroom().Update)
 		}
 	}
 }
@@ -236,7 +316,7 @@ func (s *APIServer) datasetRoute(isSecure bool) {
 	if isSecure {
 		datasetAuth := s.router.Group("/api").Group("/beta").Group("/datasets").Use(s.authMiddleware)
 		{
-			datasetAuth.GET("/", s.Beta().Dataset().List)
+			datasetAuth.GET("/", s.authorize(OpDatasetRead), s.Beta().Dataset().List)
 		}
 	}
 }
@@ -260,8 +340,8 @@ func (s *APIServer) healthRoute(isSecure bool) {
 	if isSecure {
 		healthAuth := s.router.Group("/api").Group("/beta").Group("/health").Use(s.authMiddleware)
 		{
-			healthAuth.GET("/kubernetesAuth", s.Beta().Health().CheckK8sAuth)
-			healthAuth.POST("/databaseAuth", s.Beta().Health().CheckDatabaseAuth)
+			healthAuth.GET("/kubernetesAuth", s.authorize(OpHealthRead), s.Beta().Health().CheckK8sAuth)
+			healthAuth.POST("/databaseAuth", s.authorize(OpHealthRead), s.Beta().Health().CheckDatabaseAuth)
 		}
 	}
 }
@@ -270,7 +350,6 @@ func (s *APIServer) proxyRoute(isSecure bool) {
 	proxy := s.router.Group("/api").Group("/beta").Group("/proxy")
 	{
 		proxy.POST("/token", s.Beta().Proxy().GetToken)
-		proxy.POST("/register", s.Beta().Proxy().RegisterUser)
 		proxy.POST("/refresh", s.Beta().Proxy().RefreshToken)
 		proxy.POST("/introspection", s.Beta().Proxy().Introspection)
 		proxy.OPTIONS("/introspection", handleOption)
@@ -283,20 +362,24 @@ func (s *APIServer) proxyRoute(isSecure bool) {
 		proxy.OPTIONS("/refresh", handleOption)
 
 		if !isSecure {
+			proxy.POST("/register", s.Beta().Proxy().RegisterUser)
 			proxy.POST("/logout", s.Beta().Proxy().Logout)
 			proxy.POST("/update", s.Beta().Proxy().UpdateUserBasicInfo)
 			proxy.POST("/changePW", s.Beta().Proxy().ChangeUserPassword)
 			proxy.GET("/query", s.Beta().Proxy().QueryUser)
+		} else {
+			// registration is open to anyone, but caller is authenticated if token is given, so superuser can register other roles
+			proxy.POST("/register", optionalAuth(s.authMiddleware), s.Beta().Proxy().RegisterUser)
 		}
 	}
 
 	if isSecure {
 		proxyAuth := s.router.Group("/api").Group("/beta").Group("/proxy").Use(s.authMiddleware)
 		{
-			proxyAuth.POST("/logout", s.Beta().Proxy().Logout)
-			proxyAuth.POST("/update", s.Beta().Proxy().UpdateUserBasicInfo)
-			proxyAuth.POST("/changePW", s.Beta().Proxy().ChangeUserPassword)
-			proxyAuth.GET("/query", s.Beta().Proxy().QueryUser)
+			proxyAuth.POST("/logout", s.authorize(OpProxyUser), s.Beta().Proxy().Logout)
+			proxyAuth.POST("/update", s.authorize(OpProxyUser), s.Beta().Proxy().UpdateUserBasicInfo)
+			proxyAuth.POST("/changePW", s.authorize(OpProxyUser), s.Beta().Proxy().ChangeUserPassword)
+			proxyAuth.GET("/query", s.authorize(OpProxyUser), s.Beta().Proxy().QueryUser)
 		}
 	}
 }
@@ -306,18 +389,40 @@ func (s *APIServer) imageRoute(isSecure bool) {
 	{
 		image.OPTIONS("/", handleOption)
 		image.OPTIONS("/commit", handleOption)
+		image.OPTIONS("/commit/list", handleOption)
+		image.OPTIONS("/commit/get/:id", handleOption)
+		image.OPTIONS("/commit/logs/:id", handleOption)
+		image.OPTIONS("/refresh", handleOption)
+		image.OPTIONS("/policy/list", handleOption)
+		image.OPTIONS("/policy/set", handleOption)
+		image.OPTIONS("/policy/delete", handleOption)
 
 		if !isSecure {
 			image.GET("/", s.Beta().Image().List)
 			image.POST("/commit", s.Beta().Image().Commit)
+			image.GET("/commit/list", s.Beta().Image().CommitList)
+			image.GET("/commit/get/:id", s.Beta().Image().CommitGet)
+			image.GET("/commit/logs/:id", s.Beta().Image().CommitLogs)
+			image.POST("/refresh", s.Beta().Catalog().Refresh)
+			image.GET("/policy/list", s.Beta().ImagePolicy().List)
+			image.PUT("/policy/set", s.Beta().ImagePolicy().Set)
+			image.DELETE("/policy/delete", s.Beta().ImagePolicy().Delete)
 		}
 	}
 
 	if isSecure {
 		imageAuth := s.router.Group("/api").Group("/beta").Group("/images").Use(s.authMiddleware)
 		{
-			imageAuth.GET("/", s.Beta().Image().List)
-			imageAuth.POST("/commit", s.Beta().Image().Commit)
+			imageAuth.GET("/", s.authorize(OpImageRead), s.Beta().Image().List)
+			imageAuth.POST("/commit", s.authorize(OpImageWrite), s.Beta().Image().Commit)
+			imageAuth.GET("/commit/list", s.authorize(OpImageRead), s.Beta().Image().CommitList)
+			imageAuth.GET("/commit/get/:id", s.authorize(OpImageRead), s.Beta().Image().CommitGet)
+			imageAuth.GET("/commit/logs/:id", s.authorize(OpImageRead), s.Beta().Image().CommitLogs)
+			imageAuth.POST("/refresh", s.authorize(OpImageAdmin), s.Beta().Catalog().Refresh)
+			// global rules are further limited to superuser by handler
+			imageAuth.GET("/policy/list", s.authorize(OpImageRead), s.Beta().ImagePolicy().List)
+			imageAuth.PUT("/policy/set", s.authorize(OpImageWrite), s.Beta().ImagePolicy().Set)
+			imageAuth.DELETE("/policy/delete", s.authorize(OpImageWrite), s.Beta().ImagePolicy().Delete)
 		}
 	}
 }
@@ -333,42 +438,150 @@ func (s *APIServer) userRoute(isSecure bool) {
 	if isSecure {
 		userAuth := s.router.Group("/api").Group("/beta").Group("/user").Use(s.authMiddleware)
 		{
-			userAuth.GET("/role/:roleid", s.Beta().User().RoleList)
+			userAuth.GET("/role/:roleid", s.authorize(OpUserRead), s.Beta().User().RoleList)
 		}
 	}
 }
 
-func (s *APIServer) addSwaggerRoute() {
-	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+func (s *APIServer) quotaRoute(isSecure bool) {
+	quota := s.router.Group("/api").Group("/beta").Group("/quota")
+	{
+		quota.OPTIONS("/list", handleOption)
+		quota.OPTIONS("/set", handleOption)
+		quota.OPTIONS("/delete", handleOption)
+
+		if !isSecure {
+			quota.GET("/list", s.Beta().Quota().List)
+			quota.PUT("/set", s.Beta().Quota().Set)
+			quota.DELETE("/delete", s.Beta().Quota().Delete)
+		}
+	}
+
+	if isSecure {
+		quotaAuth := s.router.Group("/api").Group("/beta").Group("/quota").Use(s.authMiddleware)
+		{
+			quotaAuth.GET("/list", s.authorize(OpQuotaAdmin), s.Beta().Quota().List)
+			quotaAuth.PUT("/set", s.authorize(OpQuotaAdmin), s.Beta().Quota().Set)
+			quotaAuth.DELETE("/delete", s.authorize(OpQuotaAdmin), s.Beta().Quota().Delete)
+		}
+	}
 }
 
-func (s *APIServer) resume(crdClient *versioned.Clientset) {
-	job := db.Job{
-		Status: beta.JobStatueReady,
+func (s *APIServer) reservationRoute(isSecure bool) {
+	reservation := s.router.Group("/api").Group("/beta").Group("/reservation")
+	{
+		reservation.OPTIONS("/list/:id", handleOption)
+		reservation.OPTIONS("/create", handleOption)
+		reservation.OPTIONS("/delete/:id", handleOption)
+
+		if !isSecure {
+			reservation.GET("/list/:id", s.Beta().Reservation().List)
+			reservation.POST("/create", s.Beta().Reservation().Add)
+			reservation.DELETE("/delete/:id", s.Beta().Reservation().Delete)
+		}
+	}
+
+	if isSecure {
+		reservationAuth := s.router.Group("/api").Group("/beta").Group("/reservation").Use(s.authMiddleware)
+		{
+			reservationAuth.GET("/list/:id", s.authorize(OpReservation), s.Beta().Reservation().List)
+			reservationAuth.POST("/create", s.authorize(OpReservation), s.Beta().Reservation().Add)
+			reservationAuth.DELETE("/delete/:id", s.authorize(OpReservation), s.Beta().Reservation().Delete)
+		}
+	}
+}
+
+func (s *APIServer) scheduleRoute(isSecure bool) {
+	schedule := s.router.Group("/api").Group("/beta").Group("/schedule")
+	{
+		schedule.OPTIONS("/list", handleOption)
+		schedule.OPTIONS("/create", handleOption)
+		schedule.OPTIONS("/update/:id", handleOption)
+		schedule.OPTIONS("/delete/:id", handleOption)
+
+		if !isSecure {
+			schedule.GET("/list", s.Beta().Schedule().List)
+			schedule.POST("/create", s.Beta().Schedule().Add)
+			schedule.PUT("/update/:id", s.Beta().Schedule().Update)
+			schedule.DELETE("/delete/:id", s.Beta().Schedule().Delete)
+		}
+	}
+
+	if isSecure {
+		scheduleAuth := s.router.Group("/api").Group("/beta").Group("/schedule").Use(s.authMiddleware)
+		{
+			scheduleAuth.GET("/list", s.authorize(OpJobRead), s.Beta().Schedule().List)
+			scheduleAuth.POST("/create", s.authorize(OpJobWrite), s.Beta().Schedule().Add)
+			scheduleAuth.PUT("/update/:id", s.authorize(OpJobWrite), s.Beta().Schedule().Update)
+			scheduleAuth.DELETE("/delete/:id", s.authorize(OpJobWrite), s.Beta().Schedule().Delete)
+		}
+	}
+}
+
+func (s *APIServer) reconcileRoute(isSecure bool) {
+	reconcile := s.router.Group("/api").Group("/beta").Group("/reconcile")
+	{
+		reconcile.OPTIONS("/run", handleOption)
+
+		if !isSecure {
+			reconcile.POST("/run", s.Beta().Reconcile().Check)
+		}
 	}
-	resultJobs := []db.Job{}
-	if err := s.db.Not(&job).Find(&resultJobs).Error; err != nil {
-		log.Warningf("find Job in Pending state fail: %s", err.Error())
-		return
+
+	if isSecure {
+		reconcileAuth := s.router.Group("/api").Group("/beta").Group("/reconcile").Use(s.authMiddleware)
+		{
+			reconcileAuth.POST("/run", s.authorize(OpReconcile), s.Beta().Reconcile().Check)
+		}
+	}
+}
+
+func (s *APIServer) workspaceRoute(isSecure bool) {
+	workspace := s.router.Group("/api").Group("/beta").Group("/workspace")
+	{
+		workspace.OPTIONS("/list", handleOption)
+		workspace.OPTIONS("/delete/:classroom/:name", handleOption)
+		workspace.OPTIONS("/reset/:classroom/:name", handleOption)
+		workspace.OPTIONS("/gc", handleOption)
+
+		if !isSecure {
+			workspace.GET("/list", s.Beta().Workspace().List)
+			workspace.DELETE("/delete/:classroom/:name", s.Beta().Workspace().Delete)
+			workspace.POST("/reset/:classroom/:name", s.Beta().Workspace().Reset)
+			workspace.POST("/gc", s.Beta().Workspace().GarbageCollect)
+		}
 	}
 
-	b := s.clientSet.BetaClient.Job().(*beta.Job)
-	for _, j := range resultJobs {
-		log.Infof("start check Course CRD {%s}", j.ID)
-		b.StopChanMap[j.ID] = make(chan string, 5)
-		b.StopChanMap[j.ID] <- ""
-		go beta.CheckCourseCRDStatus(s.db, s.redis, crdClient, *j.ClassroomID, j.ID, b.StopChanMap[j.ID])
+	if isSecure {
+		workspaceAuth := s.router.Group("/api").Group("/beta").Group("/workspace").Use(s.authMiddleware)
+		{
+			workspaceAuth.GET("/list", s.authorize(OpWorkspace), s.Beta().Workspace().List)
+			workspaceAuth.DELETE("/delete/:classroom/:name", s.authorize(OpWorkspace), s.Beta().Workspace().Delete)
+			workspaceAuth.POST("/reset/:classroom/:name", s.authorize(OpWorkspace), s.Beta().Workspace().Reset)
+			workspaceAuth.POST("/gc", s.authorize(OpWorkspaceAdmin), s.Beta().Workspace().GarbageCollect)
+		}
 	}
 }
 
+func (s *APIServer) addSwaggerRoute() {
+	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+}
+
 func (s *APIServer) Beta() *beta.BetaClient {
 	return s.clientSet.BetaClient
 }
 
 // PRIVATE util func
-func authMiddleware(p provider_inerface.Provider) gin.HandlerFunc {
+
+// authMiddleware validates bearer token, and resolve token owner into provider.UserInfo and local db.User.
+// Both are put in gin context, so handlers use caller identity from token instead of user field in request body.
+func authMiddleware(p provider_inerface.Provider, DB *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		// browser can not set header of websocket request, accept token in query instead
+		if authHeader == "" && websocket.IsWebSocketUpgrade(c.Request) && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
 		if authHeader == "" {
 			log.Error("Authorization header is missing")
 			beta.RespondWithError(c, http.StatusUnauthorized, "Authorization header is missing")
@@ -411,10 +624,85 @@ func authMiddleware(p provider_inerface.Provider) gin.HandlerFunc {
 			beta.RespondWithError(c, http.StatusForbidden, "Invalid API token")
 			return
 		}
+
+		userInfo, err := p.QueryUser(token)
+		if err != nil {
+			log.Errorf("query user from token fail: %s", err.Error())
+			beta.RespondWithError(c, http.StatusInternalServerError, "query user from token fail: %s", err.Error())
+			return
+		}
+
+		u := db.User{
+			User:     userInfo.Username,
+			Provider: util.StringPtr(fmt.Sprintf("%s:%s", p.Type(), p.Name())),
+		}
+		loginUser, err := u.FindUser(DB)
+		if err != nil {
+			log.Errorf("find user {%s} in local DB fail: %s", userInfo.Username, err.Error())
+			beta.RespondWithError(c, http.StatusForbidden, consts.ERROR_LOGIN_ROLE_NOT_FOUND, userInfo.Username)
+			return
+		}
+		userInfo.Role = loginUser.Role
+		userInfo.Repository = loginUser.Repository
+
+		c.Set(consts.ContextUserInfo, userInfo)
+		c.Set(consts.ContextLoginUser, loginUser)
 		c.Next()
 	}
 }
 
+// optionalAuth authenticates caller only if Authorization header is given, so anonymous caller is still allowed.
+func optionalAuth(auth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// newRouter is gin.Default() whose request log redacts access token in query of websocket requests.
+func newRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(redactLogFormatter), gin.Recovery())
+	return router
+}
+
+// redactLogFormatter is gin default log format without color, with access_token in query redacted.
+func redactLogFormatter(param gin.LogFormatterParams) string {
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency - param.Latency%time.Second
+	}
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		redactQuery(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactQuery replaces value of access_token in query of path.
+func redactQuery(path string) string {
+	idx := strings.Index(path, "?")
+	if idx < 0 {
+		return path
+	}
+	query, err := url.ParseQuery(path[idx+1:])
+	if err != nil {
+		// unparsable query may still contain token, drop it
+		return path[:idx] + "?REDACTED"
+	}
+	if _, ok := query["access_token"]; !ok {
+		return path
+	}
+	query.Set("access_token", "REDACTED")
+	return path[:idx] + "?" + query.Encode()
+}
+
 func addProviderNameMiddleware(p provider_inerface.Provider) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		provider := fmt.Sprintf("%s:%s", p.Type(), p.Name())
@@ -123,9 +123,17 @@ func NewDBClient(config *config.Config) (*gorm.DB, error) {
 	job := &db.Job{}
 	dateset := &db.Dataset{}
 	port := &db.Port{}
+	env := &db.EnvVar{}
 	courseid := &db.CourseID{}
 	user := &db.User{}
 	audit := &db.Audit{}
+	quota := &db.Quota{}
+	extensionAudit := &db.ExtensionAudit{}
+	queuedJob := &db.QueuedJob{}
+	reservation := &db.Reservation{}
+	scheduledJob := &db.ScheduledJob{}
+	imageCommit := &db.ImageCommit{}
+	imageRule := &db.ImageRule{}
 
 	classroomInfo := &db.ClassRoomInfo{}
 	classroomInfo1 := &db.ClassRoomInfo{}
@@ -137,7 +145,8 @@ func NewDBClient(config *config.Config) (*gorm.DB, error) {
 	classroomCalendar := &db.ClassRoomCalendarRelation{}
 	classroomSelected := &db.ClassRoomSelectedOptionRelation{}
 
-	DB.AutoMigrate(course, job, dateset, port, courseid, user, audit)
+	DB.AutoMigrate(course, job, dateset, port, env, courseid, user, audit, quota, extensionAudit, queuedJob, reservation, scheduledJob,
+		imageCommit, imageRule)
 
 	DB.AutoMigrate(classroomInfo, classroomCourse, classroomSchedule, classroomStudent, classroomTeacher,
 		classroomCalendar, classroomSelected)
@@ -163,6 +172,8 @@ func NewDBClient(config *config.Config) (*gorm.DB, error) {
 				StartAt:             time.Now().Format("2006-01-02"),
 				EndAt:               time.Now().AddDate(0, 1, 0).Format("2006-01-02"),
 				SelectedType:        util.Int32Ptr(2),
+				// course in public classroom is ONLY allowed run for one hour
+				MaxRuntime: util.Int32Ptr(60),
 			})
 		} else {
 			log.Error(err.Error())
@@ -218,6 +229,7 @@ func NewDBClient(config *config.Config) (*gorm.DB, error) {
 		AddForeignKey("classroom_id", "classroomInfo(id)", "CASCADE", "RESTRICT")
 	DB.Model(dateset).AddForeignKey("course_id", "courseid(id)", "CASCADE", "RESTRICT")
 	DB.Model(port).AddForeignKey("course_id", "courseid(id)", "CASCADE", "RESTRICT")
+	DB.Model(env).AddForeignKey("course_id", "courseid(id)", "CASCADE", "RESTRICT")
 	DB.Model(course).AddForeignKey("id", "courseid(id)", "CASCADE", "RESTRICT")
 
 	DB.Model(classroomCourse).
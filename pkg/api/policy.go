@@ -0,0 +1,100 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	beta "github.com/nchc-ai/backend-api/pkg/appsbeta"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+)
+
+// Operations protected by role based authorization.
+// Every secured route declares the operation it performs in addAPIRoute,
+// and rolePolicy decides which role is allowed to perform it.
+// Ownership (course owner, classroom teacher, job owner) is checked by handlers.
+const (
+	OpCourseRead     = "course:read"
+	OpCourseWrite    = "course:write"
+	OpJobRead        = "job:read"
+	OpJobWrite       = "job:write"
+	OpJobClassroom   = "job:classroom"
+	OpReservation    = "reservation:classroom"
+	OpClassroomRead  = "classroom:read"
+	OpClassroomAdmin = "classroom:admin"
+	OpClassroomWrite = "classroom:write"
+	OpDatasetRead    = "dataset:read"
+	OpHealthRead     = "health:read"
+	OpProxyUser      = "proxy:user"
+	OpImageRead      = "image:read"
+	OpImageWrite     = "image:write"
+	OpImageAdmin     = "image:admin"
+	OpUserRead       = "user:read"
+	OpQuotaAdmin     = "quota:admin"
+	OpReconcile      = "reconcile:admin"
+	OpWorkspace      = "workspace:user"
+	OpWorkspaceAdmin = "workspace:admin"
+)
+
+var studentPolicy = []string{
+	OpCourseRead,
+	OpJobRead,
+	OpJobWrite,
+	OpClassroomRead,
+	OpDatasetRead,
+	OpHealthRead,
+	OpProxyUser,
+	OpImageRead,
+	OpWorkspace,
+}
+
+var teacherPolicy = append([]string{
+	OpCourseWrite,
+	OpJobClassroom,
+	OpReservation,
+	OpClassroomAdmin,
+	OpClassroomWrite,
+	OpImageWrite,
+	OpUserRead,
+}, studentPolicy...)
+
+var superuserPolicy = append([]string{
+	OpQuotaAdmin,
+	OpReconcile,
+	OpWorkspaceAdmin,
+	OpImageAdmin,
+}, teacherPolicy...)
+
+var rolePolicy = map[string][]string{
+	db.ROLE_STUDENT:   studentPolicy,
+	db.ROLE_TEACHER:   teacherPolicy,
+	db.ROLE_SUPERUSER: superuserPolicy,
+}
+
+func isAllowed(role, op string) bool {
+	for _, o := range rolePolicy[role] {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize must be used after authMiddleware, it rejects login user whose role is not allowed to perform op.
+func (s *APIServer) authorize(op string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, exist := c.Get(consts.ContextLoginUser)
+		if !exist {
+			log.Errorf("login user is not found when authorize operation {%s}", op)
+			beta.RespondWithForbidden(c, consts.FORBIDDEN_ROLE, consts.ERROR_FORBIDDEN_ROLE_FMT, "", op)
+			return
+		}
+
+		loginUser := u.(*db.User)
+		if !isAllowed(loginUser.Role, op) {
+			log.Errorf("user {%s} with role {%s} is not allowed to perform {%s}", loginUser.User, loginUser.Role, op)
+			beta.RespondWithForbidden(c, consts.FORBIDDEN_ROLE, consts.ERROR_FORBIDDEN_ROLE_FMT, loginUser.Role, op)
+			return
+		}
+		c.Next()
+	}
+}
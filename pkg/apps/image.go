@@ -5,4 +5,17 @@ import "github.com/gin-gonic/gin"
 type ImageInterface interface {
 	List(c *gin.Context)
 	Commit(c *gin.Context)
+	CommitList(c *gin.Context)
+	CommitGet(c *gin.Context)
+	CommitLogs(c *gin.Context)
+}
+
+type ImageCatalogInterface interface {
+	Refresh(c *gin.Context)
+}
+
+type ImagePolicyInterface interface {
+	List(c *gin.Context)
+	Set(c *gin.Context)
+	Delete(c *gin.Context)
 }
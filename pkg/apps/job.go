@@ -6,4 +6,14 @@ type JobInterface interface {
 	Launch(c *gin.Context)
 	Delete(c *gin.Context)
 	List(c *gin.Context)
+	Watch(c *gin.Context)
+	Extend(c *gin.Context)
+	Get(c *gin.Context)
+	Logs(c *gin.Context)
+	Exec(c *gin.Context)
+	Download(c *gin.Context)
+	Upload(c *gin.Context)
+	LaunchClassroom(c *gin.Context)
+	StopClassroom(c *gin.Context)
+	ListClassroom(c *gin.Context)
 }
@@ -0,0 +1,9 @@
+package apps
+
+import "github.com/gin-gonic/gin"
+
+type QuotaInterface interface {
+	List(c *gin.Context)
+	Set(c *gin.Context)
+	Delete(c *gin.Context)
+}
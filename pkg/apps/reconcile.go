@@ -0,0 +1,7 @@
+package apps
+
+import "github.com/gin-gonic/gin"
+
+type ReconcileInterface interface {
+	Check(c *gin.Context)
+}
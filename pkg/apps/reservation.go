@@ -0,0 +1,9 @@
+package apps
+
+import "github.com/gin-gonic/gin"
+
+type ReservationInterface interface {
+	List(c *gin.Context)
+	Add(c *gin.Context)
+	Delete(c *gin.Context)
+}
@@ -0,0 +1,10 @@
+package apps
+
+import "github.com/gin-gonic/gin"
+
+type ScheduleInterface interface {
+	List(c *gin.Context)
+	Add(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
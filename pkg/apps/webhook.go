@@ -0,0 +1,7 @@
+package apps
+
+import "github.com/gin-gonic/gin"
+
+type WebhookInterface interface {
+	MutateDeployment(c *gin.Context)
+}
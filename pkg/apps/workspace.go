@@ -0,0 +1,10 @@
+package apps
+
+import "github.com/gin-gonic/gin"
+
+type WorkspaceInterface interface {
+	List(c *gin.Context)
+	Delete(c *gin.Context)
+	Reset(c *gin.Context)
+	GarbageCollect(c *gin.Context)
+}
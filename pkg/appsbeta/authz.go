@@ -0,0 +1,131 @@
+package beta
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	rfstackmodel "github.com/nchc-ai/rfstack/model"
+)
+
+// Ownership checks complement role based authorization done in api server.
+// Each check writes 403 response with machine-readable reason and return false if caller is not allowed.
+// When secure api is disabled, there is no login user and every caller is trusted.
+
+func checkCourseOwner(c *gin.Context, DB *gorm.DB, course *db.Course) bool {
+	loginUser, ok := getLoginUser(c)
+	if !ok || loginUser.IsSuperuser() {
+		return true
+	}
+
+	if course.User == loginUser.User && course.Provider == *loginUser.Provider {
+		return true
+	}
+
+	log.Warningf("user {%s} is not owner of course {%s}", loginUser.User, course.ID)
+	RespondWithForbidden(c, consts.FORBIDDEN_COURSE_OWNER,
+		consts.ERROR_FORBIDDEN_COURSE_OWNER_FMT, course.Name, loginUser.User)
+	return false
+}
+
+func checkClassroomTeacher(c *gin.Context, DB *gorm.DB, classroomID string) bool {
+	loginUser, ok := getLoginUser(c)
+	if !ok || loginUser.IsSuperuser() {
+		return true
+	}
+
+	if isClassroomTeacher(DB, classroomID, loginUser) {
+		return true
+	}
+
+	log.Warningf("user {%s} is not teacher of classroom {%s}", loginUser.User, classroomID)
+	RespondWithForbidden(c, consts.FORBIDDEN_CLASSROOM_TEACHER,
+		consts.ERROR_FORBIDDEN_CLASSROOM_TEACHER_FMT, classroomID, loginUser.User)
+	return false
+}
+
+// checkJobOwner allows job launcher, teachers of classroom where job is running and superuser.
+func checkJobOwner(c *gin.Context, DB *gorm.DB, job *db.Job) bool {
+	loginUser, ok := getLoginUser(c)
+	if !ok || loginUser.IsSuperuser() {
+		return true
+	}
+
+	if job.User == loginUser.User && job.Provider == *loginUser.Provider {
+		return true
+	}
+
+	if job.ClassroomID != nil && isClassroomTeacher(DB, *job.ClassroomID, loginUser) {
+		return true
+	}
+
+	log.Warningf("user {%s} is neither owner nor classroom teacher of job {%s}", loginUser.User, job.ID)
+	RespondWithForbidden(c, consts.FORBIDDEN_JOB_OWNER,
+		consts.ERROR_FORBIDDEN_JOB_OWNER_FMT, job.ID, loginUser.User)
+	return false
+}
+
+// checkWorkspaceOwner allows owner of writable volume and superuser.
+func checkWorkspaceOwner(c *gin.Context, owner, provider, name string) bool {
+	loginUser, ok := getLoginUser(c)
+	if !ok || loginUser.IsSuperuser() {
+		return true
+	}
+
+	if owner != "" && owner == loginUser.User && loginUser.Provider != nil && provider == *loginUser.Provider {
+		return true
+	}
+
+	log.Warningf("user {%s} is not owner of workspace {%s}", loginUser.User, name)
+	RespondWithForbidden(c, consts.FORBIDDEN_WORKSPACE_OWNER,
+		consts.ERROR_FORBIDDEN_WORKSPACE_OWNER_FMT, name, loginUser.User)
+	return false
+}
+
+func checkVMJobOwner(c *gin.Context, DB *gorm.DB, jobID string) bool {
+	loginUser, ok := getLoginUser(c)
+	if !ok || loginUser.IsSuperuser() {
+		return true
+	}
+
+	job := rfstackmodel.Job{
+		Model: rfstackmodel.Model{
+			ID: jobID,
+		},
+	}
+	if err := DB.First(&job).Error; err != nil {
+		// let rfstack report job not found
+		if gorm.IsRecordNotFoundError(err) {
+			return true
+		}
+		log.Errorf("query owner of vm job {%s} fail: %s", jobID, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "query owner of vm job {%s} fail: %s", jobID, err.Error())
+		return false
+	}
+
+	if job.User == loginUser.User && job.Provider == *loginUser.Provider {
+		return true
+	}
+
+	log.Warningf("user {%s} is not owner of vm job {%s}", loginUser.User, jobID)
+	RespondWithForbidden(c, consts.FORBIDDEN_JOB_OWNER,
+		consts.ERROR_FORBIDDEN_JOB_OWNER_FMT, jobID, loginUser.User)
+	return false
+}
+
+func isClassroomTeacher(DB *gorm.DB, classroomID string, user *db.User) bool {
+	cm := db.ClassRoomInfo{
+		Model: db.Model{
+			ID: classroomID,
+		},
+	}
+	ok, err := cm.HasTeacher(DB, user.User, *user.Provider)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		log.Warning(fmt.Sprintf("check teacher {%s} of classroom {%s} fail: %s", user.User, classroomID, err.Error()))
+	}
+	return ok
+}
@@ -0,0 +1,323 @@
+package beta
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+)
+
+// status of each classroom member in bulk launch and stop
+const (
+	BulkStatusCreated = "Created"
+	BulkStatusSkipped = "Skipped"
+	BulkStatusQueued  = JobStatusQueued
+	BulkStatusFailed  = "Failed"
+	BulkStatusDeleted = JobStatusDeleted
+)
+
+func (j *Job) bindClassroomJobRequest(c *gin.Context) (*model.ClassroomJobRequest, bool) {
+	var req model.ClassroomJobRequest
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return nil, false
+	}
+
+	if req.ClassroomId == "" || req.CourseId == "" {
+		log.Errorf("classroom_id and course_id can not be empty")
+		RespondWithError(c, http.StatusBadRequest, "classroom_id and course_id can not be empty")
+		return nil, false
+	}
+
+	if !checkClassroomTeacher(c, j.DB, req.ClassroomId) {
+		return nil, false
+	}
+	return &req, true
+}
+
+// @Summary Launch a course for all students of classroom
+// @Description Launch container course for every student of classroom, with quota and classroom schedule checked for each student.
+// @Description Students already running the course are skipped. Jobs wait in launch queue like jobs launched by students,
+// @Description if free GPU is not enough. Result of each student is reported.
+// @Tags Job
+// @Accept  json
+// @Produce  json
+// @Param launch_classroom body docs.ClassroomJobRequest true "classroom and course to launch"
+// @Success 200 {object} docs.ClassroomJobResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/classroom/launch [post]
+func (j *Job) LaunchClassroom(c *gin.Context) {
+	req, ok := j.bindClassroomJobRequest(c)
+	if !ok {
+		return
+	}
+
+	course := db.Course{
+		Model: db.Model{
+			ID: req.CourseId,
+		},
+	}
+	courseType, err := course.Type(j.DB)
+	if err != nil {
+		errStr := fmt.Sprintf("Query course {%s} type fail: %s", req.CourseId, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	if courseType != db.CONTAINER {
+		RespondWithError(c, http.StatusBadRequest, "only container course {%s} can be launched for classroom", req.CourseId)
+		return
+	}
+
+	classroom := db.ClassRoomInfo{
+		Model: db.Model{
+			ID: req.ClassroomId,
+		},
+	}
+	students, err := classroom.GetStudents(j.DB)
+	if err != nil {
+		errStr := fmt.Sprintf("Query students of classroom {%s} fail: %s", req.ClassroomId, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	jobs, err := db.ClassroomCourseJobs(j.DB, req.ClassroomId, req.CourseId)
+	if err != nil {
+		errStr := fmt.Sprintf("Query jobs of course {%s} in classroom {%s} fail: %s", req.CourseId, req.ClassroomId, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	running := map[string]string{}
+	for _, job := range jobs {
+		running[jobOwnerKey(job.User, job.Provider)] = job.ID
+	}
+
+	results := []model.ClassroomJobResult{}
+	for _, student := range students {
+		result := model.ClassroomJobResult{
+			User:     student.User,
+			Provider: student.Provider,
+		}
+
+		if jobID, exist := running[jobOwnerKey(student.User, student.Provider)]; exist {
+			result.JobId = jobID
+			result.Status = BulkStatusSkipped
+			results = append(results, result)
+			continue
+		}
+
+		launchReq := model.LaunchCourseRequest{
+			User:        student.User,
+			CourseId:    req.CourseId,
+			ClassroomId: req.ClassroomId,
+		}
+
+		if isVerified, errs := j.precheckWithClassroom(&launchReq, student.Provider); !isVerified {
+			log.Warningf("Pre-check launch course {%s} job for {%s} fail: %s", req.CourseId, student.User, errs[0].Error())
+			result.Status = BulkStatusFailed
+			result.Message = errs[1].Error()
+			results = append(results, result)
+			continue
+		}
+
+		// go through launch queue, so bulk launch neither jumps ahead of queued jobs nor takes reserved GPU
+		status, errs := j.startContainerJob(&launchReq, student.Provider)
+		if errs != nil {
+			log.Warningf("launch course {%s} job for {%s} fail: %s", req.CourseId, student.User, errs[0].Error())
+			result.Status = BulkStatusFailed
+			result.Message = errs[1].Error()
+			results = append(results, result)
+			continue
+		}
+
+		result.JobId = status.JobId
+		result.Status = BulkStatusCreated
+		if status.Status == JobStatusQueued {
+			result.Status = BulkStatusQueued
+		} else {
+			j.statusCtrl.events.publish(jobOwnerKey(student.User, student.Provider), jobEventStatus, *status)
+		}
+		results = append(results, result)
+	}
+
+	log.Infof("launch course {%s} for %d students of classroom {%s}", req.CourseId, len(students), req.ClassroomId)
+	c.JSON(http.StatusOK, model.ClassroomJobResponse{
+		Error:   false,
+		Results: results,
+	})
+}
+
+// @Summary Stop a course of all members in classroom
+// @Description Delete every running job of course in classroom, and cancel every launch request of the course still waiting in launch queue.
+// @Description Result of each job is reported.
+// @Tags Job
+// @Accept  json
+// @Produce  json
+// @Param stop_classroom body docs.ClassroomJobRequest true "classroom and course to stop"
+// @Success 200 {object} docs.ClassroomJobResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/classroom/stop [delete]
+func (j *Job) StopClassroom(c *gin.Context) {
+	req, ok := j.bindClassroomJobRequest(c)
+	if !ok {
+		return
+	}
+
+	// cancel queued jobs first, so dispatcher does not launch them after running jobs are stopped
+	queued, err := db.ClassroomCourseQueuedJobs(j.DB, req.ClassroomId, req.CourseId)
+	if err != nil {
+		errStr := fmt.Sprintf("Query queued jobs of course {%s} in classroom {%s} fail: %s", req.CourseId, req.ClassroomId, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	results := []model.ClassroomJobResult{}
+	for i := range queued {
+		entry := &queued[i]
+		cancelled, err := j.queue.cancel(entry)
+		if err == nil && !cancelled {
+			// launched by dispatcher in the meantime, it is stopped with running jobs below
+			continue
+		}
+
+		result := model.ClassroomJobResult{
+			User:     entry.User,
+			Provider: entry.Provider,
+			JobId:    entry.ID,
+		}
+		if err != nil {
+			log.Warningf("remove job {%s} of {%s} from launch queue fail: %s", entry.ID, entry.User, err.Error())
+			result.Status = BulkStatusFailed
+			result.Message = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		j.statusCtrl.events.publish(jobOwnerKey(entry.User, entry.Provider), jobEventStatus, model.JobStatus{
+			JobId:  entry.ID,
+			Ready:  false,
+			Status: JobStatusDeleted,
+		})
+		result.Status = BulkStatusDeleted
+		results = append(results, result)
+	}
+
+	jobs, err := db.ClassroomCourseJobs(j.DB, req.ClassroomId, req.CourseId)
+	if err != nil {
+		errStr := fmt.Sprintf("Query jobs of course {%s} in classroom {%s} fail: %s", req.CourseId, req.ClassroomId, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	for _, job := range jobs {
+		result := model.ClassroomJobResult{
+			User:     job.User,
+			Provider: job.Provider,
+			JobId:    job.ID,
+		}
+
+		if errStr, err := job.DeleteCourseCRD(j.DB, j.redis, j.CourseCrdClient, req.ClassroomId); err != nil {
+			log.Warningf("stop job {%s} of {%s} fail: %s", job.ID, job.User, errStr)
+			result.Status = BulkStatusFailed
+			result.Message = errStr
+			results = append(results, result)
+			continue
+		}
+		markAuditDeleted(j.DB, job.ID, DeletedByTeacher)
+
+		j.statusCtrl.events.publish(jobOwnerKey(job.User, job.Provider), jobEventStatus, model.JobStatus{
+			JobId:  job.ID,
+			Ready:  false,
+			Status: JobStatusDeleted,
+		})
+		result.Status = BulkStatusDeleted
+		results = append(results, result)
+	}
+
+	log.Infof("stop %d jobs of course {%s} in classroom {%s}", len(results), req.CourseId, req.ClassroomId)
+	c.JSON(http.StatusOK, model.ClassroomJobResponse{
+		Error:   false,
+		Results: results,
+	})
+}
+
+// @Summary List jobs of all members in classroom
+// @Description List every job running in classroom with owner, status, start time, course, gpu and access URL, for classroom teacher.
+// @Description Job can be stopped by teacher with /beta/job/delete/{id}.
+// @Tags Job
+// @Accept  json
+// @Produce  json
+// @Param id path string true "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Success 200 {object} docs.ClassroomJobListResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/classroom/list/{id} [get]
+func (j *Job) ListClassroom(c *gin.Context) {
+	classroomID := c.Param("id")
+	if classroomID == "" {
+		RespondWithError(c, http.StatusBadRequest, "Classroom Id is empty")
+		return
+	}
+
+	if !checkClassroomTeacher(c, j.DB, classroomID) {
+		return
+	}
+
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+	viewer := callerName(c, "")
+
+	jobs, err := db.ClassroomJobs(j.DB, classroomID)
+	if err != nil {
+		errStr := fmt.Sprintf("Query jobs of classroom {%s} fail: %s", classroomID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	jobList := []model.ClassroomJobInfo{}
+	for _, job := range jobs {
+		info, errStr := j.jobInfo(job, viewer, provider.(string))
+		if errStr != "" {
+			// still show broken job, so teacher is able to stop it
+			log.Warning(errStr)
+			info = &model.JobInfo{
+				Id:       job.ID,
+				CourseID: job.CourseID,
+				StartAt:  job.CreatedAt,
+				Status:   job.Status,
+			}
+		}
+		jobList = append(jobList, model.ClassroomJobInfo{
+			User:     job.User,
+			Provider: job.Provider,
+			JobInfo:  *info,
+		})
+	}
+
+	c.JSON(http.StatusOK, model.ClassroomJobListResponse{
+		Error: false,
+		Jobs:  jobList,
+	})
+}
@@ -0,0 +1,367 @@
+package beta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/gomodule/redigo/redis"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/registry"
+	"github.com/nitishm/go-rejson/v4"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	defaultCatalogTTL             = 30 // minutes
+	defaultCatalogRefreshInterval = 10 // minutes
+	defaultCatalogConcurrency     = 4
+)
+
+// catalogEntry is images of a repository cached in redis.
+type catalogEntry struct {
+	Images    []registry.Image `json:"images"`
+	FetchedAt time.Time        `json:"fetchedAt"`
+}
+
+// catalogRepo is a repository in a registry.
+type catalogRepo struct {
+	reg  registry.Registry
+	repo string
+}
+
+func (r catalogRepo) key() string {
+	return fmt.Sprintf("image-catalog:%s:%s", r.reg.Name(), r.repo)
+}
+
+// userRepo is a user repository seen in listing, which expires if it is not listed again within TTL.
+type userRepo struct {
+	catalogRepo
+	listedAt time.Time
+}
+
+// catalogFetch is a fetch in progress, result is set before done is closed.
+type catalogFetch struct {
+	done  chan struct{}
+	entry *catalogEntry
+	err   error
+}
+
+// ImageCatalog caches images of registry repositories in redis, so listing images does not wait for registries.
+// Cached repositories are refreshed in background. Entry older than TTL is still served but refreshed right away,
+// and entry is kept when registry is unreachable, so stale images are served rather than nothing.
+type ImageCatalog struct {
+	redis      *rejson.Handler
+	registries []registry.Registry
+	config     *config.Config
+
+	mu sync.Mutex
+	// user repositories seen in listing, which are refreshed in background until they expire
+	userRepos map[string]userRepo
+	// keys being fetched, so a repository is never fetched twice at a time
+	fetching map[string]*catalogFetch
+}
+
+func NewImageCatalog(rh *rejson.Handler, registries []registry.Registry, config *config.Config) *ImageCatalog {
+	return &ImageCatalog{
+		redis:      rh,
+		registries: registries,
+		config:     config,
+		userRepos:  map[string]userRepo{},
+		fetching:   map[string]*catalogFetch{},
+	}
+}
+
+// Run refreshes all known repositories every refresh interval until stopCh is closed.
+func (ic *ImageCatalog) Run(stopCh <-chan struct{}) {
+	log.Info("Image catalog refresher is started")
+	wait.Until(func() {
+		ic.expireUserRepos()
+		ic.refresh(ic.repositories(nil))
+	}, ic.refreshInterval(), stopCh)
+	log.Info("Image catalog refresher is stopped")
+}
+
+// @Summary Refresh image catalog
+// @Description Fetch images of repositories from registries and update cache right away. All repositories listed so far are refreshed
+// @Description if registry and repository are not given. Cache is kept for repositories failed to be fetched.
+// @Tags Image
+// @Accept  json
+// @Produce  json
+// @Param registry query string false "name of registry to refresh"
+// @Param repository query string false "repository to refresh"
+// @Success 200 {object} docs.ImageCatalogRefreshResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/refresh [post]
+func (ic *ImageCatalog) Refresh(c *gin.Context) {
+	regName, repoName := c.Query("registry"), c.Query("repository")
+
+	repos := ic.repositories(func(r catalogRepo) bool {
+		return (regName == "" || r.reg.Name() == regName) && (repoName == "" || r.repo == repoName)
+	})
+
+	c.JSON(http.StatusOK, model.ImageCatalogRefreshResponse{
+		Error:        false,
+		Repositories: ic.refresh(repos),
+	})
+}
+
+// List returns images of configured repositories and repository of user, in order of configuration.
+func (ic *ImageCatalog) List(userRepoName string) ([]registry.Image, error) {
+	repos := []catalogRepo{}
+	for _, r := range ic.registries {
+		for _, repo := range r.Repositories() {
+			repos = append(repos, catalogRepo{reg: r, repo: repo})
+		}
+		if userRepoName != "" && r.UserRepository() {
+			repos = append(repos, catalogRepo{reg: r, repo: userRepoName})
+		}
+	}
+
+	results := make([][]registry.Image, len(repos))
+	errs := make([]error, len(repos))
+	ic.forEach(repos, func(idx int, r catalogRepo) {
+		results[idx], errs[idx] = ic.get(r)
+	})
+
+	result := []registry.Image{}
+	seen := map[string]bool{}
+	for idx, r := range repos {
+		if errs[idx] != nil {
+			return nil, fmt.Errorf("list repository {%s} of registry {%s} fail: %s", r.repo, r.reg.Name(), errs[idx].Error())
+		}
+		for _, image := range results[idx] {
+			if !seen[image.Ref()] {
+				seen[image.Ref()] = true
+				result = append(result, image)
+			}
+		}
+	}
+
+	if userRepoName != "" {
+		now := time.Now()
+		ic.mu.Lock()
+		for _, r := range repos {
+			if r.repo == userRepoName && r.reg.UserRepository() {
+				ic.userRepos[r.key()] = userRepo{catalogRepo: r, listedAt: now}
+			}
+		}
+		ic.mu.Unlock()
+	}
+	return result, nil
+}
+
+// get returns cached images of repository. Repository is fetched if it is not cached, and refreshed
+// in background if cache is older than TTL. Cache is skipped without redis.
+func (ic *ImageCatalog) get(r catalogRepo) ([]registry.Image, error) {
+	if ic.redis == nil {
+		return r.reg.ListImages(r.repo)
+	}
+
+	entry, err := ic.load(r)
+	if err != nil {
+		// not cached yet, or redis is unreachable
+		entry, err = ic.fetch(r)
+		if err != nil {
+			return nil, err
+		}
+		return entry.Images, nil
+	}
+
+	if time.Since(entry.FetchedAt) > ic.ttl() {
+		go ic.fetch(r)
+	}
+	return entry.Images, nil
+}
+
+// load returns cache of repository, error is returned if it is not cached.
+func (ic *ImageCatalog) load(r catalogRepo) (*catalogEntry, error) {
+	data, err := redis.Bytes(ic.redis.JSONGet(r.key(), "."))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := catalogEntry{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// fetch lists images of repository from registry and updates cache. Cache is not updated if list fails.
+// Caller waits for fetch of the same repository in progress and shares its result, including error.
+func (ic *ImageCatalog) fetch(r catalogRepo) (*catalogEntry, error) {
+	if ic.redis == nil {
+		images, err := r.reg.ListImages(r.repo)
+		if err != nil {
+			return nil, err
+		}
+		return &catalogEntry{Images: images, FetchedAt: time.Now()}, nil
+	}
+
+	key := r.key()
+	ic.mu.Lock()
+	if f, ok := ic.fetching[key]; ok {
+		ic.mu.Unlock()
+		<-f.done
+		return f.entry, f.err
+	}
+	f := &catalogFetch{done: make(chan struct{})}
+	ic.fetching[key] = f
+	ic.mu.Unlock()
+
+	defer func() {
+		ic.mu.Lock()
+		delete(ic.fetching, key)
+		ic.mu.Unlock()
+		close(f.done)
+	}()
+
+	images, err := r.reg.ListImages(r.repo)
+	if err != nil {
+		log.Warningf("Fetch images of repository {%s} of registry {%s} fail: %s", r.repo, r.reg.Name(), err.Error())
+		f.err = err
+		return nil, err
+	}
+
+	f.entry = &catalogEntry{
+		Images:    images,
+		FetchedAt: time.Now(),
+	}
+	if _, err := ic.redis.JSONSet(key, ".", f.entry); err != nil {
+		log.Warningf("Save image catalog {%s} to redis fail: %s", key, err.Error())
+	}
+	return f.entry, nil
+}
+
+// expireUserRepos forgets user repositories not listed within TTL and drops their cache,
+// so repositories of users who no longer list images are not refreshed forever.
+func (ic *ImageCatalog) expireUserRepos() {
+	expired := []string{}
+	ic.mu.Lock()
+	for key, r := range ic.userRepos {
+		if time.Since(r.listedAt) > ic.ttl() {
+			delete(ic.userRepos, key)
+			expired = append(expired, key)
+		}
+	}
+	ic.mu.Unlock()
+
+	if ic.redis == nil {
+		return
+	}
+	// cache of configured repository is kept, even if it is also listed as user repository
+	configured := map[string]bool{}
+	for _, r := range ic.registries {
+		for _, repo := range r.Repositories() {
+			configured[catalogRepo{reg: r, repo: repo}.key()] = true
+		}
+	}
+	for _, key := range expired {
+		if configured[key] {
+			continue
+		}
+		if _, err := ic.redis.JSONDel(key, "."); err != nil {
+			log.Warningf("Delete expired image catalog {%s} from redis fail: %s", key, err.Error())
+		}
+	}
+}
+
+// repositories returns configured repositories and user repositories seen so far, which are selected by filter if given.
+func (ic *ImageCatalog) repositories(filter func(catalogRepo) bool) []catalogRepo {
+	repos := []catalogRepo{}
+	for _, r := range ic.registries {
+		for _, repo := range r.Repositories() {
+			repos = append(repos, catalogRepo{reg: r, repo: repo})
+		}
+	}
+	ic.mu.Lock()
+	for _, r := range ic.userRepos {
+		repos = append(repos, r.catalogRepo)
+	}
+	ic.mu.Unlock()
+
+	if filter == nil {
+		return repos
+	}
+	selected := []catalogRepo{}
+	for _, r := range repos {
+		if filter(r) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// refresh fetches repositories with bounded concurrency and reports result of each.
+func (ic *ImageCatalog) refresh(repos []catalogRepo) []model.CatalogRepository {
+	results := make([]model.CatalogRepository, len(repos))
+	ic.forEach(repos, func(idx int, r catalogRepo) {
+		results[idx] = model.CatalogRepository{
+			Registry:   r.reg.Name(),
+			Repository: r.repo,
+		}
+
+		entry, err := ic.fetch(r)
+		if err != nil {
+			results[idx].Error = err.Error()
+			// report age of stale cache which is still served
+			if ic.redis != nil {
+				entry, _ = ic.load(r)
+			}
+		}
+		if entry != nil {
+			fetchedAt := entry.FetchedAt
+			results[idx].Images = len(entry.Images)
+			results[idx].FetchedAt = &fetchedAt
+		}
+	})
+	return results
+}
+
+// forEach calls f for each repository, at most concurrency calls at a time, and waits all calls are returned.
+func (ic *ImageCatalog) forEach(repos []catalogRepo, f func(int, catalogRepo)) {
+	sem := make(chan struct{}, ic.concurrency())
+	var wg sync.WaitGroup
+	for idx, r := range repos {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int, r catalogRepo) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(idx, r)
+		}(idx, r)
+	}
+	wg.Wait()
+}
+
+func (ic *ImageCatalog) ttl() time.Duration {
+	if ic.config == nil || ic.config.APIConfig.Catalog.TTL <= 0 {
+		return defaultCatalogTTL * time.Minute
+	}
+	return time.Duration(ic.config.APIConfig.Catalog.TTL) * time.Minute
+}
+
+func (ic *ImageCatalog) refreshInterval() time.Duration {
+	if ic.config == nil || ic.config.APIConfig.Catalog.RefreshInterval <= 0 {
+		return defaultCatalogRefreshInterval * time.Minute
+	}
+	return time.Duration(ic.config.APIConfig.Catalog.RefreshInterval) * time.Minute
+}
+
+func (ic *ImageCatalog) concurrency() int {
+	if ic.config == nil || ic.config.APIConfig.Catalog.Concurrency <= 0 {
+		return defaultCatalogConcurrency
+	}
+	return ic.config.APIConfig.Catalog.Concurrency
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -45,7 +46,7 @@ type Classroom struct {
 // @Success 200 {object} docs.UploadUserResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/upload [post]
@@ -101,7 +102,7 @@ func (cm *Classroom) UploadUserAccount(c *gin.Context) {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/create [post]
@@ -293,7 +294,7 @@ func (cm *Classroom) Add(c *gin.Context) {
 // @Success 200 {object} docs.GetClassroomResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/get/{id} [get]
@@ -381,7 +382,7 @@ func (cm *Classroom) Get(c *gin.Context) {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/update [put]
@@ -401,6 +402,10 @@ func (cm *Classroom) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkClassroomTeacher(c, cm.DB, req.ID) {
+		return
+	}
+
 	//use transaction avoid partial update
 	tx := cm.DB.Begin()
 
@@ -413,6 +418,9 @@ func (cm *Classroom) Update(c *gin.Context) {
 			SelectedType:        req.ScheduleTime.SelectedType,
 			StartAt:             req.ScheduleTime.StartDate,
 			EndAt:               req.ScheduleTime.EndDate,
+			MaxRuntime:          req.MaxRuntime,
+			IdleTimeout:         req.IdleTimeout,
+			MaxExtension:        req.MaxExtension,
 		}).Error; err != nil {
 		tx.Rollback()
 		errStr := fmt.Sprintf("update classroom {%s} fail: %s", req.ID, err.Error())
@@ -530,7 +538,7 @@ func (cm *Classroom) Update(c *gin.Context) {
 // @Success 200 {object} docs.SimpleListClassroomResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/list [get]
@@ -586,7 +594,7 @@ func (cm *Classroom) ListAll(c *gin.Context) {
 // @Success 200 {object} docs.ListClassroomResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/list [post]
@@ -605,6 +613,7 @@ func (cm *Classroom) List(c *gin.Context) {
 		return
 	}
 	req.Provider = provider.(string)
+	req.User = callerName(c, req.User)
 
 	if req.User == "" {
 		log.Errorf("Empty user name")
@@ -666,7 +675,7 @@ func (cm *Classroom) List(c *gin.Context) {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/classroom/delete/{id} [delete]
@@ -686,6 +695,10 @@ func (cm *Classroom) Delete(c *gin.Context) {
 		return
 	}
 
+	if !checkClassroomTeacher(c, cm.DB, classroomID) {
+		return
+	}
+
 	classroom := db.ClassRoomInfo{
 		Model: db.Model{
 			ID: classroomID,
@@ -873,38 +886,51 @@ func (cm *Classroom) RemoveDataSetPVC(namespace string) error {
 }
 
 func (cm *Classroom) copySecretFromSystem(namespace string) error {
+	return copySystemSecret(cm.KClientSet, namespace, consts.TlsSecretName)
+}
+
+// copySystemSecret copies secret from aitrain-system to namespace.
+func copySystemSecret(kclient kubernetes.Interface, namespace, name string) error {
 	// get from aitrain-system
-	origSec, err := cm.KClientSet.CoreV1().Secrets(consts.AiTrainSystemNamespace).Get(
-		context.Background(), consts.TlsSecretName, metav1.GetOptions{})
+	origSec, err := kclient.CoreV1().Secrets(consts.AiTrainSystemNamespace).Get(
+		context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
+	return copySecret(kclient, namespace, origSec)
+}
 
-	_, err = cm.KClientSet.CoreV1().Secrets(namespace).Get(context.Background(), consts.TlsSecretName, metav1.GetOptions{})
+// copySecret creates copy of origSec in namespace, or updates data of existing copy, so rotated secret is refreshed.
+func copySecret(kclient kubernetes.Interface, namespace string, origSec *corev1.Secret) error {
+	data := make(map[string][]byte)
+	for k, v := range origSec.Data {
+		data[k] = v
+	}
+
+	sec, err := kclient.CoreV1().Secrets(namespace).Get(context.Background(), origSec.Name, metav1.GetOptions{})
 	if err != nil && errors.IsNotFound(err) {
 		// if secret is not found, create one
 		newSec := &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: namespace,
-				Name:      consts.TlsSecretName,
+				Name:      origSec.Name,
 			},
-			Data: make(map[string][]byte),
-			Type: corev1.SecretTypeTLS,
-		}
-
-		for k, v := range origSec.Data {
-			newSec.Data[k] = v
+			Data: data,
+			Type: origSec.Type,
 		}
 
-		_, err := cm.KClientSet.CoreV1().Secrets(namespace).Create(context.Background(), newSec, metav1.CreateOptions{})
-		if err != nil {
-			return err
-		}
-	} else {
+		_, err := kclient.CoreV1().Secrets(namespace).Create(context.Background(), newSec, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
 		return err
 	}
 
-	return nil
+	if reflect.DeepEqual(sec.Data, data) {
+		return nil
+	}
+	sec.Data = data
+	_, err = kclient.CoreV1().Secrets(namespace).Update(context.Background(), sec, metav1.UpdateOptions{})
+	return err
 }
 
 func (cm *Classroom) updateCourseCRD(req db.ClassRoomInfo) error {
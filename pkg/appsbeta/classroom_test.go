@@ -3,6 +3,7 @@ package beta
 import (
 	"context"
 	"fmt"
+	"os"
 	"testing"
 
 	uuid2 "github.com/google/uuid"
@@ -27,8 +28,9 @@ func TestMain(m *testing.M) {
 	config, err := clientcmd.BuildConfigFromFlags("", "../../conf/minikube-kubeconfig")
 
 	if err != nil {
-		fmt.Println("kubeconfig not found, exit...")
-		return
+		// unit tests still run, tests need kubernetes or docker hub are skipped, see requireTestEnv
+		fmt.Println("kubeconfig not found, skip tests need test environment...")
+		os.Exit(m.Run())
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
@@ -55,7 +57,16 @@ func TestMain(m *testing.M) {
 	clientset.CoreV1().Namespaces().Delete(context.Background(), newNS, metav1.DeleteOptions{})
 }
 
+// requireTestEnv skips test which needs kubernetes cluster of kubeconfig or access to docker hub.
+func requireTestEnv(t *testing.T) {
+	t.Helper()
+	if cm.KClientSet == nil {
+		t.Skip("kubeconfig not found")
+	}
+}
+
 func TestClassroom_createSecret(t *testing.T) {
+	requireTestEnv(t)
 
 	// Test1: both aitrain-system ns & aitrain-system/secret are not exist
 	err := cm.copySecretFromSystem(newNS)
@@ -121,6 +132,7 @@ func TestClassroom_createSecret(t *testing.T) {
 }
 
 func TestClassroom_updateCourseCRD(t *testing.T) {
+	requireTestEnv(t)
 
 	c1, _ := cm.CourseCrdClient.NchcV1alpha1().Courses(newNS).Create(
 		context.Background(),
@@ -6,6 +6,7 @@ import (
 	"github.com/jinzhu/gorm"
 	"github.com/nchc-ai/backend-api/pkg/apps"
 	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/registry"
 	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
 	"github.com/nchc-ai/oauth-provider/pkg/provider"
 	"github.com/nitishm/go-rejson/v4"
@@ -13,14 +14,27 @@ import (
 )
 
 type BetaClient struct {
-	classroom apps.ClassroomInterface
-	course    apps.CourseInterface
-	dataset   apps.DatasetInterface
-	health    apps.HealthInterface
-	image     apps.ImageInterface
-	job       apps.JobInterface
-	proxy     apps.ProxyInterface
-	user      apps.UserInterface
+	classroom   apps.ClassroomInterface
+	course      apps.CourseInterface
+	dataset     apps.DatasetInterface
+	health      apps.HealthInterface
+	image       apps.ImageInterface
+	imagePolicy apps.ImagePolicyInterface
+	job         apps.JobInterface
+	proxy       apps.ProxyInterface
+	quota       apps.QuotaInterface
+	reservation apps.ReservationInterface
+	user        apps.UserInterface
+	webhook     apps.WebhookInterface
+
+	jobStatusController *JobStatusController
+	jobReaper           *JobReaper
+	reconciler          *Reconciler
+	launchQueue         *LaunchQueue
+	jobScheduler        *JobScheduler
+	workspace           *Workspace
+	imageCommitter      *ImageCommitter
+	imageCatalog        *ImageCatalog
 }
 
 func NewClient(kclient *kubernetes.Clientset, crdclient *versioned.Clientset,
@@ -34,6 +48,27 @@ func NewClient(kclient *kubernetes.Clientset, crdclient *versioned.Clientset,
 		rfstackbase = nil
 	}
 
+	jobStatusController := NewJobStatusController(db, rh, kclient, crdclient)
+
+	job := &Job{
+		KClientSet:      kclient,
+		DB:              db,
+		redis:           rh,
+		CourseCrdClient: crdclient,
+		config:          config,
+		rfStackBase:     rfstackbase,
+		statusCtrl:      jobStatusController,
+	}
+	job.queue = NewLaunchQueue(db, kclient, config, job)
+	imageCommitter := NewImageCommitter(db, kclient, config)
+
+	registries, err := registry.NewAll(config.Registries)
+	if err != nil {
+		log.Errorf("Invalid registries configuration, use default registry: %s", err.Error())
+		registries, _ = registry.NewAll(nil)
+	}
+	imageCatalog := NewImageCatalog(rh, registries, config)
+
 	return &BetaClient{
 		classroom: &Classroom{
 			DB:              db,
@@ -46,7 +81,9 @@ func NewClient(kclient *kubernetes.Clientset, crdclient *versioned.Clientset,
 			DB:              db,
 			Redis:           rh,
 			CourseCrdClient: crdclient,
+			KClientSet:      kclient,
 			rfStackBase:     rfstackbase,
+			config:          config,
 		},
 
 		dataset: &Dataset{
@@ -60,28 +97,55 @@ func NewClient(kclient *kubernetes.Clientset, crdclient *versioned.Clientset,
 		},
 
 		image: &Image{
-			provider: provider,
-			db:       db,
+			provider:  provider,
+			db:        db,
+			job:       job,
+			committer: imageCommitter,
+			catalog:   imageCatalog,
 		},
 
-		job: &Job{
-			DB:              db,
-			redis:           rh,
-			CourseCrdClient: crdclient,
-			config:          config,
-			rfStackBase:     rfstackbase,
-			StopChanMap:     make(map[string]chan string),
+		imagePolicy: &ImagePolicy{
+			db: db,
 		},
 
+		job: job,
+
 		proxy: &Proxy{
 			provider: provider,
 			db:       db,
 			config:   config,
 		},
 
+		quota: &Quota{
+			db: db,
+		},
+
+		reservation: &Reservation{
+			DB:         db,
+			KClientSet: kclient,
+			config:     config,
+		},
+
 		user: &User{
 			db: db,
 		},
+
+		webhook: &Webhook{
+			CourseCrdClient: crdclient,
+		},
+
+		jobStatusController: jobStatusController,
+		jobReaper:           NewJobReaper(db, rh, crdclient, job, jobStatusController.events),
+		reconciler:          NewReconciler(db, rh, kclient, crdclient, config, jobStatusController.events),
+		launchQueue:         job.queue,
+		jobScheduler:        NewJobScheduler(db, job),
+		workspace: &Workspace{
+			DB:         db,
+			KClientSet: kclient,
+			config:     config,
+		},
+		imageCommitter: imageCommitter,
+		imageCatalog:   imageCatalog,
 	}
 }
 
@@ -113,6 +177,66 @@ func (c *BetaClient) Proxy() apps.ProxyInterface {
 	return c.proxy
 }
 
+func (c *BetaClient) Quota() apps.QuotaInterface {
+	return c.quota
+}
+
+func (c *BetaClient) Reservation() apps.ReservationInterface {
+	return c.reservation
+}
+
 func (c *BetaClient) User() apps.UserInterface {
 	return c.user
 }
+
+func (c *BetaClient) Webhook() apps.WebhookInterface {
+	return c.webhook
+}
+
+func (c *BetaClient) JobStatusController() *JobStatusController {
+	return c.jobStatusController
+}
+
+func (c *BetaClient) JobReaper() *JobReaper {
+	return c.jobReaper
+}
+
+func (c *BetaClient) Reconcile() apps.ReconcileInterface {
+	return c.reconciler
+}
+
+func (c *BetaClient) Reconciler() *Reconciler {
+	return c.reconciler
+}
+
+func (c *BetaClient) LaunchQueue() *LaunchQueue {
+	return c.launchQueue
+}
+
+func (c *BetaClient) Schedule() apps.ScheduleInterface {
+	return c.jobScheduler
+}
+
+func (c *BetaClient) JobScheduler() *JobScheduler {
+	return c.jobScheduler
+}
+
+func (c *BetaClient) Workspace() apps.WorkspaceInterface {
+	return c.workspace
+}
+
+func (c *BetaClient) ImageCommitter() *ImageCommitter {
+	return c.imageCommitter
+}
+
+func (c *BetaClient) ImagePolicy() apps.ImagePolicyInterface {
+	return c.imagePolicy
+}
+
+func (c *BetaClient) Catalog() apps.ImageCatalogInterface {
+	return c.imageCatalog
+}
+
+func (c *BetaClient) ImageCatalog() *ImageCatalog {
+	return c.imageCatalog
+}
@@ -0,0 +1,173 @@
+package beta
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
+	"github.com/nchc-ai/course-crd/pkg/client/informers/externalversions"
+	listers "github.com/nchc-ai/course-crd/pkg/client/listers/coursecontroller/v1alpha1"
+	"github.com/nitishm/go-rejson/v4"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const jobStatusResync = 10 * time.Minute
+
+// JobStatusController watches Course CRDs in all classroom namespaces with a shared informer,
+// and keeps status of corresponding container job in database in sync with CRD status.
+// Redis cache of job owner is invalidated and watchers of job owner are notified whenever job status is changed.
+// Writable volume of job is labelled as workspace of job owner, see labelWorkspace.
+type JobStatusController struct {
+	DB         *gorm.DB
+	KClientSet *kubernetes.Clientset
+	redis      *rejson.Handler
+	factory    externalversions.SharedInformerFactory
+	lister     listers.CourseLister
+	synced     cache.InformerSynced
+	queue      workqueue.RateLimitingInterface
+	events     *jobEventHub
+}
+
+func NewJobStatusController(DB *gorm.DB, redis *rejson.Handler, kclient *kubernetes.Clientset,
+	crdClient *versioned.Clientset) *JobStatusController {
+	factory := externalversions.NewSharedInformerFactory(crdClient, jobStatusResync)
+	informer := factory.Nchc().V1alpha1().Courses()
+
+	ctrl := &JobStatusController{
+		DB:         DB,
+		KClientSet: kclient,
+		redis:      redis,
+		factory:    factory,
+		lister:     informer.Lister(),
+		synced:     informer.Informer().HasSynced,
+		queue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "JobStatus"),
+		events:     newJobEventHub(),
+	}
+
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: ctrl.enqueue,
+		UpdateFunc: func(old, new interface{}) {
+			ctrl.enqueue(new)
+		},
+	})
+
+	return ctrl
+}
+
+// Run starts informer and a single worker, and blocks until stopCh is closed.
+func (ctrl *JobStatusController) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer ctrl.queue.ShutDown()
+
+	ctrl.factory.Start(stopCh)
+
+	log.Info("Wait for Course CRD informer cache synced")
+	if !cache.WaitForCacheSync(stopCh, ctrl.synced) {
+		log.Error("Course CRD informer cache fail to sync")
+		return
+	}
+
+	go wait.Until(ctrl.runWorker, time.Second, stopCh)
+
+	log.Info("Job status controller is started")
+	<-stopCh
+	log.Info("Job status controller is stopped")
+}
+
+func (ctrl *JobStatusController) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	ctrl.queue.Add(key)
+}
+
+// Enqueue asks controller to sync status of job with Course CRD in namespace ns.
+func (ctrl *JobStatusController) Enqueue(ns, name string) {
+	ctrl.queue.Add(fmt.Sprintf("%s/%s", ns, name))
+}
+
+func (ctrl *JobStatusController) runWorker() {
+	for ctrl.processNextItem() {
+	}
+}
+
+func (ctrl *JobStatusController) processNextItem() bool {
+	key, quit := ctrl.queue.Get()
+	if quit {
+		return false
+	}
+	defer ctrl.queue.Done(key)
+
+	if err := ctrl.sync(key.(string)); err != nil {
+		log.Warningf("sync job status of Course CRD {%s} fail, retry later: %s", key, err.Error())
+		ctrl.queue.AddRateLimited(key)
+		return true
+	}
+
+	ctrl.queue.Forget(key)
+	return true
+}
+
+func (ctrl *JobStatusController) sync(key string) error {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil
+	}
+
+	course, err := ctrl.lister.Courses(ns).Get(name)
+	if errors.IsNotFound(err) {
+		// CRD is deleted, job record is removed by whom delete it.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	job := db.Job{
+		Model: db.Model{
+			ID: course.Name,
+		},
+	}
+	if result := ctrl.DB.First(&job); result.Error != nil {
+		if result.RecordNotFound() {
+			// CRD is not created by api server, or job is already deleted
+			return nil
+		}
+		return result.Error
+	}
+
+	if status := crdJobStatus(course); job.Status != status {
+		if err := ctrl.DB.Model(&job).Update("status", status).Error; err != nil {
+			return fmt.Errorf("update job {%s} status to %s fail: %s", job.ID, status, err.Error())
+		}
+		job.Status = status
+		ctrl.events.publish(jobOwnerKey(job.User, job.Provider), jobEventStatus, toJobStatus(job))
+
+		redisKey := jobOwnerKey(job.User, job.Provider)
+		if _, err := ctrl.redis.JSONDel(redisKey, "."); err != nil {
+			log.Errorf("Delete cache key {%s} fail for update job status to %s: %s", redisKey, status, err.Error())
+		}
+
+		log.Infof("job {%s} status is changed to %s", job.ID, status)
+	}
+
+	return labelWorkspace(ctrl.KClientSet, course, &job)
+}
+
+func crdJobStatus(course *v1alpha1.Course) string {
+	if course.Status.Accessible {
+		return JobStatueReady
+	}
+	return JobStatusPending
+}
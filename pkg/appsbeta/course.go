@@ -14,18 +14,23 @@ import (
 	"github.com/nchc-ai/backend-api/pkg/consts"
 	"github.com/nchc-ai/backend-api/pkg/model"
 	"github.com/nchc-ai/backend-api/pkg/model/common"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
 	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/backend-api/pkg/registry"
 	"github.com/nchc-ai/backend-api/pkg/util"
 	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
 	rfstackmodel "github.com/nchc-ai/rfstack/model"
 	"github.com/nitishm/go-rejson/v4"
+	"k8s.io/client-go/kubernetes"
 )
 
 type Course struct {
 	DB              *gorm.DB
 	Redis           *rejson.Handler
 	CourseCrdClient *versioned.Clientset
+	KClientSet      *kubernetes.Clientset
 	rfStackBase     *sling.Sling
+	config          *config.Config
 }
 
 // @Summary Add new course information
@@ -37,7 +42,7 @@ type Course struct {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/course/create [post]
@@ -49,6 +54,7 @@ func (co *Course) Add(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
 		return
 	}
+	req.User = callerName(c, req.User)
 
 	if req.User == "" {
 		log.Errorf("user field in request cannot be empty")
@@ -63,6 +69,34 @@ func (co *Course) Add(c *gin.Context) {
 		return
 	}
 
+	if err := req.ResourceProfile.Validate(co.config.APIConfig.Resource); err != nil {
+		log.Errorf("invalid resource profile of course {%s}: %s", req.Name, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_CREATE_RESOURCE_FMT, req.Name, err.Error())
+		return
+	}
+
+	// image can be pinned by digest, i.e. name@sha256:..., instead of mutable tag
+	if _, err := registry.ParseReference(req.ImageLV.Value); err != nil {
+		log.Errorf("invalid image of course {%s}: %s", req.Name, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_CREATE_IMAGE_FMT, req.Name, err.Error())
+		return
+	}
+	if err := db.CheckImageAllowed(co.DB, req.ImageLV.Value); err != nil {
+		log.Errorf("image of course {%s} is not allowed: %s", req.Name, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_CREATE_IMAGE_DENY_FMT, req.Name, err.Error())
+		return
+	}
+
+	envs := []db.EnvVar{}
+	if req.Envs != nil {
+		envs = db.UnmaskSecretEnvs(*req.Envs)
+	}
+	if err := checkCourseEnvs(co.KClientSet, envs); err != nil {
+		log.Errorf("invalid environment variables of course {%s}: %s", req.Name, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_CREATE_ENV_INVALID_FMT, req.Name, err.Error())
+		return
+	}
+
 	// add course information in DB
 	courseID := uuid.New().String()
 
@@ -96,13 +130,16 @@ func (co *Course) Add(c *gin.Context) {
 			User:     req.User,
 			Provider: provider.(string),
 		},
-		Introduction: req.Introduction,
-		Name:         req.Name,
-		Image:        req.ImageLV.Value,
-		Level:        req.Level,
-		Gpu:          req.GpuLV.Value,
-		WritablePath: req.WritablePath,
-		AccessType:   req.AccessType,
+		Introduction:    req.Introduction,
+		Name:            req.Name,
+		Image:           req.ImageLV.Value,
+		Level:           req.Level,
+		Gpu:             req.GpuLV.Value,
+		WritablePath:    req.WritablePath,
+		AccessType:      req.AccessType,
+		MaxRuntime:      req.MaxRuntime,
+		IdleTimeout:     req.IdleTimeout,
+		ResourceProfile: req.ResourceProfile,
 	}
 
 	err = tx.Create(&newCourse).Error
@@ -165,6 +202,17 @@ func (co *Course) Add(c *gin.Context) {
 		}
 	}
 
+	// add course environment variables in DB
+	for _, env := range envs {
+		env.CourseID = courseID
+		if err = tx.Create(&env).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("Failed to create course-env information in DB: %s", err.Error())
+			RespondWithError(c, http.StatusInternalServerError, consts.ERROR_COURSE_CREATE_ENV_FMT, req.Name)
+			return
+		}
+	}
+
 	tx.Commit()
 	RespondWithOk(c, "Course %s created successfully", req.Name)
 }
@@ -178,7 +226,7 @@ func (co *Course) Add(c *gin.Context) {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/course/update [put]
@@ -205,6 +253,29 @@ func (co *Course) Update(c *gin.Context) {
 		return
 	}
 
+	if err := req.ResourceProfile.Validate(co.config.APIConfig.Resource); err != nil {
+		log.Errorf("invalid resource profile of course {%s}: %s", req.ID, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_UPDATE_RESOURCE_FMT, req.Name, err.Error())
+		return
+	}
+
+	if _, err := registry.ParseReference(req.ImageLV.Value); err != nil {
+		log.Errorf("invalid image of course {%s}: %s", req.ID, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_UPDATE_IMAGE_FMT, req.Name, err.Error())
+		return
+	}
+
+	// environment variables are kept if not given, for client not aware of them
+	if req.Envs != nil {
+		envs := db.UnmaskSecretEnvs(*req.Envs)
+		if err := checkCourseEnvs(co.KClientSet, envs); err != nil {
+			log.Errorf("invalid environment variables of course {%s}: %s", req.ID, err.Error())
+			RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_UPDATE_ENV_INVALID_FMT, req.Name, err.Error())
+			return
+		}
+		req.Envs = &envs
+	}
+
 	findCourse := db.Course{
 		Model: db.Model{
 			ID: req.ID,
@@ -218,18 +289,36 @@ func (co *Course) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkCourseOwner(c, co.DB, &findCourse) {
+		return
+	}
+
+	// image must be allowed by classrooms which course is added to as well
+	classrooms, err := db.CourseClassrooms(co.DB, req.ID)
+	if err == nil {
+		err = db.CheckImageAllowed(co.DB, req.ImageLV.Value, classrooms...)
+	}
+	if err != nil {
+		log.Errorf("image of course {%s} is not allowed: %s", req.ID, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_COURSE_UPDATE_IMAGE_DENY_FMT, req.Name, err.Error())
+		return
+	}
+
 	tx := co.DB.Begin()
 
 	// update Course DB
 	if err := tx.Model(&findCourse).Updates(
 		db.Course{
-			Introduction: req.Introduction,
-			Name:         req.Name,
-			Image:        req.ImageLV.Value,
-			Level:        req.Level,
-			Gpu:          req.GpuLV.Value,
-			AccessType:   req.AccessType,
-			WritablePath: req.WritablePath,
+			Introduction:    req.Introduction,
+			Name:            req.Name,
+			Image:           req.ImageLV.Value,
+			Level:           req.Level,
+			Gpu:             req.GpuLV.Value,
+			AccessType:      req.AccessType,
+			WritablePath:    req.WritablePath,
+			MaxRuntime:      req.MaxRuntime,
+			IdleTimeout:     req.IdleTimeout,
+			ResourceProfile: req.ResourceProfile,
 		}).Error; err != nil {
 		tx.Rollback()
 		errStr := fmt.Sprintf("update course {%s} information fail: %s", req.ID, err.Error())
@@ -300,6 +389,27 @@ func (co *Course) Update(c *gin.Context) {
 		}
 	}
 
+	// update environment variables of course
+	if req.Envs != nil {
+		// Step 1: delete environment variables of course
+		if err = tx.Where("course_id = ?", req.ID).Delete(db.EnvVar{}).Error; err != nil {
+			tx.Rollback()
+			log.Errorf("Failed to delete course {%s} env information in DB: %s", req.ID, err.Error())
+			RespondWithError(c, http.StatusInternalServerError, consts.ERROR_COURSE_UPDATE_ENV_FMT, req.Name)
+			return
+		}
+		// Step 2: create new environment variables
+		for _, env := range *req.Envs {
+			env.CourseID = req.ID
+			if err = tx.Create(&env).Error; err != nil {
+				tx.Rollback()
+				log.Errorf("Failed to create course-env information in DB: %s", err.Error())
+				RespondWithError(c, http.StatusInternalServerError, consts.ERROR_COURSE_UPDATE_ENV_FMT, req.Name)
+				return
+			}
+		}
+	}
+
 	tx.Commit()
 	RespondWithOk(c, "Course {%s} update successfully", req.ID)
 }
@@ -313,7 +423,7 @@ func (co *Course) Update(c *gin.Context) {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/course/delete/{id} [delete]
@@ -334,6 +444,10 @@ func (co *Course) Delete(c *gin.Context) {
 		return
 	}
 
+	if !checkCourseOwner(c, co.DB, course) {
+		return
+	}
+
 	jobs := []db.Job{}
 	// Step 1: Find all associated Deployment/Service
 	if err := co.DB.Model(course).Related(&jobs).Error; err != nil {
@@ -379,7 +493,7 @@ func (co *Course) Delete(c *gin.Context) {
 // @Success 200 {object} docs.GetCourseResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/course/get/{id} [get]
@@ -454,6 +568,16 @@ func (co *Course) Get(c *gin.Context) {
 	}
 	result.Ports = &portResult
 
+	// query env table, value of secret is not shown
+	envResult, err := result.GetEnv(co.DB)
+	if err != nil {
+		log.Errorf("Query course {%s} envs fail: %s", id, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Query course {%s} envs fail: %s", id, err.Error())
+		return
+	}
+	envs := db.MaskSecretEnvs(envResult)
+	result.Envs = &envs
+
 	c.JSON(http.StatusOK, model.GetCourseResponse{
 		Error:  false,
 		Course: result,
@@ -469,7 +593,7 @@ func (co *Course) Get(c *gin.Context) {
 // @Success 200 {object} docs.CourseTypeResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/course/type/{id} [get]
@@ -522,7 +646,7 @@ func (co *Course) CourseType(c *gin.Context) {
 // @Success 200 {object} docs.ListCourseResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/course/list [post]
@@ -539,6 +663,7 @@ func (co *Course) ListUserCourse(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
 		return
 	}
+	req.User = callerName(c, req.User)
 
 	if req.User == "" {
 		log.Errorf("Empty user name")
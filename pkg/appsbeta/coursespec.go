@@ -0,0 +1,168 @@
+package beta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// hasCourseAnnotations tells if Course CRD keeps any setting which is applied to deployment by Webhook.
+func hasCourseAnnotations(course *v1alpha1.Course) bool {
+	for _, key := range []string{consts.CourseAnnotationResources, consts.CourseAnnotationNodeSelector, consts.CourseAnnotationEnv} {
+		if course.Annotations[key] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPodAnnotations merges resources, node selector and environment variables in annotations into pod spec.
+// Resources not in annotation, eg: GPU set by course controller, are kept, and env of the same name is replaced.
+func applyPodAnnotations(spec *v1.PodSpec, annotations map[string]string) error {
+	if v := annotations[consts.CourseAnnotationResources]; v != "" {
+		requirements := v1.ResourceRequirements{}
+		if err := json.Unmarshal([]byte(v), &requirements); err != nil {
+			return fmt.Errorf("invalid %s annotation: %s", consts.CourseAnnotationResources, err.Error())
+		}
+		for i := range spec.Containers {
+			c := &spec.Containers[i]
+			c.Resources.Requests = mergeResourceList(c.Resources.Requests, requirements.Requests)
+			c.Resources.Limits = mergeResourceList(c.Resources.Limits, requirements.Limits)
+		}
+	}
+
+	if v := annotations[consts.CourseAnnotationNodeSelector]; v != "" {
+		selector := map[string]string{}
+		if err := json.Unmarshal([]byte(v), &selector); err != nil {
+			return fmt.Errorf("invalid %s annotation: %s", consts.CourseAnnotationNodeSelector, err.Error())
+		}
+		if spec.NodeSelector == nil && len(selector) > 0 {
+			spec.NodeSelector = map[string]string{}
+		}
+		for k, v := range selector {
+			spec.NodeSelector[k] = v
+		}
+	}
+
+	if v := annotations[consts.CourseAnnotationEnv]; v != "" {
+		envs := []v1.EnvVar{}
+		if err := json.Unmarshal([]byte(v), &envs); err != nil {
+			return fmt.Errorf("invalid %s annotation: %s", consts.CourseAnnotationEnv, err.Error())
+		}
+		for i := range spec.Containers {
+			spec.Containers[i].Env = mergeEnv(spec.Containers[i].Env, envs)
+		}
+	}
+	return nil
+}
+
+func mergeEnv(envs, from []v1.EnvVar) []v1.EnvVar {
+	for _, e := range from {
+		replaced := false
+		for i := range envs {
+			if envs[i].Name == e.Name {
+				envs[i] = e
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			envs = append(envs, e)
+		}
+	}
+	return envs
+}
+
+func mergeResourceList(list, from v1.ResourceList) v1.ResourceList {
+	if len(from) == 0 {
+		return list
+	}
+	if list == nil {
+		list = v1.ResourceList{}
+	}
+	for name, q := range from {
+		list[name] = q
+	}
+	return list
+}
+
+// labelWorkspace labels writable volume mounted at WritableVolume.MountPoint by deployment of Course CRD as workspace
+// of job owner, since course controller creates the volume without telling whom it belongs to.
+// Volume already labelled for another owner is left unchanged.
+func labelWorkspace(kclient kubernetes.Interface, course *v1alpha1.Course, job *db.Job) error {
+	if course.Spec.WritableVolume == nil || course.Spec.WritableVolume.MountPoint == "" {
+		return nil
+	}
+
+	deployments, err := courseDeployments(kclient, course)
+	if err != nil {
+		return fmt.Errorf("list deployments of Course CRD {%s} fail: %s", course.Name, err.Error())
+	}
+	if len(deployments) == 0 {
+		return fmt.Errorf("deployment of Course CRD {%s} is not created yet", course.Name)
+	}
+
+	for _, d := range deployments {
+		claim := mountedClaim(&d.Spec.Template.Spec, course.Spec.WritableVolume.MountPoint)
+		if claim == "" {
+			log.Warningf("writable volume of Course CRD {%s} is not mounted at %s by deployment {%s}",
+				course.Name, course.Spec.WritableVolume.MountPoint, d.Name)
+			continue
+		}
+
+		pvc, err := kclient.CoreV1().PersistentVolumeClaims(d.Namespace).Get(context.Background(), claim, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("get writable volume {%s/%s} fail: %s", d.Namespace, claim, err.Error())
+		}
+		owner, provider := workspaceOwner(pvc), workspaceProvider(pvc)
+		if _, ok := pvc.Labels[consts.WorkspaceLabel]; ok && owner == job.User && provider == job.Provider {
+			continue
+		}
+		if owner != "" && (owner != job.User || provider != job.Provider) {
+			log.Warningf("writable volume {%s/%s} of {%s/%s} is mounted by job {%s} of {%s/%s}, skip labelling",
+				pvc.Namespace, pvc.Name, provider, owner, job.ID, job.Provider, job.User)
+			continue
+		}
+
+		if pvc.Labels == nil {
+			pvc.Labels = map[string]string{}
+		}
+		if pvc.Annotations == nil {
+			pvc.Annotations = map[string]string{}
+		}
+		pvc.Labels[consts.WorkspaceLabel] = "true"
+		pvc.Annotations[consts.WorkspaceOwnerAnnotation] = job.User
+		pvc.Annotations[consts.WorkspaceProviderAnnotation] = job.Provider
+		if _, err := kclient.CoreV1().PersistentVolumeClaims(pvc.Namespace).
+			Update(context.Background(), pvc, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("label writable volume {%s/%s} fail: %s", pvc.Namespace, pvc.Name, err.Error())
+		}
+		log.Infof("writable volume {%s/%s} is labelled as workspace of {%s/%s}", pvc.Namespace, pvc.Name, job.Provider, job.User)
+	}
+	return nil
+}
+
+// mountedClaim returns name of persistent volume claim mounted at mountPath by any container of pod.
+func mountedClaim(spec *v1.PodSpec, mountPath string) string {
+	for _, c := range spec.Containers {
+		for _, m := range c.VolumeMounts {
+			if m.MountPath != mountPath {
+				continue
+			}
+			for _, vol := range spec.Volumes {
+				if vol.Name == m.Name && vol.PersistentVolumeClaim != nil {
+					return vol.PersistentVolumeClaim.ClaimName
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -30,7 +30,7 @@ type Dataset struct {
 // @Success 200 {object} docs.DatasetsListResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/datasets [get]
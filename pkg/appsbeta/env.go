@@ -0,0 +1,115 @@
+package beta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkCourseEnvs validates environment variables of course, referenced secret must be approved course secret
+// in aitrain-system and have referenced key.
+func checkCourseEnvs(kclient kubernetes.Interface, envs []db.EnvVar) error {
+	if err := db.ValidateEnvs(envs); err != nil {
+		return err
+	}
+
+	secrets := map[string]*v1.Secret{}
+	for _, e := range envs {
+		if !e.FromSecret() {
+			continue
+		}
+		name := *e.SecretName
+		if _, ok := secrets[name]; !ok {
+			sec, err := courseSecret(kclient, name)
+			if err != nil {
+				return err
+			}
+			secrets[name] = sec
+		}
+
+		if _, ok := secrets[name].Data[*e.SecretKey]; !ok {
+			return fmt.Errorf("key {%s} is not found in secret {%s}", *e.SecretKey, name)
+		}
+	}
+	return nil
+}
+
+// copyCourseSecrets copies secrets referenced by environment variables of course into classroom namespace.
+// Secret is checked again, since it may be no longer approved after course is saved.
+// Name of secret failed to copy is returned with error.
+func copyCourseSecrets(kclient kubernetes.Interface, namespace string, envs []db.EnvVar) (string, error) {
+	for _, name := range db.SecretNames(envs) {
+		sec, err := courseSecret(kclient, name)
+		if err != nil {
+			return name, err
+		}
+		if err := copySecret(kclient, namespace, sec); err != nil {
+			return name, err
+		}
+	}
+	return "", nil
+}
+
+// courseSecret returns secret of aitrain-system approved by admin for course, i.e. labelled with consts.CourseSecretLabel.
+// TLS secret of system is never exposed to container.
+func courseSecret(kclient kubernetes.Interface, name string) (*v1.Secret, error) {
+	if name == consts.TlsSecretName {
+		return nil, fmt.Errorf("secret {%s} can not be used in environment variable", name)
+	}
+
+	sec, err := kclient.CoreV1().Secrets(consts.AiTrainSystemNamespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("query secret {%s} fail: %s", name, err.Error())
+	}
+	if sec.Labels[consts.CourseSecretLabel] != "true" {
+		return nil, fmt.Errorf("secret {%s} is not approved for course, it must be labelled with %s=true",
+			name, consts.CourseSecretLabel)
+	}
+	return sec, nil
+}
+
+// setEnvAnnotation puts environment variables of course in Course CRD annotations,
+// which are applied to containers of course deployment by Webhook.
+func setEnvAnnotation(crdDef *v1alpha1.Course, envs []db.EnvVar) error {
+	if len(envs) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(containerEnvs(envs))
+	if err != nil {
+		return err
+	}
+	crdDef.Annotations[consts.CourseAnnotationEnv] = string(b)
+	return nil
+}
+
+// containerEnvs converts environment variables of course to container env, secret is read by SecretKeyRef.
+func containerEnvs(envs []db.EnvVar) []v1.EnvVar {
+	result := []v1.EnvVar{}
+	for _, e := range envs {
+		env := v1.EnvVar{
+			Name: e.Name,
+		}
+		if e.FromSecret() {
+			env.ValueFrom = &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: *e.SecretName,
+					},
+					Key: *e.SecretKey,
+				},
+			}
+		} else if e.Value != nil {
+			env.Value = *e.Value
+		}
+		result = append(result, env)
+	}
+	return result
+}
@@ -24,7 +24,7 @@ type Health struct {
 // @Produce  json
 // @Success 200 {object} docs.HealthKubernetesResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/health/kubernetesAuth [get]
@@ -53,7 +53,7 @@ func (h *Health) CheckK8s(c *gin.Context) {
 // @Success 200 {object} docs.HealthDatabaseResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/health/databaseAuth [post]
@@ -1,39 +1,46 @@
 package beta
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
-	"sync"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/golang/glog"
+	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"github.com/nchc-ai/backend-api/pkg/consts"
 	"github.com/nchc-ai/backend-api/pkg/model"
-	"github.com/nchc-ai/backend-api/pkg/model/common"
 	"github.com/nchc-ai/backend-api/pkg/model/db"
-	img "github.com/nchc-ai/backend-api/pkg/model/image"
+	"github.com/nchc-ai/backend-api/pkg/registry"
 	"github.com/nchc-ai/backend-api/pkg/util"
 	"github.com/nchc-ai/oauth-provider/pkg/provider"
+	v1 "k8s.io/api/core/v1"
 )
 
 type Image struct {
-	provider provider.Provider
-	db       *gorm.DB
+	provider  provider.Provider
+	db        *gorm.DB
+	job       *Job
+	committer *ImageCommitter
+	catalog   *ImageCatalog
 }
 
-// @Summary List images in nchcai/train dockerhub repo
-// @Description List images in nchcai/train dockerhub repo
+// @Summary List images in configured registries
+// @Description List images in repositories of configured registries, which is nchcai/train dockerhub repo by default,
+// @Description user's repository and images committed by user. Size, digest, architectures, last updated time, description
+// @Description and gpu are given as far as registry tells. Pinned name@digest can be used as course image instead of mutable tag.
 // @Tags Image
 // @Accept  json
 // @Produce  json
+// @Param arch query string false "only list images built for architecture, eg: amd64"
+// @Param sort query string false "recent: most recently updated first"
 // @Success 200 {object} docs.ImagesListResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/images [get]
@@ -44,12 +51,16 @@ func (i *Image) List(c *gin.Context) {
 		providerName = ""
 	}
 
-	userRepo := ""
-	u, err := getUserInfoFromToken(i.provider, c)
-	if err != nil {
+	userRepo, userName := "", ""
+	if loginUser, ok := getLoginUser(c); ok {
+		log.Infof("List images from nchcai/train and %s", loginUser.Repository)
+		userRepo = loginUser.Repository
+		userName = loginUser.User
+	} else if u, err := getUserInfoFromToken(i.provider, c); err != nil {
 		log.Warningf("Something wrong when query user from token: %s", err.Error())
 		log.Warningf("Only list nchcai/train dockerhub image")
 	} else {
+		userName = u.Username
 		uu := db.User{
 			User:     u.Username,
 			Provider: util.StringPtr(providerName.(string)),
@@ -64,21 +75,59 @@ func (i *Image) List(c *gin.Context) {
 		}
 	}
 	// get userRepo name from user db
-	imgs, err := listhubimage(userRepo)
+	imgs, err := i.catalog.List(userRepo)
 	if err != nil {
-		log.Errorf("Failed to get images information from Dockerhub: %s", err.Error())
-		RespondWithError(c, http.StatusInternalServerError, "Failed to get images information from Dockerhub: %s", err.Error())
+		log.Errorf("Failed to get images information from registry: %s", err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Failed to get images information from registry: %s", err.Error())
 		return
 	}
 
-	imageList := []common.LabelValue{}
+	// images committed from jobs of user's courses
+	if userName != "" {
+		committed, err := db.UserCommittedImages(i.db, userName, providerName.(string))
+		if err != nil {
+			log.Warningf("Something wrong when query committed images of user {%s}: %s", userName, err.Error())
+		} else {
+			for _, ref := range committed {
+				image, err := registry.ParseReference(ref)
+				if err != nil {
+					log.Warningf("Committed image {%s} is invalid: %s", ref, err.Error())
+					continue
+				}
+				imgs = append(imgs, image)
+			}
+		}
+	}
 
-	for _, n := range imgs {
-		lbval := common.LabelValue{
-			Label: n,
-			Value: n,
+	if arch := c.Query("arch"); arch != "" {
+		selected := []registry.Image{}
+		for _, image := range imgs {
+			if image.HasArchitecture(arch) {
+				selected = append(selected, image)
+			}
 		}
-		imageList = append(imageList, lbval)
+		imgs = selected
+	}
+
+	// images without last updated time are listed last
+	if c.Query("sort") == "recent" {
+		sort.SliceStable(imgs, func(a, b int) bool {
+			if imgs[b].LastUpdated == nil {
+				return imgs[a].LastUpdated != nil
+			}
+			return imgs[a].LastUpdated != nil && imgs[a].LastUpdated.After(*imgs[b].LastUpdated)
+		})
+	}
+
+	imageList := []model.ImageOption{}
+
+	for _, image := range imgs {
+		imageList = append(imageList, model.ImageOption{
+			Label:  image.Ref(),
+			Value:  image.Ref(),
+			Pinned: image.Pinned(),
+			Image:  image,
+		})
 	}
 
 	c.JSON(http.StatusOK, model.ImagesListResponse{
@@ -88,21 +137,21 @@ func (i *Image) List(c *gin.Context) {
 }
 
 // @Summary Commit current container into new image
-// @Description Commit current container into new image
+// @Description Commit container of running job into new image, which is pushed to configured registry by a builder job in background.
+// @Description Only owner of the course can commit the job. Committed image is listed in images of course owner once it is succeeded.
+// @Description Image is pushed under repository of course owner, which is prefixed to image name if it is not given.
 // @Tags Image
 // @Accept  json
 // @Produce  json
 // @Param commit body docs.CommitImage true "course job id and new image name:tag"
-// @Success 200 {object} docs.GenericOKResponse
+// @Success 200 {object} docs.ImageCommitResponse
+// @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/images/commit [post]
 func (i *Image) Commit(c *gin.Context) {
-	// todo: use docker api to commit image (#66)
-	// here is a mockup api implementation
-
 	var req model.CommitReq
 
 	err := c.BindJSON(&req)
@@ -112,187 +161,274 @@ func (i *Image) Commit(c *gin.Context) {
 		return
 	}
 
-	s := strings.Split(req.ImageName, ":")
+	if !i.committer.Enabled() {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_IMAGE_COMMIT_DISABLED)
+		return
+	}
 
-	tag := ""
-	if len(s) == 2 {
-		tag = s[1]
-	} else {
-		tag = "latest"
+	name, tag, ok := parseImageName(req.ImageName)
+	if !ok {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_IMAGE_COMMIT_NAME_FMT, req.ImageName)
+		return
 	}
 
-	RespondWithOk(c, "Commit Job {%s} into new image {%s}, use tag {%s}", req.JobID, s[0], tag)
-}
+	job := db.Job{
+		Model: db.Model{
+			ID: req.JobID,
+		},
+	}
+	if err := i.db.First(&job).Error; err != nil {
+		errStr := fmt.Sprintf("find job {%s} fail: %s", req.JobID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusBadRequest, errStr)
+		return
+	}
 
-func listhubimage(userRepo string) ([]string, error) {
+	if !checkJobOwner(c, i.db, &job) {
+		return
+	}
 
-	// 1. query system default repo (nchcai/train:<all-tag>)
+	course, err := job.GetCourse(i.db)
+	if err != nil {
+		errStr := fmt.Sprintf("Query Course info for job {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
 
-	var wg sync.WaitGroup
-	wg.Add(2)
+	// same as canSnapshot of job, only course owner is allowed
+	if !checkCourseOwner(c, i.db, course) {
+		return
+	}
 
-	var err1 error
-	var r1 []string
-	go func() {
-		defer wg.Done()
-		r, err := _find(consts.AiTrainUser)
-		if err != nil {
-			err1 = err
-			return
-		}
-		r1 = r
-	}()
-
-	// 2. query user defined repo (<user-repo>/<trainXXX>:<all-tag>)
-	//	  Only image name start with train is feasible.
-	var err2 error
-	var r2 []string
-	go func() {
-		defer wg.Done()
-		r, err := _find(userRepo)
-		if err != nil {
-			err2 = err
+	// images are pushed under repository of course owner, so others' images cannot be overwritten
+	if prefix := ownerRepository(course.User, course.Provider); !strings.HasPrefix(name, prefix+"/") {
+		name = prefix + "/" + name
+	}
+	if len(name) > 255 {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_IMAGE_COMMIT_NAME_FMT, req.ImageName)
+		return
+	}
+
+	commits, err := db.UserImageCommits(i.db, course.User, course.Provider)
+	if err != nil {
+		errStr := fmt.Sprintf("Query image commits of user {%s} fail: %s", course.User, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	for _, commit := range commits {
+		if !commit.IsFinished() {
+			RespondWithError(c, http.StatusBadRequest, consts.ERROR_IMAGE_COMMIT_RUNNING_FMT, commit.Image)
 			return
 		}
-		r2 = r
-	}()
-	wg.Wait()
+	}
+
+	pod, ok := i.job.runningJobPod(c, &job)
+	if !ok {
+		return
+	}
 
-	if err1 != nil {
-		return nil, err1
+	commit := db.ImageCommit{
+		Model: db.Model{
+			ID: uuid.New().String(),
+		},
+		// image belongs to course owner, so it is listed for courses of owner
+		OauthUser:   course.OauthUser,
+		JobID:       job.ID,
+		CourseID:    course.ID,
+		ClassroomID: *job.ClassroomID,
+		Image:       i.committer.ImageRef(name, tag),
+		Status:      db.COMMIT_PENDING,
+	}
+	if err := commit.NewEntry(i.db); err != nil {
+		errStr := fmt.Sprintf("Insert image commit of job {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
 	}
 
-	if err2 != nil {
-		return nil, err2
+	if err := i.committer.Start(&commit, pod); err != nil {
+		log.Errorf("Start image commit {%s} of job {%s} fail: %s", commit.ID, job.ID, err.Error())
+		i.committer.finish(&commit, db.COMMIT_FAILED, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, consts.ERROR_IMAGE_COMMIT_CREATE_FMT, commit.Image)
+		return
 	}
 
-	result := append([]string{}, append(r1, r2...)...)
-	return result, nil
+	log.Infof("Commit job {%s} into new image {%s} is started", job.ID, commit.Image)
+	c.JSON(http.StatusOK, model.ImageCommitResponse{
+		Error:  false,
+		Commit: commit,
+	})
 }
 
-func findRepoImagesName(userRepo string) ([]string, error) {
+// @Summary List someone's image commits
+// @Description List image commits of user, newest first.
+// @Tags Image
+// @Accept  json
+// @Produce  json
+// @Param user query string false "user name, only used when secure api is disabled"
+// @Success 200 {object} docs.ImageCommitListResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/commit/list [get]
+func (i *Image) CommitList(c *gin.Context) {
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
 
-	result := []string{}
-	nextURL := consts.BaseDockerHubUrl + userRepo
+	user := callerName(c, c.Query("user"))
+	if user == "" {
+		log.Errorf("Empty user name")
+		RespondWithError(c, http.StatusBadRequest, "Empty user name")
+		return
+	}
 
-	// golang style do-while loop
-	//https://yourbasic.org/golang/do-while-loop/
-	for {
-		req, err := http.NewRequest("GET", nextURL, nil)
+	commits, err := db.UserImageCommits(i.db, user, provider.(string))
+	if err != nil {
+		errStr := fmt.Sprintf("Query image commits of user {%s} fail: %s", user, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
 
-		if err != nil {
-			return nil, err
-		}
+	c.JSON(http.StatusOK, model.ImageCommitListResponse{
+		Error:   false,
+		Commits: commits,
+	})
+}
 
-		req.Header.Add("Cache-Control", "no-cache")
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
+// @Summary Get status of an image commit
+// @Description Get status of an image commit, message is set if it is failed.
+// @Tags Image
+// @Accept  json
+// @Produce  json
+// @Param id path string true "image commit uuid"
+// @Success 200 {object} docs.ImageCommitResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/commit/get/{id} [get]
+func (i *Image) CommitGet(c *gin.Context) {
+	commit, ok := i.findImageCommit(c)
+	if !ok {
+		return
+	}
 
-		data, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
+	c.JSON(http.StatusOK, model.ImageCommitResponse{
+		Error:  false,
+		Commit: *commit,
+	})
+}
 
-		err = res.Body.Close()
-		if err != nil {
-			return nil, err
-		}
+// @Summary Get logs of an image commit
+// @Description Get logs of builder job in plain text. Logs are streamed from builder job while commit is running,
+// @Description and the end of logs kept after it is finished is returned otherwise.
+// @Tags Image
+// @Produce  plain
+// @Param id path string true "image commit uuid"
+// @Param follow query bool false "stream new logs while commit is running"
+// @Success 200 {string} string "builder logs"
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/commit/logs/{id} [get]
+func (i *Image) CommitLogs(c *gin.Context) {
+	commit, ok := i.findImageCommit(c)
+	if !ok {
+		return
+	}
 
-		imgResult := img.ImageResult{}
-		err = json.Unmarshal(data, &imgResult)
-		if err != nil {
-			return nil, err
-		}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	if commit.IsFinished() || commit.BuilderJob == "" {
+		c.String(http.StatusOK, commit.Logs)
+		return
+	}
 
-		for _, aa := range imgResult.Results {
-			if strings.HasPrefix(aa.Name, consts.AiTrainImagePrefix) {
-				result = append(result, aa.Name)
-			}
-		}
+	pod, err := i.committer.builderPod(commit.BuilderJob)
+	if err != nil {
+		// builder pod is not scheduled yet
+		log.Warningf("Find pod of image commit {%s} fail: %s", commit.ID, err.Error())
+		c.String(http.StatusOK, "")
+		return
+	}
 
-		if imgResult.Next == nil {
-			break
-		} else {
-			nextURL = *imgResult.Next
-		}
+	tail := int64(defaultLogTailLines)
+	stream, err := i.committer.KClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Follow:    c.Query("follow") == "true",
+		TailLines: &tail,
+	}).Stream(c.Request.Context())
+	if err != nil {
+		errStr := fmt.Sprintf("Get logs of pod {%s} fail: %s", pod.Name, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
 	}
-	return result, nil
-}
+	defer stream.Close()
 
-func findImageTag(userRepo, image string) ([]string, error) {
-	result := []string{}
-	nextURL := consts.BaseDockerHubUrl + strings.Join([]string{userRepo, image, "tags"}, "/")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
 
+	buf := make([]byte, 4096)
 	for {
-		req, err := http.NewRequest("GET", nextURL, nil)
-
-		if err != nil {
-			return nil, err
-		}
-
-		req.Header.Add("Cache-Control", "no-cache")
-		res, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-
-		data, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		err = res.Body.Close()
-		if err != nil {
-			return nil, err
+		n, err := stream.Read(buf)
+		if n > 0 {
+			if _, werr := c.Writer.Write(buf[:n]); werr != nil {
+				return
+			}
+			c.Writer.Flush()
 		}
-
-		tagResult := img.TagResult{}
-		err = json.Unmarshal(data, &tagResult)
 		if err != nil {
-			return nil, err
-		}
-
-		for _, aa := range tagResult.Results {
-			result = append(result, aa.Name)
-		}
-
-		if tagResult.Next == nil {
-			break
-		} else {
-			nextURL = *tagResult.Next
+			if err != io.EOF {
+				log.Warningf("read logs of pod {%s} fail: %s", pod.Name, err.Error())
+			}
+			return
 		}
 	}
-
-	return result, nil
 }
 
-func _find(user string) ([]string, error) {
-
-	result := []string{}
-
-	if user == "" {
-		return result, nil
+// findImageCommit finds image commit by id in url, and checks caller can access the job committed.
+func (i *Image) findImageCommit(c *gin.Context) (*db.ImageCommit, bool) {
+	id := c.Param("id")
+	if id == "" {
+		RespondWithError(c, http.StatusBadRequest, "Image commit Id is empty")
+		return nil, false
 	}
 
-	nchcaiImg, err := findRepoImagesName(user)
+	commit, err := db.GetImageCommit(i.db, id)
 	if err != nil {
-		return nil, err
+		log.Errorf("Query image commit {%s} fail: %s", id, err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Query image commit {%s} fail: %s", id, err.Error())
+		return nil, false
 	}
 
-	for _, imgName := range nchcaiImg {
-		imgTags, err := findImageTag(user, imgName)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, tag := range imgTags {
-			result = append(result, fmt.Sprintf("%s/%s:%s", user, imgName, tag))
-		}
+	job := db.Job{
+		OauthUser:   commit.OauthUser,
+		CourseID:    commit.CourseID,
+		ClassroomID: &commit.ClassroomID,
 	}
+	if !checkJobOwner(c, i.db, &job) {
+		return nil, false
+	}
+	return commit, true
+}
 
-	return result, nil
+// parseImageName splits name:tag of committed image, tag is latest if not given.
+// Committed image is pushed to configured registry, so name with host or digest is rejected.
+func parseImageName(image string) (string, string, bool) {
+	ref, err := registry.ParseReference(image)
+	if err != nil || ref.Digest != "" || ref.HasHost() {
+		return "", "", false
+	}
+	return ref.Name, ref.Tag, true
 }
 
 // todo: refact. same with proxy.QueryUser()
@@ -1,49 +1,64 @@
 package beta
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/nchc-ai/backend-api/pkg/registry"
 	"github.com/stretchr/testify/assert"
 )
 
 const TEST_USER = "some_github_user"
 
+var dockerHub = registry.NewDockerHub(registry.DefaultConfig())
+
 func TestListImage(t *testing.T) {
+	requireTestEnv(t)
 
-	r, err := findRepoImagesName("nchcai")
+	images, err := dockerHub.ListImages("nchcai")
 	// should be no error
 	assert.NoError(t, err)
 
-	// only onw image exist in nchcai repository
-	assert.Equal(t, 1, len(r))
-
-	// the only one image name is train
-	assert.Equal(t, "train", r[0])
+	names := map[string]bool{}
+	for _, i := range images {
+		names[i.Name] = true
+	}
+	// only one image exist in nchcai repository, and its name is train
+	assert.Equal(t, map[string]bool{"nchcai/train": true}, names)
 }
 
 func TestListImageTag(t *testing.T) {
+	requireTestEnv(t)
 	// There is only one train-test:latest
-	r, err := findImageTag(TEST_USER, "train-test")
-
+	images, err := dockerHub.ListImages(TEST_USER)
 	assert.NoError(t, err)
-	// should only 1 tag of image train-test
-	assert.Equal(t, 1, len(r))
-	// the only one tag is latest
-	assert.Equal(t, "latest", r[0])
+
+	tags := []string{}
+	for _, i := range images {
+		if i.Name == TEST_USER+"/train-test" {
+			tags = append(tags, i.Tag)
+		}
+	}
+	// the only one tag of image train-test is latest
+	assert.Equal(t, []string{"latest"}, tags)
 }
 
 func TestList(t *testing.T) {
-	nchcaiResult, _ := listhubimage("")
-	r, _ := listhubimage(TEST_USER)
+	requireTestEnv(t)
+	registries, _ := registry.NewAll(nil)
+	catalog := NewImageCatalog(nil, registries, nil)
+	nchcaiResult, _ := catalog.List("")
+	r, _ := catalog.List(TEST_USER)
 
-	// listhubimage(TEST_USER) will loockup nchcai repo & one more TEST_USER repo
+	// catalog.List(TEST_USER) will loockup nchcai repo & one more TEST_USER repo
 	// TEST_USER repo has only one train-test image
 	assert.Equal(t, len(nchcaiResult)+1, len(r))
 }
 
 func TestListNonExistRepo(t *testing.T) {
+	requireTestEnv(t)
 	// an non-existing docker huh
-	r, err := _find("ogreaaa")
+	r, err := dockerHub.ListImages("ogreaaa")
 
 	// error is not occur when lookup non-existing dockerhub
 	assert.NoError(t, err)
@@ -51,3 +66,38 @@ func TestListNonExistRepo(t *testing.T) {
 	// but should return zero result
 	assert.Equal(t, 0, len(r))
 }
+
+func TestParseImageName(t *testing.T) {
+	for _, tc := range []struct {
+		image string
+		name  string
+		tag   string
+		ok    bool
+	}{
+		{image: "train", name: "train", tag: "latest", ok: true},
+		{image: "owner/train:v1", name: "owner/train", tag: "v1", ok: true},
+		{image: "owner/train:", ok: false},
+		{image: "Owner/train:v1", ok: false},
+		{image: "harbor.example.com/owner/train:v1", ok: false},
+		{image: "localhost:5000/train:v1", ok: false},
+		{image: "owner/train@sha256:" + strings.Repeat("a", 64), ok: false},
+		{image: "owner/train:v1@sha256:" + strings.Repeat("a", 64), ok: false},
+	} {
+		name, tag, ok := parseImageName(tc.image)
+		assert.Equal(t, tc.ok, ok, tc.image)
+		assert.Equal(t, tc.name, name, tc.image)
+		assert.Equal(t, tc.tag, tag, tc.image)
+	}
+}
+
+func TestOwnerRepository(t *testing.T) {
+	repo := ownerRepository("Alice@Example.com", "github")
+	name, _, ok := parseImageName(repo + "/train:v1")
+	assert.True(t, ok)
+	assert.Equal(t, repo+"/train", name)
+	assert.Regexp(t, `^alice-example-com-[0-9a-f]{12}$`, repo)
+
+	// similar names or same name of other provider are kept apart
+	assert.NotEqual(t, repo, ownerRepository("alice-example.com", "github"))
+	assert.NotEqual(t, repo, ownerRepository("Alice@Example.com", "google"))
+}
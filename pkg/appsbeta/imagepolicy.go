@@ -0,0 +1,130 @@
+package beta
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+)
+
+type ImagePolicy struct {
+	db *gorm.DB
+}
+
+// @Summary List image allowlist
+// @Description List global image rules maintained by superuser, and rules of classroom if classroom is given.
+// @Description All images are allowed if there is no global rule, and classroom rules narrow global rules.
+// @Tags Image
+// @Accept  json
+// @Produce  json
+// @Param classroom query string false "classroom id"
+// @Success 200 {object} docs.ImageRuleListResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/policy/list [get]
+func (p *ImagePolicy) List(c *gin.Context) {
+	classroomID := c.Query("classroom")
+	rules, err := db.ListImageRules(p.db, classroomID)
+	if err != nil {
+		log.Errorf("List image rules of classroom {%s} fail: %s", classroomID, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "List image rules fail: %s", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ImageRuleListResponse{
+		Error: false,
+		Rules: rules,
+	})
+}
+
+// @Summary Add image rule
+// @Description Add or update an image pattern in global allowlist, or allowlist of classroom. Global rule can only be set by superuser,
+// @Description and classroom rule by teacher of classroom. Pattern is image reference with optional wildcards, eg: nchcai/train:v1,
+// @Description nchcai/train, nchcai/train:v1.*, nchcai/*, harbor.example.com/ai/** or nchcai/train@sha256:<digest>.
+// @Description Docker Hub names are normalized, eg: docker.io/library/ubuntu is ubuntu, and docker.io/** matches any image on Docker Hub.
+// @Tags Image
+// @Accept  json
+// @Produce  json
+// @Param rule body docs.ImageRule true "scope is global or classroom, target is classroom id for classroom scope"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/policy/set [put]
+func (p *ImagePolicy) Set(c *gin.Context) {
+	req, ok := p.bindRule(c)
+	if !ok {
+		return
+	}
+
+	if err := req.Save(p.db); err != nil {
+		log.Errorf("Save image rule {%s:%s:%s} fail: %s", req.Scope, req.Target, req.Pattern, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Save image rule {%s} fail: %s", req.Pattern, err.Error())
+		return
+	}
+
+	RespondWithOk(c, "Image rule {%s} of {%s:%s} is saved successfully", req.Pattern, req.Scope, req.Target)
+}
+
+// @Summary Delete image rule
+// @Description Delete an image pattern from global allowlist, or allowlist of classroom.
+// @Tags Image
+// @Accept  json
+// @Produce  json
+// @Param rule body docs.ImageRuleTarget true "scope, target and pattern of rule"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/images/policy/delete [delete]
+func (p *ImagePolicy) Delete(c *gin.Context) {
+	req, ok := p.bindRule(c)
+	if !ok {
+		return
+	}
+
+	if err := req.Delete(p.db); err != nil {
+		log.Errorf("Delete image rule {%s:%s:%s} fail: %s", req.Scope, req.Target, req.Pattern, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Delete image rule {%s} fail: %s", req.Pattern, err.Error())
+		return
+	}
+
+	RespondWithOk(c, "Image rule {%s} of {%s:%s} is deleted successfully", req.Pattern, req.Scope, req.Target)
+}
+
+// bindRule parses and validates rule in request, and checks caller can manage rules of its scope.
+func (p *ImagePolicy) bindRule(c *gin.Context) (*db.ImageRule, bool) {
+	var req db.ImageRule
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return nil, false
+	}
+
+	if err := req.Normalize(); err != nil {
+		log.Errorf("invalid image rule: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "invalid image rule: %s", err.Error())
+		return nil, false
+	}
+
+	if req.Scope == db.IMAGE_SCOPE_CLASSROOM {
+		return &req, checkClassroomTeacher(c, p.db, req.Target)
+	}
+
+	if loginUser, ok := getLoginUser(c); ok && !loginUser.IsSuperuser() {
+		log.Warningf("user {%s} is not allowed to manage global image rules", loginUser.User)
+		RespondWithForbidden(c, consts.FORBIDDEN_ROLE, consts.ERROR_FORBIDDEN_ROLE_FMT, loginUser.Role, "image:admin")
+		return nil, false
+	}
+	return &req, true
+}
@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
+	"time"
 
-	"github.com/cenkalti/backoff"
 	"github.com/dghubble/sling"
 	"github.com/gin-gonic/gin"
 	log "github.com/golang/glog"
@@ -26,26 +27,46 @@ import (
 	"github.com/nchc-ai/course-cron/pkg/cron"
 	rfstackmodel "github.com/nchc-ai/rfstack/model"
 	"github.com/nitishm/go-rejson/v4"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
 	JobStatusCreated = "Created"
 	JobStatusPending = "Pending"
 	JobStatueReady   = "Ready"
+	JobStatusDeleted = "Deleted"
+	JobStatusQueued  = "Queued"
+)
+
+// upper bound of minutes extended by a request, so every minute can be checked against classroom schedule
+const maxExtendMinutes = 24 * 60
+
+// who deletes job, recorded in jobAudit
+const (
+	DeletedByUI        = "UI"
+	DeletedByTTL       = "TTL"
+	DeletedByIdle      = "IDLE"
+	DeletedByReconcile = "RECONCILE"
+	DeletedByTeacher   = "TEACHER"
+	DeletedBySchedule  = "SCHEDULE"
 )
 
 type Job struct {
+	KClientSet      *kubernetes.Clientset
 	CourseCrdClient *versioned.Clientset
 	DB              *gorm.DB
 	redis           *rejson.Handler
 	config          *config.Config
 	rfStackBase     *sling.Sling
-	StopChanMap     map[string](chan string)
+	statusCtrl      *JobStatusController
+	queue           *LaunchQueue
 }
 
 // @Summary List all running course deployment for a user
-// @Description List all running course deployment for a user
+// @Description List all running container jobs and vm jobs in rfstack for a user, type of job is CONTAINER or VM.
 // @Tags Job
 // @Accept  json
 // @Produce  json
@@ -53,7 +74,7 @@ type Job struct {
 // @Success 200 {object} docs.JobListResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/job/list [post]
@@ -70,6 +91,7 @@ func (j *Job) List(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
 		return
 	}
+	req.User = callerName(c, req.User)
 
 	if req.User == "" {
 		log.Errorf("Empty user name")
@@ -101,62 +123,90 @@ func (j *Job) List(c *gin.Context) {
 		return
 	}
 
-	//namespace := j.namespace
 	jobList := []model.JobInfo{}
 	for _, result := range resultJobs {
-		// find course information
-		courseInfo, err := result.GetCourse(j.DB)
-		if err != nil {
-			errStr := fmt.Sprintf("Query Course info for job {%s} fail: %s", result.ID, err.Error())
+		jobInfo, errStr := j.jobInfo(result, req.User, provider.(string))
+		if errStr != "" {
 			log.Errorf(errStr)
 			RespondWithError(c, http.StatusInternalServerError, errStr)
 			return
 		}
+		jobList = append(jobList, *jobInfo)
+	}
 
-		// find access URL information
-		accessURL, err := getCRDPort(j.CourseCrdClient, result, j.config.K8SConfig, *result.ClassroomID)
+	vmJobs, vmErr := j.listVMJob(c, req.User)
+	if vmErr != nil {
+		log.Warningf("List vm job of user {%s} fail: %s", req.User, vmErr.Error())
+	}
+	jobList = append(jobList, vmJobs...)
+
+	queuedJobs, queueErr := j.queue.userQueue(req.User, provider.(string))
+	if queueErr != nil {
+		log.Warningf("List queued job of user {%s} fail: %s", req.User, queueErr.Error())
+	}
+	jobList = append(jobList, queuedJobs...)
+
+	result.Error = false
+	result.Jobs = jobList
+
+	// add result to redis, partial result without vm jobs is not cached,
+	// neither is queue position which is changed by launch of others
+	if vmErr == nil && queueErr == nil && len(queuedJobs) == 0 {
+		_, err = j.redis.JSONSet(redisKey, ".", result)
 		if err != nil {
-			errStr := fmt.Sprintf("Parse Service info for job {%s} fail: %s", result.ID, err.Error())
-			log.Errorf(errStr)
-			RespondWithError(c, http.StatusInternalServerError, errStr)
-			return
+			log.Warningf("Failed to JSONSet")
 		}
+	}
 
-		snapshot := false
-		snapshot, _ = courseInfo.IsOwner(j.DB, req.User, provider.(string))
+	c.JSON(http.StatusOK, result)
+}
 
-		jobInfo := model.JobInfo{
-			Id:           result.ID,
-			CourseID:     courseInfo.ID,
-			StartAt:      result.CreatedAt,
-			Status:       result.Status,
-			Name:         courseInfo.Name,
-			Introduction: *courseInfo.Introduction,
-			Image:        courseInfo.Image,
-			Level:        courseInfo.Level,
-			GPU:          *courseInfo.Gpu,
-			CanSnapshot:  snapshot,
-			//Dataset:      courseInfo.Datasets,
-			Service: accessURL,
-		}
+// jobInfo collects course, access URL and lifetime of container job. Snapshot is allowed if viewer owns the course.
+func (j *Job) jobInfo(result db.Job, viewer, provider string) (*model.JobInfo, string) {
+	// find course information
+	courseInfo, err := result.GetCourse(j.DB)
+	if err != nil {
+		return nil, fmt.Sprintf("Query Course info for job {%s} fail: %s", result.ID, err.Error())
+	}
 
-		jobList = append(jobList, jobInfo)
+	// find access URL information
+	accessURL, err := getCRDPort(j.CourseCrdClient, result, j.config.K8SConfig, *result.ClassroomID)
+	if err != nil {
+		return nil, fmt.Sprintf("Parse Service info for job {%s} fail: %s", result.ID, err.Error())
 	}
 
-	result.Error = false
-	result.Jobs = jobList
+	snapshot := false
+	snapshot, _ = courseInfo.IsOwner(j.DB, viewer, provider)
+
+	jobInfo := model.JobInfo{
+		Id:           result.ID,
+		Type:         db.CONTAINER,
+		CourseID:     courseInfo.ID,
+		StartAt:      result.CreatedAt,
+		Status:       result.Status,
+		Name:         courseInfo.Name,
+		Introduction: *courseInfo.Introduction,
+		Image:        courseInfo.Image,
+		Level:        courseInfo.Level,
+		GPU:          *courseInfo.Gpu,
+		CanSnapshot:  snapshot,
+		//Dataset:      courseInfo.Datasets,
+		Service: accessURL,
+	}
 
-	// add result to redis
-	_, err = j.redis.JSONSet(redisKey, ".", result)
+	lifetime, err := result.GetLifetime(j.DB)
 	if err != nil {
-		log.Warningf("Failed to JSONSet")
+		log.Warningf("Query lifetime policy of job {%s} fail: %s", result.ID, err.Error())
+	} else if expireAt := lifetime.ExpireAt(&result); !expireAt.IsZero() {
+		jobInfo.ExpireAt = &expireAt
 	}
 
-	c.JSON(http.StatusOK, result)
+	return &jobInfo, ""
 }
 
 // @Summary Create a course CRD in kubernetes
 // @Description Create a course CRD in kubernetes
+// @Description If launch queue is enabled and free GPU is not enough, GPU job is queued with status Queued and its queue position.
 // @Tags Job
 // @Accept  json
 // @Produce  json
@@ -164,7 +214,7 @@ func (j *Job) List(c *gin.Context) {
 // @Success 200 {object} docs.LaunchCourseResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/job/launch [post]
@@ -177,6 +227,7 @@ func (j *Job) Launch(c *gin.Context) {
 		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
 		return
 	}
+	req.User = callerName(c, req.User)
 
 	course := db.Course{
 		Model: db.Model{
@@ -227,7 +278,7 @@ func (j *Job) Launch(c *gin.Context) {
 // @Success 200 {object} docs.GenericOKResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/job/delete/{id} [delete]
@@ -246,11 +297,27 @@ func (j *Job) Delete(c *gin.Context) {
 		},
 	}
 
-	audit := db.Audit{
-		Model: db.Model{
-			ID: jobId,
-		},
-		//DeletedBy: util.StringPtr("UI"),
+	// job still waiting in launch queue
+	if entry := j.queue.findQueued(jobId); entry != nil {
+		job.OauthUser = entry.OauthUser
+		job.CourseID = entry.CourseID
+		job.ClassroomID = &entry.ClassroomID
+		if !checkJobOwner(c, j.DB, &job) {
+			return
+		}
+		if cancelled, err := j.queue.cancel(entry); err != nil {
+			RespondWithError(c, http.StatusInternalServerError, "Remove job {%s} from launch queue fail: %s", jobId, err.Error())
+			return
+		} else if cancelled {
+			j.statusCtrl.events.publish(jobOwnerKey(entry.User, entry.Provider), jobEventStatus, model.JobStatus{
+				JobId:  jobId,
+				Ready:  false,
+				Status: JobStatusDeleted,
+			})
+			RespondWithOk(c, "Job {%s} is deleted successfully", jobId)
+			return
+		}
+		// job is launched by queue in the meantime, delete it as usual
 	}
 
 	// default type is CONTAINER, if job id not found in containerJob table, set type to VM
@@ -262,9 +329,14 @@ func (j *Job) Delete(c *gin.Context) {
 
 	switch courseType {
 	case db.CONTAINER:
-		j.DB.Model(&audit).Update("deleted_by", "UI")
-		if err := j.DB.Delete(audit).Error; err != nil {
-			log.Warningf(fmt.Sprintf("Failed to mark job {%s} deletion audit information : %s", audit.ID, err.Error()))
+		if !checkJobOwner(c, j.DB, &job) {
+			return
+		}
+
+		// job stopped by teacher or superuser rather than its owner
+		deletedBy := DeletedByUI
+		if u, ok := getLoginUser(c); ok && (u.User != job.User || u.Provider == nil || *u.Provider != job.Provider) {
+			deletedBy = DeletedByTeacher
 		}
 
 		// course type is container, delete container job
@@ -273,81 +345,186 @@ func (j *Job) Delete(c *gin.Context) {
 			RespondWithError(c, http.StatusInternalServerError, errStr)
 			return
 		}
-		j.StopChanMap[jobId] <- "STOP"
+		markAuditDeleted(j.DB, jobId, deletedBy)
+
+		j.statusCtrl.events.publish(jobOwnerKey(job.User, job.Provider), jobEventStatus, model.JobStatus{
+			JobId:  job.ID,
+			Ready:  false,
+			Status: JobStatusDeleted,
+		})
 		RespondWithOk(c, "Job {%s} is deleted successfully", jobId)
 	case db.VM:
+		if !checkVMJobOwner(c, j.DB, jobId) {
+			return
+		}
+
 		// course type is VM, use rfstack api delete VM job
 		j.deleteVMJob(c, jobId)
 		return
 	}
 }
 
-// PUBLIC utility function
-func CheckCourseCRDStatus(DB *gorm.DB, redis *rejson.Handler, crdClient *versioned.Clientset, ns string, CRDId string, stop chan string) {
+// @Summary Extend maximum runtime of a running job
+// @Description Extend maximum runtime of a container job within limit of classroom, extension is unlimited if maxExtension of classroom is zero.
+// @Description Every minute extended must be in classroom schedule, and at most 1440 minutes are extended at a time.
+// @Tags Job
+// @Accept  json
+// @Produce  json
+// @Param id path string true "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param extend body docs.ExtendJobRequest true "minutes to extend"
+// @Success 200 {object} docs.ExtendJobResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 409 {object} docs.GenericErrorResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/extend/{id} [post]
+func (j *Job) Extend(c *gin.Context) {
+	jobId := c.Param("id")
+
+	if jobId == "" {
+		RespondWithError(c, http.StatusBadRequest,
+			"Job Id is empty")
+		return
+	}
+
+	var req model.ExtendJobRequest
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return
+	}
+
+	if req.Minutes <= 0 || req.Minutes > maxExtendMinutes {
+		log.Errorf("extend minutes {%d} is not in 1 to %d", req.Minutes, maxExtendMinutes)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_EXTEND_MINUTES_FMT, req.Minutes, maxExtendMinutes)
+		return
+	}
 
-	jobObj := db.Job{
+	job := db.Job{
 		Model: db.Model{
-			ID: CRDId,
+			ID: jobId,
 		},
 	}
-	if err := DB.First(&jobObj).Error; err != nil {
+	if err := j.DB.First(&job).Error; err != nil {
+		errStr := fmt.Sprintf("find job {%s} fail: %s", jobId, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusBadRequest, errStr)
 		return
 	}
-	redisKey := fmt.Sprintf("%s:%s", jobObj.Provider, jobObj.User)
-
-	// binary backoff retry check if CRD accessible
 
-	operation := func() error {
+	if !checkJobOwner(c, j.DB, &job) {
+		return
+	}
 
-		v, _ := <-stop
-		if v != "STOP" {
-			stop <- ""
-		} else {
-			log.Info("Course CRD checker is stopped.")
-			return nil
-		}
+	lifetime, err := job.GetLifetime(j.DB)
+	if err != nil {
+		errStr := fmt.Sprintf("Query lifetime policy of job {%s} fail: %s", jobId, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
 
-		course, err := crdClient.NchcV1alpha1().Courses(ns).Get(context.Background(), CRDId, metav1.GetOptions{})
-		if err != nil {
-			log.Warningf("Get Course CRD {%s} fail: %s", CRDId, err.Error())
-			return err
-		}
-		if !course.Status.Accessible {
-			if err := DB.Model(&jobObj).Update("status", JobStatusPending).Error; err != nil {
-				log.Errorf("update job {%s} status to %s fail: %s", CRDId, JobStatusPending, err.Error())
-				return err
-			}
+	if lifetime.MaxRuntime <= 0 {
+		log.Errorf("job {%s} has no maximum runtime", jobId)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_EXTEND_NOLIMIT_FMT, jobId)
+		return
+	}
 
-			// delete redis cache
-			_, err = redis.JSONDel(redisKey, ".")
-			if err != nil {
-				log.Errorf("Delete cache key {%s} fail for update job status to %s: %s", redisKey, JobStatusPending, err.Error())
-				return err
-			}
+	cmInfo := db.ClassRoomInfo{}
+	if err := j.DB.Where("id = ?", *job.ClassroomID).First(&cmInfo).Error; err != nil {
+		errStr := fmt.Sprintf("find classroom {%s} of job {%s} fail: %s", *job.ClassroomID, jobId, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
 
-			log.Warningf(fmt.Sprintf("Course CRD {%s} is not Accessible", CRDId))
-			return errors.New(fmt.Sprintf("Course CRD {%s} is not Accessible", CRDId))
-		}
-		return nil
+	// zero or nil means unlimited, the same as other lifetime limits of classroom
+	maxExtension := int32(0)
+	if cmInfo.MaxExtension != nil {
+		maxExtension = *cmInfo.MaxExtension
+	}
+	// total extension is kept in int32 column, it is bounded by int32 even if classroom allows unlimited extension
+	if maxExtension <= 0 || maxExtension > math.MaxInt32-maxExtendMinutes {
+		maxExtension = math.MaxInt32 - maxExtendMinutes
 	}
+	if int64(job.Extended)+int64(req.Minutes) > int64(maxExtension) {
+		log.Errorf("job {%s} already extend %d minutes, classroom {%s} allows %d", jobId, job.Extended, cmInfo.ID, maxExtension)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_EXTEND_QUOTA_FMT, cmInfo.Name, maxExtension, job.Extended)
+		return
+	}
+
+	// every minute added should be still in allowed classroom schedule
+	extended := job.Extended
+	oldExpireAt := lifetime.ExpireAt(&job)
+	job.Extended = extended + req.Minutes
+	expireAt := lifetime.ExpireAt(&job)
 
-	err := backoff.Retry(operation, backoff.NewExponentialBackOff())
+	schedules, err := cmInfo.GetSchedule(j.DB)
 	if err != nil {
-		log.Warningf("check CRD {%s} Accessible Retry timeout: %s", CRDId, err.Error())
+		errStr := fmt.Sprintf("Query schedule of classroom {%s} fail: %s", cmInfo.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
 		return
 	}
 
-	if err := DB.Model(&jobObj).Update("status", JobStatueReady).Error; err != nil {
-		log.Errorf("update job {%s} status to %s fail: %s", CRDId, JobStatueReady, err.Error())
+	if outAt, ok := outOfSchedule(schedules.CronFormat, oldExpireAt, expireAt); !ok {
+		log.Errorf("extended time %s of job {%s} is out of classroom {%s} schedule", outAt, jobId, cmInfo.ID)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_EXTEND_TIME_FMT,
+			cmInfo.Name, cmInfo.ScheduleDescription, outAt.Format("2006-01-02 15:04"))
 		return
 	}
-	// delete redis cache
-	_, err = redis.JSONDel(redisKey, ".")
+
+	// job may be extended by another request after it is read
+	updated, err := db.ExtendJob(j.DB, jobId, extended, req.Minutes, maxExtension)
 	if err != nil {
-		log.Errorf("Delete cache key {%s} fail for update job status to %s: %s", redisKey, JobStatueReady, err.Error())
+		errStr := fmt.Sprintf("update extended time of job {%s} fail: %s", jobId, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
 		return
 	}
+	if !updated {
+		log.Errorf("job {%s} is extended by another request at the same time", jobId)
+		RespondWithError(c, http.StatusConflict, consts.ERROR_JOB_EXTEND_CONFLICT_FMT, jobId)
+		return
+	}
+
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+	extendBy := job.User
+	if loginUser, ok := getLoginUser(c); ok {
+		extendBy = loginUser.User
+	}
+	extension := db.ExtensionAudit{
+		Model: db.Model{
+			ID: uuid.New().String(),
+		},
+		OauthUser: db.OauthUser{
+			User:     extendBy,
+			Provider: provider.(string),
+		},
+		JobID:    jobId,
+		Minutes:  req.Minutes,
+		ExpireAt: expireAt,
+	}
+	if err := extension.NewEntry(j.DB); err != nil {
+		log.Warningf("Insert extension of job {%s} in audit table fail: %s", jobId, err.Error())
+	}
 
+	// expireAt in job list is changed
+	redisKey := fmt.Sprintf("%s:%s", job.Provider, job.User)
+	if _, err := j.redis.JSONDel(redisKey, "."); err != nil {
+		log.Warningf("Delete cache key {%s} fail for extend job: %s", redisKey, err.Error())
+	}
+
+	c.JSON(http.StatusOK, model.ExtendJobResponse{
+		Error:    false,
+		JobId:    jobId,
+		ExpireAt: expireAt,
+	})
 }
 
 // PRIVATE function
@@ -371,7 +548,14 @@ func buildCourseCRD(DB *gorm.DB, classroomID, courseID string, user *db.User, co
 		return nil, []error{err, err}
 	}
 
-	// todo: if classroom is default, course is ONLY allowed run for one hour
+	// policy may be changed after course is created
+	if err := db.CheckImageAllowed(DB, course.Image, classroomID); err != nil {
+		return nil, []error{
+			err,
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_IMAGE_FMT, course.Name, course.Image, cm.Name, course.User)),
+		}
+	}
+
 	schedule, err := cm.GetSchedule(DB)
 	if err != nil {
 		return nil, []error{err, err}
@@ -450,9 +634,81 @@ func buildCourseCRD(DB *gorm.DB, classroomID, courseID string, user *db.User, co
 		}
 	}
 
+	// 	Step 3-4: resource profile and node selector of course
+	if errs := setResourceAnnotations(crdDef, &course.ResourceProfile, config.APIConfig.Resource); errs != nil {
+		return nil, []error{
+			errs[0],
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, course.Name)),
+		}
+	}
+
+	// 	Step 3-5: environment variables of course
+	envs, err := course.GetEnv(DB)
+	if err != nil {
+		log.Error(fmt.Sprintf("Query course {%s} environment variables fail", courseID))
+		return nil, []error{
+			err,
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, course.Name)),
+		}
+	}
+	if err := setEnvAnnotation(crdDef, envs); err != nil {
+		return nil, []error{
+			err,
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, course.Name)),
+		}
+	}
+
 	return crdDef, nil
 }
 
+// setResourceAnnotations puts resource requests, limits and node selector of course in Course CRD annotations.
+func setResourceAnnotations(crdDef *v1alpha1.Course, profile *db.ResourceProfile, resourceConfig config.ResourceConfig) []error {
+	requirements := resourceRequirements(profile)
+	if len(requirements.Requests) > 0 || len(requirements.Limits) > 0 {
+		b, err := json.Marshal(requirements)
+		if err != nil {
+			return []error{err}
+		}
+		crdDef.Annotations[consts.CourseAnnotationResources] = string(b)
+	}
+
+	selector, err := profile.NodeSelectorMap(resourceConfig.GpuModelLabel)
+	if err != nil {
+		return []error{err}
+	}
+	if selector != nil {
+		b, err := json.Marshal(selector)
+		if err != nil {
+			return []error{err}
+		}
+		crdDef.Annotations[consts.CourseAnnotationNodeSelector] = string(b)
+	}
+	return nil
+}
+
+// resourceRequirements converts resource profile of course to container resources, zero limit means no limit.
+func resourceRequirements(profile *db.ResourceProfile) v1.ResourceRequirements {
+	requirements := v1.ResourceRequirements{
+		Requests: v1.ResourceList{},
+		Limits:   v1.ResourceList{},
+	}
+	set := func(list v1.ResourceList, name v1.ResourceName, v *int64, q func(int64) *resource.Quantity) {
+		if v != nil && *v > 0 {
+			list[name] = *q(*v)
+		}
+	}
+	milli := func(v int64) *resource.Quantity { return resource.NewMilliQuantity(v, resource.DecimalSI) }
+	mebi := func(v int64) *resource.Quantity { return resource.NewQuantity(v*1024*1024, resource.BinarySI) }
+
+	set(requirements.Requests, v1.ResourceCPU, profile.CpuRequest, milli)
+	set(requirements.Limits, v1.ResourceCPU, profile.CpuLimit, milli)
+	set(requirements.Requests, v1.ResourceMemory, profile.MemoryRequest, mebi)
+	set(requirements.Limits, v1.ResourceMemory, profile.MemoryLimit, mebi)
+	set(requirements.Requests, v1.ResourceEphemeralStorage, profile.StorageRequest, mebi)
+	set(requirements.Limits, v1.ResourceEphemeralStorage, profile.StorageLimit, mebi)
+	return requirements
+}
+
 func getCRDPort(crdclient *versioned.Clientset, job db.Job, config *config.K8SConfig, namespace string) ([]common.LabelValue, error) {
 
 	result := []common.LabelValue{}
@@ -504,11 +760,16 @@ func (j *Job) preCheckJob(c *gin.Context, req *model.LaunchCourseRequest) (bool,
 		provider = db.DEFAULT_PROVIDER
 	}
 
+	return j.precheck(req, provider.(string))
+}
+
+// precheck is preCheckJob without request context, which is also used when queued job is launched.
+func (j *Job) precheck(req *model.LaunchCourseRequest, provider string) (bool, []error) {
 	if req.ClassroomId == "" {
 		//without classroom id
 		// check user is superuser -> check count
 		// check user is onwer -> check count
-		return j.precheckWithoutClassroom(req, provider.(string))
+		return j.precheckWithoutClassroom(req, provider)
 	} else {
 		//with classroom id
 		// check classroom is pubic
@@ -516,10 +777,8 @@ func (j *Job) preCheckJob(c *gin.Context, req *model.LaunchCourseRequest) (bool,
 		// check classroom has course
 		// check user is superuser -> check count
 		// check user is teacher or student -> check count
-		return j.precheckWithClassroom(req, provider.(string))
+		return j.precheckWithClassroom(req, provider)
 	}
-
-	return true, nil
 }
 
 func (j *Job) precheckWithClassroom(req *model.LaunchCourseRequest, provider string) (bool, []error) {
@@ -646,18 +905,21 @@ func (j *Job) precheckWithoutClassroom(req *model.LaunchCourseRequest, provider
 }
 
 func (j *Job) precheckCount(req *model.LaunchCourseRequest, newJob db.Job) (bool, []error) {
-	// user usage constraint: one user can start ONLY one job at one time
-	c_count, err := newJob.UserJobCount(j.DB)
+	// user usage constraint: running jobs of one user can not exceed quota
+	limit, err := j.userLimit(newJob)
 	if err != nil {
 		return false, []error{
-			errors.New(fmt.Sprintf("Query user container job count fail: %s", err.Error())),
-			errors.New(fmt.Sprintf("Query user container job count fail: %s", err.Error())),
+			errors.New(fmt.Sprintf("Query user {%s} quota fail: %s", req.User, err.Error())),
+			errors.New(fmt.Sprintf("Query user {%s} quota fail: %s", req.User, err.Error())),
 		}
 	}
-	if c_count > 0 {
+
+	quota := j.config.APIConfig.Quota
+	usage, err := db.UserContainerUsage(j.DB, newJob.User, newJob.Provider, quota)
+	if err != nil {
 		return false, []error{
-			errors.New(fmt.Sprintf("user {%s} already lauch %d container job", req.User, c_count)),
-			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_QUOTA_FMT, req.User, c_count)),
+			errors.New(fmt.Sprintf("Query user container job usage fail: %s", err.Error())),
+			errors.New(fmt.Sprintf("Query user container job usage fail: %s", err.Error())),
 		}
 	}
 
@@ -668,21 +930,154 @@ func (j *Job) precheckCount(req *model.LaunchCourseRequest, newJob db.Job) (bool
 			errors.New(fmt.Sprintf("Query user vm job count fail: %s", err.Error())),
 		}
 	}
-	if vm_count > 0 {
+	usage.Jobs = usage.Jobs + vm_count
+	usage.Cpu = usage.Cpu + int64(vm_count)*quota.JobCpu
+	usage.Memory = usage.Memory + int64(vm_count)*quota.JobMemory
+
+	gpu, err := requestGpu(j.DB, req.CourseId)
+	if err != nil {
 		return false, []error{
-			errors.New(fmt.Sprintf("user {%s} already lauch %d vm job", req.User, vm_count)),
-			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_QUOTA_FMT, req.User, vm_count)),
+			errors.New(fmt.Sprintf("Query gpu of course {%s} fail: %s", req.CourseId, err.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, req.CourseId)),
+		}
+	}
+
+	cpu, memory, err := requestResource(j.DB, req.CourseId, quota)
+	if err != nil {
+		return false, []error{
+			errors.New(fmt.Sprintf("Query resource profile of course {%s} fail: %s", req.CourseId, err.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, req.CourseId)),
+		}
+	}
+
+	// GPU reserved by other classrooms can not be used, queue handles this itself when it is enabled
+	if gpu > 0 && !j.queue.enabled() {
+		free, err := j.queue.freeGpu(req.ClassroomId)
+		if err != nil {
+			return false, []error{
+				errors.New(fmt.Sprintf("Query free gpu fail: %s", err.Error())),
+				errors.New(fmt.Sprintf("Query free gpu fail: %s", err.Error())),
+			}
+		}
+		unused, err := unusedReservations(j.DB, time.Now())
+		if err != nil {
+			return false, []error{
+				errors.New(fmt.Sprintf("Query reservations fail: %s", err.Error())),
+				errors.New(fmt.Sprintf("Query reservations fail: %s", err.Error())),
+			}
+		}
+		// job is not rejected only because cluster is busy, it will be pending as before
+		if reservedForOthers(unused, req.ClassroomId) > 0 && free < int64(gpu) {
+			return false, []error{
+				errors.New(fmt.Sprintf("gpu is reserved by other classrooms, %d gpu is free, request %d", free, gpu)),
+				errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_RESERVED_FMT, free, gpu)),
+			}
+		}
+	}
+
+	// negative limit means unlimited, zero limit allows nothing
+	if limit.Jobs >= 0 && usage.Jobs+1 > limit.Jobs {
+		return false, []error{
+			errors.New(fmt.Sprintf("user {%s} already lauch %d job, quota is %d", req.User, usage.Jobs, limit.Jobs)),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_QUOTA_FMT, limit.Jobs, req.User, usage.Jobs)),
+		}
+	}
+
+	if limit.Gpu >= 0 && usage.Gpu+gpu > limit.Gpu {
+		return false, []error{
+			errors.New(fmt.Sprintf("user {%s} already use %d gpu, request %d, quota is %d", req.User, usage.Gpu, gpu, limit.Gpu)),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_GPU_FMT, limit.Gpu, req.User, usage.Gpu, gpu)),
+		}
+	}
+
+	if limit.Cpu >= 0 && usage.Cpu+cpu > limit.Cpu {
+		return false, []error{
+			errors.New(fmt.Sprintf("user {%s} already use %dm cpu, request %dm, quota is %dm", req.User, usage.Cpu, cpu, limit.Cpu)),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_CPU_FMT, limit.Cpu, req.User, usage.Cpu, cpu)),
+		}
+	}
+
+	if limit.Memory >= 0 && usage.Memory+memory > limit.Memory {
+		return false, []error{
+			errors.New(fmt.Sprintf("user {%s} already use %dMi memory, request %dMi, quota is %dMi", req.User, usage.Memory, memory, limit.Memory)),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_MEMORY_FMT, limit.Memory, req.User, usage.Memory, memory)),
 		}
 	}
 
 	return true, nil
 }
 
+// userLimit resolves effective quota of job launcher from default, role, classroom and user quota.
+func (j *Job) userLimit(newJob db.Job) (*db.Limit, error) {
+	u := db.User{
+		User:     newJob.User,
+		Provider: util.StringPtr(newJob.Provider),
+	}
+	role, err := u.GetRole(j.DB)
+	if err != nil && !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+
+	classroomID := ""
+	if newJob.ClassroomID != nil {
+		classroomID = *newJob.ClassroomID
+	}
+
+	return db.EffectiveLimit(j.DB, j.config.APIConfig.Quota, role, classroomID, newJob.User, newJob.Provider)
+}
+
+// requestGpu returns gpu number required by course, vm course doesn't request gpu.
+func requestGpu(DB *gorm.DB, courseID string) (int32, error) {
+	course := db.Course{
+		Model: db.Model{
+			ID: courseID,
+		},
+	}
+	courseType, err := course.Type(DB)
+	if err != nil {
+		return 0, err
+	}
+	if courseType == db.VM {
+		return 0, nil
+	}
+
+	c, err := db.GetCourse(DB, courseID)
+	if err != nil {
+		return 0, err
+	}
+	if c.Gpu == nil {
+		return 0, nil
+	}
+	return *c.Gpu, nil
+}
+
+// requestResource returns cpu and memory counted in quota for job of course, vm course is counted with defaults of each job.
+func requestResource(DB *gorm.DB, courseID string, defaults config.QuotaConfig) (int64, int64, error) {
+	course := db.Course{
+		Model: db.Model{
+			ID: courseID,
+		},
+	}
+	courseType, err := course.Type(DB)
+	if err != nil {
+		return 0, 0, err
+	}
+	if courseType == db.VM {
+		return defaults.JobCpu, defaults.JobMemory, nil
+	}
+
+	c, err := db.GetCourse(DB, courseID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.QuotaCpu(defaults), c.QuotaMemory(defaults), nil
+}
+
 func (j *Job) isSuperuser(user, provider string) bool {
 
 	u := db.User{
 		Provider: util.StringPtr(provider),
-		Role:     "superuser",
+		Role:     db.ROLE_SUPERUSER,
 	}
 
 	superuserList, err := u.GetRoleList(j.DB)
@@ -708,10 +1103,61 @@ func (j *Job) launchContainerJob(c *gin.Context, req *model.LaunchCourseRequest)
 		provider = db.DEFAULT_PROVIDER
 	}
 
+	status, errs := j.startContainerJob(req, provider.(string))
+	if errs != nil {
+		log.Error(errs[0].Error())
+		RespondWithError(c, http.StatusInternalServerError, errs[1].Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, model.LaunchCourseResponse{
+		Error: false,
+		Job:   *status,
+	})
+}
+
+// startContainerJob creates job for request which passes preCheckJob, or puts it in launch queue if free GPU is not enough.
+// errs[0] is for log and errs[1] is for user if start fail.
+func (j *Job) startContainerJob(req *model.LaunchCourseRequest, provider string) (*model.JobStatus, []error) {
+	gpu, err := requestGpu(j.DB, req.CourseId)
+	if err != nil {
+		errStr := fmt.Sprintf("Query gpu of course {%s} fail: %s", req.CourseId, err.Error())
+		return nil, []error{errors.New(errStr), errors.New(errStr)}
+	}
+
+	// wait in launch queue if free gpu is not enough
+	entry, position, err := j.queue.admitOrEnqueue(req, provider, gpu)
+	if err != nil {
+		errStr := fmt.Sprintf("Admit course {%s} job into launch queue fail: %s", req.CourseId, err.Error())
+		return nil, []error{errors.New(errStr), errors.New(errStr)}
+	}
+	if entry != nil {
+		status := queuedJobStatus(*entry, position)
+		j.statusCtrl.events.publish(jobOwnerKey(entry.User, entry.Provider), jobEventStatus, status)
+		return &status, nil
+	}
+
+	newJob, errs := j.createContainerJob(req, provider, "")
+	j.queue.launched(gpu)
+	if errs != nil {
+		return nil, errs
+	}
+
+	return &model.JobStatus{
+		JobId:  newJob.ID,
+		Ready:  false,
+		Status: JobStatusCreated,
+	}, nil
+}
+
+// createContainerJob creates Course CRD and job record for user in request, request must pass preCheckJob.
+// Job id is generated if jobID is empty. errs[0] is for log and errs[1] is for user if creation fail.
+func (j *Job) createContainerJob(req *model.LaunchCourseRequest, provider, jobID string) (*db.Job, []error) {
+
 	newJob := db.Job{
 		OauthUser: db.OauthUser{
 			User:     req.User,
-			Provider: provider.(string),
+			Provider: provider,
 		},
 		CourseID:    req.CourseId,
 		ClassroomID: &req.ClassroomId,
@@ -720,40 +1166,62 @@ func (j *Job) launchContainerJob(c *gin.Context, req *model.LaunchCourseRequest)
 
 	u := db.User{
 		User:     req.User,
-		Provider: util.StringPtr(provider.(string)),
+		Provider: util.StringPtr(provider),
 	}
 
 	user, err := u.FindUser(j.DB)
 	if err != nil {
-		log.Errorf("Query user info fail: %s", err.Error())
-		RespondWithError(c, http.StatusInternalServerError, consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, req.CourseId)
-		return
+		return nil, []error{
+			errors.New(fmt.Sprintf("Query user info fail: %s", err.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, req.CourseId)),
+		}
 	}
 
 	course, err := db.GetCourse(j.DB, req.CourseId)
 	if err != nil {
-		log.Errorf("Query course info fail: %s", err.Error())
-		RespondWithError(c, http.StatusInternalServerError, consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, req.CourseId)
-		return
+		return nil, []error{
+			errors.New(fmt.Sprintf("Query course info fail: %s", err.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, req.CourseId)),
+		}
 	}
 
 	CRDDef, errs := buildCourseCRD(j.DB, req.ClassroomId, req.CourseId, user, j.config)
 
 	if errs != nil {
-		log.Errorf(" user {%s} build Course {%s} CRD in Classroom {%s} fail: %s",
-			req.User, req.CourseId, req.ClassroomId, errs[0].Error())
-		RespondWithError(c, http.StatusInternalServerError, errs[1].Error())
-		return
+		return nil, []error{
+			errors.New(fmt.Sprintf(" user {%s} build Course {%s} CRD in Classroom {%s} fail: %s",
+				req.User, req.CourseId, req.ClassroomId, errs[0].Error())),
+			errs[1],
+		}
+	}
+	if jobID != "" {
+		CRDDef.Name = jobID
 	}
 	CRDId := CRDDef.Name
 
+	// secrets used by environment variables must be in classroom namespace before job is created
+	envs, err := course.GetEnv(j.DB)
+	if err != nil {
+		return nil, []error{
+			errors.New(fmt.Sprintf("Query course env fail: %s", err.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_BUILDCRD_FMT, course.Name)),
+		}
+	}
+	if name, err := copyCourseSecrets(j.KClientSet, req.ClassroomId, envs); err != nil {
+		return nil, []error{
+			errors.New(fmt.Sprintf("copy secret {%s} to classroom {%s} fail: %s", name, req.ClassroomId, err.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_SECRET_FMT, course.Name, name, course.User)),
+		}
+	}
+
 	courseCRD, createErr := j.CourseCrdClient.NchcV1alpha1().Courses(req.ClassroomId).Create(
 		context.Background(), CRDDef, metav1.CreateOptions{})
 
 	if createErr != nil {
-		log.Errorf("create CRD fail: %s", createErr.Error())
-		RespondWithError(c, http.StatusInternalServerError, consts.ERROR_JOB_LAUNCH_RUNCRD_FMT, course.Name)
-		return
+		return nil, []error{
+			errors.New(fmt.Sprintf("create CRD fail: %s", createErr.Error())),
+			errors.New(fmt.Sprintf(consts.ERROR_JOB_LAUNCH_RUNCRD_FMT, course.Name)),
+		}
 	}
 
 	// Step 3: update Job Table
@@ -764,15 +1232,11 @@ func (j *Job) launchContainerJob(c *gin.Context, req *model.LaunchCourseRequest)
 		if delErr := j.CourseCrdClient.NchcV1alpha1().Courses(req.ClassroomId).
 			Delete(context.Background(), CRDId, metav1.DeleteOptions{PropagationPolicy: &deletePolicy}); delErr != nil {
 			errStrt2 := fmt.Sprintf("Delete CRD when insert new job fail: %s", delErr.Error())
-			log.Error(errStrt2)
-			RespondWithError(c, http.StatusInternalServerError, errStrt2)
-			return
+			return nil, []error{errors.New(errStrt2), errors.New(errStrt2)}
 		}
 		errStrt := fmt.Sprintf("Insert new job {id = %s} in Job table fail: %s, and CRD {%s} is also deleted",
 			courseCRD.Name, err.Error(), CRDId)
-		log.Errorf(errStrt)
-		RespondWithError(c, http.StatusInternalServerError, errStrt)
-		return
+		return nil, []error{errors.New(errStrt), errors.New(errStrt)}
 	}
 
 	// delete redis cache
@@ -788,7 +1252,7 @@ func (j *Job) launchContainerJob(c *gin.Context, req *model.LaunchCourseRequest)
 		},
 		OauthUser: db.OauthUser{
 			User:     req.User,
-			Provider: provider.(string),
+			Provider: provider,
 		},
 		CourseID:    req.CourseId,
 		ClassroomID: &req.ClassroomId,
@@ -798,20 +1262,10 @@ func (j *Job) launchContainerJob(c *gin.Context, req *model.LaunchCourseRequest)
 			courseCRD.Name, err.Error()))
 	}
 
-	// this goroutine should be also stop  when job is deleted (#71)
-	// ref: https://stackoverflow.com/questions/6807590/how-to-stop-a-goroutine
-	j.StopChanMap[newJob.ID] = make(chan string, 5)
-	j.StopChanMap[newJob.ID] <- ""
-	go CheckCourseCRDStatus(j.DB, j.redis, j.CourseCrdClient, req.ClassroomId, courseCRD.Name, j.StopChanMap[newJob.ID])
+	// CRD events may be handled before job is inserted, ask controller to sync job status again
+	j.statusCtrl.Enqueue(req.ClassroomId, courseCRD.Name)
 
-	c.JSON(http.StatusOK, model.LaunchCourseResponse{
-		Error: false,
-		Job: model.JobStatus{
-			JobId:  newJob.ID,
-			Ready:  false,
-			Status: "Created",
-		},
-	})
+	return &newJob, nil
 }
 
 func (j *Job) launchVMJob(c *gin.Context, req *model.LaunchCourseRequest) {
@@ -846,6 +1300,12 @@ func (j *Job) launchVMJob(c *gin.Context, req *model.LaunchCourseRequest) {
 		return
 	}
 
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+	j.invalidateJobList(req.User, provider.(string))
+
 	c.JSON(http.StatusOK, model.LaunchCourseResponse{
 		Error: false,
 		Job: model.JobStatus{
@@ -859,6 +1319,16 @@ func (j *Job) launchVMJob(c *gin.Context, req *model.LaunchCourseRequest) {
 func (j *Job) deleteVMJob(c *gin.Context, jobid string) {
 	token := c.GetHeader("Authorization")
 
+	// owner is needed to invalidate job list cache after vm is deleted
+	vmJob := rfstackmodel.Job{
+		Model: rfstackmodel.Model{
+			ID: jobid,
+		},
+	}
+	if err := j.DB.First(&vmJob).Error; err != nil {
+		log.Warningf("Query vm job {%s} fail: %s", jobid, err.Error())
+	}
+
 	launchVMResp := new(rfstackmodel.GenericResponse)
 	ErrResp := new(model.GenericResponse)
 
@@ -879,9 +1349,76 @@ func (j *Job) deleteVMJob(c *gin.Context, jobid string) {
 		RespondWithError(c, http.StatusInternalServerError, errStr)
 		return
 	}
+
+	if vmJob.User != "" {
+		j.invalidateJobList(vmJob.User, vmJob.Provider)
+	}
 	RespondWithOk(c, "Job {%s} is deleted successfully", jobid)
 }
 
+// listVMJob gets vm jobs of user from rfstack, returns nothing if vm course is disabled.
+func (j *Job) listVMJob(c *gin.Context, user string) ([]model.JobInfo, error) {
+	jobList := []model.JobInfo{}
+	if j.rfStackBase == nil {
+		return jobList, nil
+	}
+
+	listVMResp := new(rfstackmodel.JobListResponse)
+	ErrResp := new(model.GenericResponse)
+
+	_, err := j.rfStackBase.New().
+		Set("Authorization", c.GetHeader("Authorization")).
+		BodyJSON(&rfstackmodel.Job{OauthUser: rfstackmodel.OauthUser{User: user}}).
+		Post("/v1/job/list").Receive(listVMResp, ErrResp)
+	if err != nil {
+		return jobList, errors.New(fmt.Sprintf("Connect to rfstack fail: %s", err.Error()))
+	}
+
+	if ErrResp.Error == true {
+		return jobList, errors.New(fmt.Sprintf("rfstack list vm fail: %s", ErrResp.Message))
+	}
+
+	for _, vm := range listVMResp.Jobs {
+		service := []common.LabelValue{}
+		for _, s := range vm.Service {
+			service = append(service, common.LabelValue{Label: s.Label, Value: s.Value})
+		}
+
+		jobList = append(jobList, model.JobInfo{
+			Id:           vm.Id,
+			Type:         db.VM,
+			CourseID:     vm.CourseID,
+			StartAt:      vm.StartAt,
+			Status:       vmJobStatus(vm.Status),
+			Name:         vm.Name,
+			Introduction: vm.Introduction,
+			Image:        vm.Image.Label,
+			Level:        vm.Level,
+			CanSnapshot:  vm.CanSnapshot,
+			Service:      service,
+		})
+	}
+	return jobList, nil
+}
+
+// vmJobStatus maps rfstack job status to container job status, vm which is not running is regarded as pending.
+func vmJobStatus(status string) string {
+	switch status {
+	case JobStatusCreated, JobStatueReady:
+		return status
+	default:
+		return JobStatusPending
+	}
+}
+
+// invalidateJobList deletes cached job list of user.
+func (j *Job) invalidateJobList(user, provider string) {
+	redisKey := jobOwnerKey(user, provider)
+	if _, err := j.redis.JSONDel(redisKey, "."); err != nil {
+		log.Warningf("Delete cache key {%s} fail: %s", redisKey, err.Error())
+	}
+}
+
 func vmJobCount(db *gorm.DB, user string) (int, error) {
 	count := 0
 	if err := db.Model(&rfstackmodel.Job{}).Where("user = ?", user).Count(&count).Error; err != nil {
@@ -928,3 +1465,15 @@ func vmJobOwnByUser(job *rfstackmodel.Job, db *gorm.DB, user string, provider st
 
 	return true, nil
 }
+
+func markAuditDeleted(DB *gorm.DB, jobID string, deletedBy string) {
+	audit := db.Audit{
+		Model: db.Model{
+			ID: jobID,
+		},
+	}
+	DB.Model(&audit).Update("deleted_by", deletedBy)
+	if err := DB.Delete(audit).Error; err != nil {
+		log.Warningf(fmt.Sprintf("Failed to mark job {%s} deletion audit information : %s", audit.ID, err.Error()))
+	}
+}
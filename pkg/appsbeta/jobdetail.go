@@ -0,0 +1,364 @@
+package beta
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const defaultLogTailLines = 100
+
+// findJobForUser finds container job by id in url, and checks caller is job owner, classroom teacher or superuser.
+// Error response is written if job is not accessible.
+func (j *Job) findJobForUser(c *gin.Context) (*db.Job, bool) {
+	jobId := c.Param("id")
+
+	if jobId == "" {
+		RespondWithError(c, http.StatusBadRequest,
+			"Job Id is empty")
+		return nil, false
+	}
+
+	job := db.Job{
+		Model: db.Model{
+			ID: jobId,
+		},
+	}
+	if err := j.DB.First(&job).Error; err != nil {
+		errStr := fmt.Sprintf("find job {%s} fail: %s", jobId, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusBadRequest, errStr)
+		return nil, false
+	}
+
+	if !checkJobOwner(c, j.DB, &job) {
+		return nil, false
+	}
+
+	return &job, true
+}
+
+// @Summary Get detail of a container job
+// @Description Get Course CRD spec and status, pods, pod conditions and kubernetes events of a job.
+// @Tags Job
+// @Accept  json
+// @Produce  json
+// @Param id path string true "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Success 200 {object} docs.JobDetailResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/get/{id} [get]
+func (j *Job) Get(c *gin.Context) {
+	job, ok := j.findJobForUser(c)
+	if !ok {
+		return
+	}
+	ns := *job.ClassroomID
+
+	course, err := j.CourseCrdClient.NchcV1alpha1().Courses(ns).Get(context.Background(), job.ID, metav1.GetOptions{})
+	if err != nil {
+		errStr := fmt.Sprintf("Get Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	crdEvents, err := j.listEvents(ns, course.UID)
+	if err != nil {
+		errStr := fmt.Sprintf("List events of Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	pods, err := j.findJobPods(course)
+	if err != nil {
+		errStr := fmt.Sprintf("Find pods of Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	jobPods := []model.JobPod{}
+	for _, pod := range pods {
+		podEvents, err := j.listEvents(ns, pod.UID)
+		if err != nil {
+			log.Warningf("List events of pod {%s} fail: %s", pod.Name, err.Error())
+		}
+		jobPods = append(jobPods, model.JobPod{
+			Name:              pod.Name,
+			Phase:             pod.Status.Phase,
+			NodeName:          pod.Spec.NodeName,
+			Conditions:        pod.Status.Conditions,
+			ContainerStatuses: pod.Status.ContainerStatuses,
+			Events:            podEvents,
+		})
+	}
+
+	c.JSON(http.StatusOK, model.JobDetailResponse{
+		Error: false,
+		Job: model.JobDetail{
+			Id:          job.ID,
+			CourseID:    job.CourseID,
+			ClassroomID: ns,
+			User:        job.User,
+			StartAt:     job.CreatedAt,
+			Status:      job.Status,
+			Spec:        course.Spec,
+			CRDStatus:   course.Status,
+			Events:      crdEvents,
+			Pods:        jobPods,
+		},
+	})
+}
+
+// @Summary Get container logs of a job
+// @Description Get container logs of job pod in plain text. Set follow to stream logs until job is stopped or client disconnects.
+// @Tags Job
+// @Produce  plain
+// @Param id path string true "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param pod query string false "pod name, default is the first pod of job"
+// @Param container query string false "container name, default is the only container of pod"
+// @Param tail query int false "number of lines from the end of logs, default 100"
+// @Param follow query bool false "stream new logs"
+// @Success 200 {string} string "container logs"
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/logs/{id} [get]
+func (j *Job) Logs(c *gin.Context) {
+	job, ok := j.findJobForUser(c)
+	if !ok {
+		return
+	}
+	ns := *job.ClassroomID
+
+	tail := int64(defaultLogTailLines)
+	if v := c.Query("tail"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			RespondWithError(c, http.StatusBadRequest, "invalid tail {%s}", v)
+			return
+		}
+		tail = n
+	}
+	follow := c.Query("follow") == "true"
+
+	course, err := j.CourseCrdClient.NchcV1alpha1().Courses(ns).Get(context.Background(), job.ID, metav1.GetOptions{})
+	if err != nil {
+		errStr := fmt.Sprintf("Get Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	pods, err := j.findJobPods(course)
+	if err != nil {
+		errStr := fmt.Sprintf("Find pods of Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	// only pods belong to job are allowed
+	podName := c.Query("pod")
+	var pod *v1.Pod
+	for i := range pods {
+		if podName == "" || pods[i].Name == podName {
+			pod = &pods[i]
+			break
+		}
+	}
+	if pod == nil {
+		RespondWithError(c, http.StatusBadRequest, "pod {%s} of job {%s} is not found", podName, job.ID)
+		return
+	}
+
+	stream, err := j.KClientSet.CoreV1().Pods(ns).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container: c.Query("container"),
+		Follow:    follow,
+		TailLines: &tail,
+	}).Stream(c.Request.Context())
+	if err != nil {
+		errStr := fmt.Sprintf("Get logs of pod {%s} fail: %s", pod.Name, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 {
+			if _, werr := c.Writer.Write(buf[:n]); werr != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Warningf("read logs of pod {%s} fail: %s", pod.Name, err.Error())
+			}
+			return
+		}
+	}
+}
+
+// findJobPods returns pods created for Course CRD, by following owner references from CRD,
+// i.e. CRD -> Deployment -> ReplicaSet -> Pod.
+func (j *Job) findJobPods(course *v1alpha1.Course) ([]v1.Pod, error) {
+	ns := course.Namespace
+	owners := map[types.UID]bool{course.UID: true}
+
+	deployments, err := courseDeployments(j.KClientSet, course)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments {
+		owners[d.UID] = true
+	}
+
+	replicaSets, err := j.KClientSet.AppsV1().ReplicaSets(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range replicaSets.Items {
+		if isOwnedBy(rs.OwnerReferences, owners) {
+			owners[rs.UID] = true
+		}
+	}
+
+	pods, err := j.KClientSet.CoreV1().Pods(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	result := []v1.Pod{}
+	for _, pod := range pods.Items {
+		if isOwnedBy(pod.OwnerReferences, owners) {
+			result = append(result, pod)
+		}
+	}
+	return result, nil
+}
+
+// coursePodCount counts pods created for each Course CRD in namespace ns, keyed by uid of CRD.
+// Owner references are followed as findJobPods does, with one list of deployments, replica sets and pods for all CRDs.
+func coursePodCount(kclient kubernetes.Interface, ns string, courses []*v1alpha1.Course) (map[types.UID]int, error) {
+	// uid of owner -> uid of Course CRD it is created for
+	roots := map[types.UID]types.UID{}
+	for _, course := range courses {
+		roots[course.UID] = course.UID
+	}
+
+	deployments, err := kclient.AppsV1().Deployments(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		if root, ok := ownerRoot(d.OwnerReferences, roots); ok {
+			roots[d.UID] = root
+		}
+	}
+
+	replicaSets, err := kclient.AppsV1().ReplicaSets(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, rs := range replicaSets.Items {
+		if root, ok := ownerRoot(rs.OwnerReferences, roots); ok {
+			roots[rs.UID] = root
+		}
+	}
+
+	pods, err := kclient.CoreV1().Pods(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	count := map[types.UID]int{}
+	for _, pod := range pods.Items {
+		if root, ok := ownerRoot(pod.OwnerReferences, roots); ok {
+			count[root]++
+		}
+	}
+	return count, nil
+}
+
+func ownerRoot(refs []metav1.OwnerReference, roots map[types.UID]types.UID) (types.UID, bool) {
+	for _, ref := range refs {
+		if root, ok := roots[ref.UID]; ok {
+			return root, true
+		}
+	}
+	return "", false
+}
+
+// courseDeployments returns deployments created by course controller for Course CRD.
+func courseDeployments(kclient kubernetes.Interface, course *v1alpha1.Course) ([]appsv1.Deployment, error) {
+	deployments, err := kclient.AppsV1().Deployments(course.Namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	owners := map[types.UID]bool{course.UID: true}
+	result := []appsv1.Deployment{}
+	for _, d := range deployments.Items {
+		if isOwnedBy(d.OwnerReferences, owners) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func isOwnedBy(refs []metav1.OwnerReference, owners map[types.UID]bool) bool {
+	for _, ref := range refs {
+		if owners[ref.UID] {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *Job) listEvents(ns string, uid types.UID) ([]model.KubernetesEvent, error) {
+	events, err := j.KClientSet.CoreV1().Events(ns).List(context.Background(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.uid", string(uid)).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []model.KubernetesEvent{}
+	for _, e := range events.Items {
+		result = append(result, model.KubernetesEvent{
+			Type:          e.Type,
+			Reason:        e.Reason,
+			Message:       e.Message,
+			Count:         e.Count,
+			LastTimestamp: e.LastTimestamp.Time,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package beta
+
+import (
+	"testing"
+
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ownedBy(name, ns string, uid, owner types.UID) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       ns,
+		UID:             uid,
+		OwnerReferences: []metav1.OwnerReference{{Name: "owner", UID: owner}},
+	}
+}
+
+func TestCoursePodCount(t *testing.T) {
+	ns := "classroom-1"
+	running := &v1alpha1.Course{ObjectMeta: metav1.ObjectMeta{Name: "job-1", Namespace: ns, UID: "course-1"}}
+	creating := &v1alpha1.Course{ObjectMeta: metav1.ObjectMeta{Name: "job-2", Namespace: ns, UID: "course-2"}}
+	waiting := &v1alpha1.Course{ObjectMeta: metav1.ObjectMeta{Name: "job-3", Namespace: ns, UID: "course-3"}}
+
+	kclient := fake.NewSimpleClientset(
+		// job-1 has two pods
+		&appsv1.Deployment{ObjectMeta: ownedBy("d1", ns, "d1", "course-1")},
+		&appsv1.ReplicaSet{ObjectMeta: ownedBy("rs1", ns, "rs1", "d1")},
+		&v1.Pod{ObjectMeta: ownedBy("p1", ns, "p1", "rs1")},
+		&v1.Pod{ObjectMeta: ownedBy("p2", ns, "p2", "rs1")},
+		// job-2 has deployment but no pod yet
+		&appsv1.Deployment{ObjectMeta: ownedBy("d2", ns, "d2", "course-2")},
+		// pod of other owner, and pod of job-1 in other namespace, are not counted
+		&v1.Pod{ObjectMeta: ownedBy("p3", ns, "p3", "other")},
+		&v1.Pod{ObjectMeta: ownedBy("p4", "classroom-2", "p4", "rs1")},
+	)
+
+	count, err := coursePodCount(kclient, ns, []*v1alpha1.Course{running, creating, waiting})
+	assert.NoError(t, err)
+	assert.Equal(t, map[types.UID]int{"course-1": 2}, count)
+}
@@ -0,0 +1,441 @@
+package beta
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	defaultMaxDownload = int64(1024) // MiB
+	defaultMaxUpload   = int64(100)  // MiB
+	// room for multipart headers around uploaded file
+	uploadFormOverhead = int64(1 << 20)
+)
+
+var errFileTooLarge = errors.New("file size exceeds limit")
+
+// @Summary Download files in workspace of container job
+// @Description Stream a tar or zip archive of path under writable mount point of job, by exec-ing tar in job pod.
+// @Description Size of path is checked with du before download.
+// @Tags Job
+// @Produce  application/x-tar
+// @Produce  application/zip
+// @Param id path string true "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param path query string false "path relative to writable mount point, default is whole workspace"
+// @Param format query string false "tar or zip, default tar"
+// @Param pod query string false "pod name, default is the first running pod of job"
+// @Param container query string false "container name, default is the only container of pod"
+// @Success 200 {file} file "archive of path"
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/download/{id} [get]
+func (j *Job) Download(c *gin.Context) {
+	format := c.DefaultQuery("format", "tar")
+	if format != "tar" && format != "zip" {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_FORMAT_FMT, format)
+		return
+	}
+
+	job, pod, mountPoint, rel, ok := j.findJobWorkspace(c)
+	if !ok {
+		return
+	}
+	container := c.Query("container")
+
+	limit := j.config.APIConfig.Workspace.MaxDownload
+	if limit <= 0 {
+		limit = defaultMaxDownload
+	}
+
+	// du reports KiB, which is good enough to reject large download before any byte is sent
+	var stdout, stderr bytes.Buffer
+	if err := j.execInPod(c.Request.Context(), pod, container,
+		[]string{"du", "-sk", path.Join(mountPoint, rel)}, nil, &stdout, &stderr); err != nil {
+		log.Errorf("check size of {%s} in job {%s} fail: %s, %s", rel, job.ID, err.Error(), stderr.String())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_NOTFOUND_FMT, rel)
+		return
+	}
+	fields := strings.Fields(stdout.String())
+	if len(fields) == 0 {
+		RespondWithError(c, http.StatusInternalServerError, "unexpected output of du: %s", stdout.String())
+		return
+	}
+	sizeKiB, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		RespondWithError(c, http.StatusInternalServerError, "unexpected output of du: %s", stdout.String())
+		return
+	}
+	if sizeKiB > limit*1024 {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_SIZE_FMT, sizeKiB/1024, limit)
+		return
+	}
+
+	name := path.Base(rel)
+	if rel == "." {
+		name = path.Base(mountPoint)
+	}
+	contentType := "application/x-tar"
+	if format == "zip" {
+		contentType = "application/zip"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	c.Status(http.StatusOK)
+
+	// tar is streamed from pod, and converted to zip on the fly if requested
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		stderr := bytes.Buffer{}
+		err := j.execInPod(ctx, pod, container,
+			[]string{"tar", "cf", "-", "-C", mountPoint, "./" + rel}, nil, pw, &stderr)
+		if err != nil {
+			err = fmt.Errorf("%s: %s", err.Error(), stderr.String())
+		}
+		pw.CloseWithError(err)
+	}()
+
+	out := &limitedWriter{w: c.Writer, remain: limit << 20}
+	if format == "zip" {
+		err = tarToZip(pr, out)
+	} else {
+		_, err = io.Copy(out, pr)
+	}
+	pr.CloseWithError(err)
+	if err != nil {
+		// header is sent, client sees truncated archive
+		log.Errorf("download {%s} of job {%s} fail: %s", rel, job.ID, err.Error())
+		return
+	}
+	log.Infof("{%s} of job {%s} is downloaded as %s", rel, job.ID, format)
+}
+
+// @Summary Upload files into workspace of container job
+// @Description Upload a file into directory under writable mount point of job. Tar, tar.gz and zip archives are extracted,
+// @Description other files are copied as is. Uncompressed size is limited, and only regular files and directories are allowed in archive.
+// @Tags Job
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param id path string true "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param path query string false "directory relative to writable mount point, default is mount point"
+// @Param pod query string false "pod name, default is the first running pod of job"
+// @Param container query string false "container name, default is the only container of pod"
+// @Param file formData file true "file or archive to upload"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/upload/{id} [post]
+func (j *Job) Upload(c *gin.Context) {
+	limit := j.config.APIConfig.Workspace.MaxUpload
+	if limit <= 0 {
+		limit = defaultMaxUpload
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit<<20+uploadFormOverhead)
+
+	job, pod, mountPoint, rel, ok := j.findJobWorkspace(c)
+	if !ok {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		log.Errorf("read upload file of job {%s} fail: %s", job.ID, err.Error())
+		RespondWithError(c, http.StatusBadRequest, "read upload file fail: %s", err.Error())
+		return
+	}
+	defer file.Close()
+
+	// validate whole archive before anything is extracted in pod
+	count, total, err := writeUploadTar(file, header, io.Discard, limit<<20)
+	if err == errFileTooLarge {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_SIZE_FMT, total>>20, limit)
+		return
+	}
+	if err != nil {
+		log.Errorf("invalid upload file {%s} of job {%s}: %s", header.Filename, job.ID, err.Error())
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_ARCHIVE_FMT, header.Filename, err.Error())
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		RespondWithError(c, http.StatusInternalServerError, "read upload file fail: %s", err.Error())
+		return
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, _, err := writeUploadTar(file, header, pw, limit<<20)
+		pw.CloseWithError(err)
+	}()
+
+	dir := path.Join(mountPoint, rel)
+	var stderr bytes.Buffer
+	err = j.execInPod(c.Request.Context(), pod, c.Query("container"),
+		[]string{"sh", "-c", `mkdir -p "$1" && tar xf - -C "$1"`, "sh", dir}, pr, io.Discard, &stderr)
+	pr.Close()
+	if err != nil {
+		errStr := fmt.Sprintf("extract {%s} into {%s} of job {%s} fail: %s, %s",
+			header.Filename, rel, job.ID, err.Error(), stderr.String())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	log.Infof("%d files of {%s} are uploaded into {%s} of job {%s}", count, header.Filename, rel, job.ID)
+	RespondWithOk(c, "%d files are uploaded into {%s}", count, rel)
+}
+
+// findJobWorkspace finds job in path and its running pod, checks job owner, and resolves path query under
+// writable mount point of course. Error response is written if any of them is not found.
+func (j *Job) findJobWorkspace(c *gin.Context) (*db.Job, *v1.Pod, string, string, bool) {
+	job, ok := j.findJobForUser(c)
+	if !ok {
+		return nil, nil, "", "", false
+	}
+
+	course, err := db.GetCourse(j.DB, job.CourseID)
+	if err != nil {
+		errStr := fmt.Sprintf("Query course {%s} fail: %s", job.CourseID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return nil, nil, "", "", false
+	}
+	if course.WritablePath == nil || *course.WritablePath == "" {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_NOPATH_FMT, course.Name)
+		return nil, nil, "", "", false
+	}
+
+	rel, err := workspaceRelPath(c.Query("path"))
+	if err != nil {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_JOB_FILE_PATH_FMT, c.Query("path"))
+		return nil, nil, "", "", false
+	}
+
+	pod, ok := j.runningJobPod(c, job)
+	if !ok {
+		return nil, nil, "", "", false
+	}
+
+	return job, pod, path.Clean(*course.WritablePath), rel, true
+}
+
+func (j *Job) execInPod(ctx context.Context, pod *v1.Pod, container string, command []string,
+	stdin io.Reader, stdout, stderr io.Writer) error {
+	executor, err := j.podExecutor(pod, container, command, stdin != nil, false)
+	if err != nil {
+		return err
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// workspaceRelPath cleans path relative to mount point, path escaping mount point is rejected.
+func workspaceRelPath(p string) (string, error) {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return ".", nil
+	}
+	rel := path.Clean(p)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("path {%s} is out of workspace", p)
+	}
+	return rel, nil
+}
+
+// writeUploadTar writes uploaded file to w as tar stream, archives are converted entry by entry.
+// Number of files and uncompressed size are returned. errFileTooLarge is returned once size exceeds limit.
+func writeUploadTar(file multipart.File, header *multipart.FileHeader, w io.Writer, limit int64) (int, int64, error) {
+	tw := tar.NewWriter(w)
+	count, total := 0, int64(0)
+
+	add := func(name string, mode int64, size int64, isDir bool, r io.Reader) error {
+		name = strings.TrimPrefix(name, "./")
+		rel, err := workspaceRelPath(name)
+		if err == nil && rel == "." && isDir {
+			// root of archive is target directory itself
+			return nil
+		}
+		if err != nil || rel == "." || path.IsAbs(name) {
+			return fmt.Errorf("entry {%s} is out of target directory", name)
+		}
+		if isDir {
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: rel + "/", Mode: mode & 0777})
+		}
+
+		total += size
+		if total > limit {
+			return errFileTooLarge
+		}
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: rel, Mode: mode & 0777, Size: size}); err != nil {
+			return err
+		}
+		count++
+		if _, err := io.CopyN(tw, r, size); err != nil {
+			return err
+		}
+		// size declared in zip may be a lie, content beyond it is never counted in limit
+		if n, err := r.Read(make([]byte, 1)); n > 0 || (err != nil && err != io.EOF) {
+			return fmt.Errorf("entry {%s} does not match its declared size", name)
+		}
+		return nil
+	}
+
+	name := strings.ToLower(header.Filename)
+	var err error
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		err = zipEntries(file, header.Size, add)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		gz, gzErr := gzip.NewReader(file)
+		if gzErr != nil {
+			return 0, 0, gzErr
+		}
+		err = tarEntries(gz, add)
+	case strings.HasSuffix(name, ".tar"):
+		err = tarEntries(file, add)
+	default:
+		err = add(path.Base(header.Filename), 0644, header.Size, false, file)
+	}
+	if err != nil {
+		return count, total, err
+	}
+	return count, total, tw.Close()
+}
+
+type addEntry func(name string, mode int64, size int64, isDir bool, r io.Reader) error
+
+func tarEntries(r io.Reader, add addEntry) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = add(h.Name, h.Mode, 0, true, nil)
+		case tar.TypeReg:
+			err = add(h.Name, h.Mode, h.Size, false, tr)
+		default:
+			err = fmt.Errorf("entry {%s} is neither regular file nor directory", h.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func zipEntries(r io.ReaderAt, size int64, add addEntry) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = add(f.Name, int64(mode.Perm()), 0, true, nil)
+		case mode.IsRegular():
+			rc, openErr := f.Open()
+			if openErr != nil {
+				return openErr
+			}
+			err = add(f.Name, int64(mode.Perm()), int64(f.UncompressedSize64), false, rc)
+			rc.Close()
+		default:
+			err = fmt.Errorf("entry {%s} is neither regular file nor directory", f.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tarToZip converts tar stream to zip, only regular files and directories under root of archive are kept.
+func tarToZip(r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	zw := zip.NewWriter(w)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return zw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg && h.Typeflag != tar.TypeDir {
+			continue
+		}
+
+		fh, err := zip.FileInfoHeader(h.FileInfo())
+		if err != nil {
+			return err
+		}
+		rel, err := workspaceRelPath(h.Name)
+		if err != nil || rel == "." || path.IsAbs(h.Name) {
+			continue
+		}
+		fh.Name = rel
+		if h.Typeflag == tar.TypeDir {
+			fh.Name = rel + "/"
+		} else {
+			fh.Method = zip.Deflate
+		}
+
+		fw, err := zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+		if h.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(fw, tr); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// limitedWriter fails with errFileTooLarge once more than remain bytes are written.
+type limitedWriter struct {
+	w      io.Writer
+	remain int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remain {
+		return 0, errFileTooLarge
+	}
+	n, err := l.w.Write(p)
+	l.remain -= int64(n)
+	return n, err
+}
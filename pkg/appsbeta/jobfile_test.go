@@ -0,0 +1,318 @@
+package beta
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"io"
+	"mime/multipart"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// uploadFile is multipart.File of uploaded content kept in memory.
+type uploadFile struct {
+	*bytes.Reader
+}
+
+func (uploadFile) Close() error {
+	return nil
+}
+
+func newUpload(name string, data []byte) (multipart.File, *multipart.FileHeader) {
+	return uploadFile{bytes.NewReader(data)}, &multipart.FileHeader{Filename: name, Size: int64(len(data))}
+}
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	link     string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) []byte {
+	buf := bytes.Buffer{}
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0644, Size: int64(len(e.body)), Linkname: e.link}
+		if e.typeflag != tar.TypeReg {
+			h.Size = 0
+		}
+		assert.NoError(t, tw.WriteHeader(h))
+		if e.typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.body))
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+type zipEntry struct {
+	name string
+	mode os.FileMode
+	body string
+}
+
+func buildZip(t *testing.T, entries []zipEntry) []byte {
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		fh := &zip.FileHeader{Name: e.name, Method: zip.Store}
+		fh.SetMode(e.mode)
+		w, err := zw.CreateHeader(fh)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(e.body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// buildLyingZip builds zip of a stored file whose declared uncompressed size is not size of its content.
+func buildLyingZip(t *testing.T, name, body string, declared uint64) []byte {
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	fh := &zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(body)),
+		CompressedSize64:   uint64(len(body)),
+		UncompressedSize64: declared,
+	}
+	fh.SetMode(0644)
+	w, err := zw.CreateRaw(fh)
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(body))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// readTar returns content of regular files and directories in tar stream, directory content is "/".
+func readTar(t *testing.T, data []byte) map[string]string {
+	result := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return result
+		}
+		assert.NoError(t, err)
+		if h.Typeflag == tar.TypeDir {
+			result[h.Name] = "/"
+			continue
+		}
+		body, err := io.ReadAll(tr)
+		assert.NoError(t, err)
+		result[h.Name] = string(body)
+	}
+}
+
+func TestWorkspaceRelPath(t *testing.T) {
+	for _, tc := range []struct {
+		path     string
+		expected string
+		invalid  bool
+	}{
+		{path: "", expected: "."},
+		{path: "/", expected: "."},
+		{path: "data", expected: "data"},
+		{path: "/data/train/", expected: "data/train"},
+		{path: "./data/../model", expected: "model"},
+		{path: "data/../../etc", invalid: true},
+		{path: "..", invalid: true},
+		{path: "../etc/passwd", invalid: true},
+		{path: "/../etc/passwd", invalid: true},
+		{path: "..data", expected: "..data"},
+	} {
+		rel, err := workspaceRelPath(tc.path)
+		if tc.invalid {
+			assert.Error(t, err, tc.path)
+			continue
+		}
+		assert.NoError(t, err, tc.path)
+		assert.Equal(t, tc.expected, rel, tc.path)
+	}
+}
+
+func TestWriteUploadTar(t *testing.T) {
+	zipData := buildZip(t, []zipEntry{
+		{name: "data/", mode: os.ModeDir | 0755},
+		{name: "data/a.txt", mode: 0644, body: "hello"},
+	})
+
+	for _, tc := range []struct {
+		name     string
+		filename string
+		data     []byte
+		limit    int64
+		expected map[string]string
+		count    int
+		total    int64
+		fail     bool
+		tooLarge bool
+	}{
+		{
+			name: "plain file", filename: "sub/notes.txt", data: []byte("abc"), limit: 3,
+			expected: map[string]string{"notes.txt": "abc"}, count: 1, total: 3,
+		},
+		{
+			name: "plain file over limit", filename: "notes.txt", data: []byte("abcd"), limit: 3,
+			fail: true, tooLarge: true,
+		},
+		{
+			name: "tar", filename: "data.tar", limit: 6,
+			data: buildTar(t, []tarEntry{
+				{name: "./", typeflag: tar.TypeDir},
+				{name: "./dir/", typeflag: tar.TypeDir},
+				{name: "./dir/a", typeflag: tar.TypeReg, body: "abc"},
+				{name: "b", typeflag: tar.TypeReg, body: "def"},
+			}),
+			expected: map[string]string{"dir/": "/", "dir/a": "abc", "b": "def"}, count: 2, total: 6,
+		},
+		{
+			name: "tar over limit by one byte", filename: "data.tar", limit: 5,
+			data: buildTar(t, []tarEntry{
+				{name: "a", typeflag: tar.TypeReg, body: "abc"},
+				{name: "b", typeflag: tar.TypeReg, body: "def"},
+			}),
+			fail: true, tooLarge: true,
+		},
+		{
+			name: "tar parent entry", filename: "data.tar", limit: 100,
+			data: buildTar(t, []tarEntry{{name: "../evil", typeflag: tar.TypeReg, body: "x"}}),
+			fail: true,
+		},
+		{
+			name: "tar nested parent entry", filename: "data.tar", limit: 100,
+			data: buildTar(t, []tarEntry{{name: "dir/../../evil", typeflag: tar.TypeReg, body: "x"}}),
+			fail: true,
+		},
+		{
+			name: "tar absolute entry", filename: "data.tar", limit: 100,
+			data: buildTar(t, []tarEntry{{name: "/etc/passwd", typeflag: tar.TypeReg, body: "x"}}),
+			fail: true,
+		},
+		{
+			name: "tar symlink entry", filename: "data.tar", limit: 100,
+			data: buildTar(t, []tarEntry{{name: "link", typeflag: tar.TypeSymlink, link: "/etc/passwd"}}),
+			fail: true,
+		},
+		{
+			name: "tar hardlink entry", filename: "data.tar", limit: 100,
+			data: buildTar(t, []tarEntry{{name: "link", typeflag: tar.TypeLink, link: "/etc/passwd"}}),
+			fail: true,
+		},
+		{
+			name: "zip", filename: "data.ZIP", data: zipData, limit: 5,
+			expected: map[string]string{"data/": "/", "data/a.txt": "hello"}, count: 1, total: 5,
+		},
+		{
+			name: "zip over limit by one byte", filename: "data.zip", data: zipData, limit: 4,
+			fail: true, tooLarge: true,
+		},
+		{
+			name: "zip parent entry", filename: "data.zip", limit: 100,
+			data: buildZip(t, []zipEntry{{name: "../evil", mode: 0644, body: "x"}}),
+			fail: true,
+		},
+		{
+			name: "zip absolute entry", filename: "data.zip", limit: 100,
+			data: buildZip(t, []zipEntry{{name: "/etc/passwd", mode: 0644, body: "x"}}),
+			fail: true,
+		},
+		{
+			name: "zip symlink entry", filename: "data.zip", limit: 100,
+			data: buildZip(t, []zipEntry{{name: "link", mode: os.ModeSymlink | 0777, body: "/etc/passwd"}}),
+			fail: true,
+		},
+		{
+			name: "zip declares larger size than content", filename: "data.zip", limit: 100,
+			data: buildLyingZip(t, "a.txt", "hello", 9),
+			fail: true,
+		},
+		{
+			// content beyond declared size would bypass limit if it were copied
+			name: "zip declares smaller size than content", filename: "data.zip", limit: 4,
+			data: buildLyingZip(t, "a.txt", "hello", 2),
+			fail: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			file, header := newUpload(tc.filename, tc.data)
+			out := bytes.Buffer{}
+			count, total, err := writeUploadTar(file, header, &out, tc.limit)
+			if tc.fail {
+				assert.Error(t, err)
+				if tc.tooLarge {
+					assert.Equal(t, errFileTooLarge, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.count, count)
+			assert.Equal(t, tc.total, total)
+			assert.Equal(t, tc.expected, readTar(t, out.Bytes()))
+		})
+	}
+}
+
+func TestTarToZip(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		entries  []tarEntry
+		expected map[string]string
+	}{
+		{
+			name: "files and directories",
+			entries: []tarEntry{
+				{name: "./", typeflag: tar.TypeDir},
+				{name: "./dir/", typeflag: tar.TypeDir},
+				{name: "./dir/a", typeflag: tar.TypeReg, body: "abc"},
+				{name: "b", typeflag: tar.TypeReg, body: ""},
+			},
+			expected: map[string]string{"dir/": "", "dir/a": "abc", "b": ""},
+		},
+		{
+			name: "links are dropped",
+			entries: []tarEntry{
+				{name: "a", typeflag: tar.TypeReg, body: "abc"},
+				{name: "soft", typeflag: tar.TypeSymlink, link: "/etc/passwd"},
+				{name: "hard", typeflag: tar.TypeLink, link: "a"},
+			},
+			expected: map[string]string{"a": "abc"},
+		},
+		{
+			name: "entries out of archive root are dropped",
+			entries: []tarEntry{
+				{name: "../evil", typeflag: tar.TypeReg, body: "x"},
+				{name: "dir/../../evil", typeflag: tar.TypeReg, body: "x"},
+				{name: "/etc/passwd", typeflag: tar.TypeReg, body: "x"},
+				{name: "ok", typeflag: tar.TypeReg, body: "y"},
+			},
+			expected: map[string]string{"ok": "y"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			assert.NoError(t, tarToZip(bytes.NewReader(buildTar(t, tc.entries)), &out))
+
+			zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+			assert.NoError(t, err)
+			result := map[string]string{}
+			for _, f := range zr.File {
+				rc, err := f.Open()
+				assert.NoError(t, err)
+				body, err := io.ReadAll(rc)
+				assert.NoError(t, err)
+				rc.Close()
+				result[f.Name] = string(body)
+			}
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
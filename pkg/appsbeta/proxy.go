@@ -151,7 +151,7 @@ func (p *Proxy) Introspection(c *gin.Context) {
 // @Success 200 {object} docs.PlainResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/proxy/logout [post]
@@ -178,7 +178,8 @@ func (p *Proxy) Logout(c *gin.Context) {
 }
 
 // @Summary Register a new user
-// @Description Register a new user
+// @Description Register a new user. Only authenticated superuser can register teacher or superuser when secure api is enabled,
+// @Description otherwise new user is registered as student.
 // @Tags Proxy
 // @Accept  json
 // @Produce  json
@@ -215,12 +216,20 @@ func (p *Proxy) RegisterUser(c *gin.Context) {
 	}
 
 	// empty string will create student role
-	if !(req.Role == "student" || req.Role == "teacher" || req.Role == "superuser" || req.Role == "") {
+	if !(req.Role == db.ROLE_STUDENT || req.Role == db.ROLE_TEACHER || req.Role == db.ROLE_SUPERUSER || req.Role == "") {
 		log.Errorf("{%s} is not valid role string", req.Role)
 		RespondWithError(c, http.StatusBadRequest, "{%s} is not valid role string", req.Role)
 		return
 	}
 
+	// self-registration must not grant teacher or superuser
+	if req.Role != db.ROLE_STUDENT && req.Role != "" && p.config.APIConfig.EnableSecureAPI {
+		if loginUser, ok := getLoginUser(c); !ok || !loginUser.IsSuperuser() {
+			log.Warningf("register user {%s} with role {%s} by non-superuser, role is changed to student", req.Username, req.Role)
+			req.Role = db.ROLE_STUDENT
+		}
+	}
+
 	registerResult, err := p.provider.RegisterUser(&req)
 
 	if err != nil && !provider_err.IsNotSupport(err) {
@@ -258,7 +267,7 @@ func (p *Proxy) RegisterUser(c *gin.Context) {
 // @Success 200 {object} docs.PlainResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/proxy/update [post]
@@ -275,7 +284,7 @@ func (p *Proxy) UpdateUserBasicInfo(c *gin.Context) {
 // @Success 200 {object} docs.PlainResponse
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/proxy/changePW [post]
@@ -291,7 +300,7 @@ func (p *Proxy) ChangeUserPassword(c *gin.Context) {
 // @Success 200 {object} docs.UserInfo
 // @Failure 400 {object} docs.GenericErrorResponse
 // @Failure 401 {object} docs.GenericErrorResponse
-// @Failure 403 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
 // @Failure 500 {object} docs.GenericErrorResponse
 // @Security ApiKeyAuth
 // @Router /beta/proxy/query [get]
@@ -349,12 +358,21 @@ func updateUser(p *Proxy, c *gin.Context) {
 		return
 	}
 
-	if !(req.Role == "student" || req.Role == "teacher" || req.Role == "superuser" || req.Role == "") {
+	if !(req.Role == db.ROLE_STUDENT || req.Role == db.ROLE_TEACHER || req.Role == db.ROLE_SUPERUSER || req.Role == "") {
 		log.Errorf("{%s} is not valid role string", req.Role)
 		RespondWithError(c, http.StatusBadRequest, "{%s} is not valid role string", req.Role)
 		return
 	}
 
+	// only superuser can update other user or change role
+	if loginUser, ok := getLoginUser(c); ok && !loginUser.IsSuperuser() {
+		if req.Username != loginUser.User || (req.Role != "" && req.Role != loginUser.Role) {
+			log.Errorf("user {%s} is not allowed to update user {%s} with role {%s}", loginUser.User, req.Username, req.Role)
+			RespondWithForbidden(c, consts.FORBIDDEN_USER_SELF, consts.ERROR_FORBIDDEN_USER_SELF_FMT, loginUser.User)
+			return
+		}
+	}
+
 	result, err := p.provider.UpdateUser(&req)
 
 	if err != nil && !provider_err.IsNotSupport(err) {
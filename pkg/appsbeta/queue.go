@@ -0,0 +1,486 @@
+package beta
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	queueInterval      = 30 * time.Second
+	defaultGpuResource = "nvidia.com/gpu"
+	// job launched within admission window may have no pod yet, its GPU is counted as used until pod is created
+	admissionWindow = 5 * time.Minute
+)
+
+// LaunchQueue holds GPU job launch requests which can not be satisfied by free GPU on nodes,
+// and launches them in order of queue policy when GPU is released.
+// Launch requests are persisted in database, so queue survives api server restart.
+type LaunchQueue struct {
+	DB         *gorm.DB
+	KClientSet *kubernetes.Clientset
+	config     *config.Config
+	job        *Job
+	// only one admission decision at a time, so requests launched directly never skip the queue
+	mu sync.Mutex
+	// last published position of queued request, only accessed with mu held
+	positions map[string]int
+	// GPU of requests admitted directly whose jobs are not created yet, only accessed with mu held
+	launching int64
+}
+
+func NewLaunchQueue(DB *gorm.DB, kclient *kubernetes.Clientset, config *config.Config, job *Job) *LaunchQueue {
+	return &LaunchQueue{
+		DB:         DB,
+		KClientSet: kclient,
+		config:     config,
+		job:        job,
+		positions:  make(map[string]int),
+	}
+}
+
+func (q *LaunchQueue) enabled() bool {
+	return q.config.APIConfig.Queue.Enable
+}
+
+func (q *LaunchQueue) policy() string {
+	if q.config.APIConfig.Queue.Policy == db.QUEUE_POLICY_PRIORITY {
+		return db.QUEUE_POLICY_PRIORITY
+	}
+	return db.QUEUE_POLICY_FIFO
+}
+
+func (q *LaunchQueue) gpuResource() v1.ResourceName {
+	return gpuResourceName(q.config)
+}
+
+// gpuResourceName is extended resource name of GPU on nodes, which is also used in GPU reservation.
+func gpuResourceName(config *config.Config) v1.ResourceName {
+	if config.APIConfig.Queue.GpuResource == "" {
+		return defaultGpuResource
+	}
+	return v1.ResourceName(config.APIConfig.Queue.GpuResource)
+}
+
+// Run launches queued requests every queueInterval until stopCh is closed.
+func (q *LaunchQueue) Run(stopCh <-chan struct{}) {
+	if !q.enabled() {
+		log.Info("Launch queue is disabled")
+		return
+	}
+	log.Infof("Launch queue is started with %s policy", q.policy())
+	wait.Until(q.dispatch, queueInterval, stopCh)
+	log.Info("Launch queue is stopped")
+}
+
+// admitOrEnqueue decides whether GPU job in request can be launched now. If not, request is queued,
+// and queued request is returned. Request is launched directly only when queue is empty and free GPU is enough.
+func (q *LaunchQueue) admitOrEnqueue(req *model.LaunchCourseRequest, provider string, gpu int32) (*db.QueuedJob, int, error) {
+	if !q.enabled() || gpu <= 0 {
+		return nil, 0, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue, err := db.ListQueue(q.DB, q.policy())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(queue) == 0 {
+		free, err := q.freeGpu(req.ClassroomId)
+		if err != nil {
+			return nil, 0, err
+		}
+		if free >= int64(gpu) {
+			// counted until caller creates job and calls launched
+			q.launching += int64(gpu)
+			return nil, 0, nil
+		}
+	}
+
+	entry := db.QueuedJob{
+		Model: db.Model{
+			ID: uuid.New().String(),
+		},
+		OauthUser: db.OauthUser{
+			User:     req.User,
+			Provider: provider,
+		},
+		CourseID:    req.CourseId,
+		ClassroomID: req.ClassroomId,
+		Gpu:         gpu,
+		Priority:    q.priority(req.User, provider),
+	}
+	if err := entry.NewEntry(q.DB); err != nil {
+		return nil, 0, err
+	}
+	q.job.invalidateJobList(req.User, provider)
+
+	positions, err := db.QueuePositions(q.DB, q.policy())
+	if err != nil {
+		return nil, 0, err
+	}
+	q.positions[entry.ID] = positions[entry.ID]
+
+	log.Infof("launch course {%s} of user {%s} is queued at %d for %d gpu", req.CourseId, req.User, positions[entry.ID], gpu)
+	return &entry, positions[entry.ID], nil
+}
+
+// launched must be called after job admitted directly by admitOrEnqueue is created or failed to create.
+func (q *LaunchQueue) launched(gpu int32) {
+	if !q.enabled() || gpu <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.launching -= int64(gpu)
+}
+
+// priority puts teachers and superusers ahead of students in priority policy.
+func (q *LaunchQueue) priority(user, provider string) int {
+	u := db.User{
+		User:     user,
+		Provider: util.StringPtr(provider),
+	}
+	role, err := u.GetRole(q.DB)
+	if err != nil {
+		return db.QUEUE_PRIORITY_STUDENT
+	}
+	if role == db.ROLE_TEACHER || role == db.ROLE_SUPERUSER {
+		return db.QUEUE_PRIORITY_TEACHER
+	}
+	return db.QUEUE_PRIORITY_STUDENT
+}
+
+// dispatch launches queued requests in order while free GPU is enough, GPU reserved by other classrooms is not used.
+// Head of queue is never skipped by smaller requests behind it, so large requests do not starve.
+func (q *LaunchQueue) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue, err := db.ListQueue(q.DB, q.policy())
+	if err != nil {
+		log.Warningf("query launch queue fail: %s", err.Error())
+		return
+	}
+	if len(queue) == 0 {
+		return
+	}
+
+	total, used, err := clusterGpu(q.KClientSet, q.gpuResource())
+	if err != nil {
+		log.Warningf("query free gpu fail: %s", err.Error())
+		return
+	}
+	unused, err := unusedReservations(q.DB, time.Now())
+	if err != nil {
+		log.Warningf("query reservations fail: %s", err.Error())
+		return
+	}
+	admitted, err := q.admittedGpu()
+	if err != nil {
+		log.Warningf("query gpu of launching jobs fail: %s", err.Error())
+		return
+	}
+	free := total - used - admitted
+
+	launched := 0
+	for _, entry := range queue {
+		gpu := int64(entry.Gpu)
+		if gpu > free-reservedForOthers(unused, entry.ClassroomID) {
+			break
+		}
+		free -= gpu
+		// job consumes reservation of its own classroom first
+		if unused[entry.ClassroomID] > 0 {
+			unused[entry.ClassroomID] -= gpu
+			if unused[entry.ClassroomID] < 0 {
+				unused[entry.ClassroomID] = 0
+			}
+		}
+		launched++
+		q.launch(entry)
+	}
+
+	// notify owners whose position is changed
+	for i, entry := range queue[launched:] {
+		position := i + 1
+		if q.positions[entry.ID] == position {
+			continue
+		}
+		q.positions[entry.ID] = position
+		q.job.statusCtrl.events.publish(jobOwnerKey(entry.User, entry.Provider), jobEventStatus, queuedJobStatus(entry, position))
+	}
+}
+
+// launch removes request from queue and launches it with the same checks as a new launch,
+// since classroom schedule or quota may be changed while request is waiting.
+func (q *LaunchQueue) launch(entry db.QueuedJob) {
+	delete(q.positions, entry.ID)
+	if err := entry.Delete(q.DB); err != nil {
+		log.Warningf("remove queued launch {%s} fail: %s", entry.ID, err.Error())
+		return
+	}
+
+	req := model.LaunchCourseRequest{
+		User:        entry.User,
+		CourseId:    entry.CourseID,
+		ClassroomId: entry.ClassroomID,
+	}
+	// teacher's own course is launched in teacher classroom without classroom check
+	if req.ClassroomId == consts.TEACHER_CLASSROOM {
+		req.ClassroomId = ""
+	}
+
+	owner := jobOwnerKey(entry.User, entry.Provider)
+	isVerified, errs := q.job.precheck(&req, entry.Provider)
+	if !isVerified {
+		log.Warningf("queued launch {%s} of user {%s} is dropped: %s", entry.ID, entry.User, errs[0].Error())
+		q.job.invalidateJobList(entry.User, entry.Provider)
+		q.job.statusCtrl.events.publish(owner, jobEventStatus, model.JobStatus{
+			JobId:  entry.ID,
+			Ready:  false,
+			Status: JobStatusDeleted,
+		})
+		return
+	}
+	req.ClassroomId = entry.ClassroomID
+
+	newJob, errs := q.job.createContainerJob(&req, entry.Provider, entry.ID)
+	if errs != nil {
+		log.Warningf("launch queued job {%s} of user {%s} fail: %s", entry.ID, entry.User, errs[0].Error())
+		q.job.invalidateJobList(entry.User, entry.Provider)
+		q.job.statusCtrl.events.publish(owner, jobEventStatus, model.JobStatus{
+			JobId:  entry.ID,
+			Ready:  false,
+			Status: JobStatusDeleted,
+		})
+		return
+	}
+
+	log.Infof("queued job {%s} of user {%s} is launched", newJob.ID, newJob.User)
+	q.job.statusCtrl.events.publish(owner, jobEventStatus, toJobStatus(*newJob))
+}
+
+// freeGpu returns GPU which can be used by job of classroom, GPU reserved and not yet used by other classrooms is excluded.
+// GPU of jobs being launched is excluded too, mu must be held when queue is enabled.
+func (q *LaunchQueue) freeGpu(classroomID string) (int64, error) {
+	total, used, err := clusterGpu(q.KClientSet, q.gpuResource())
+	if err != nil {
+		return 0, err
+	}
+	unused, err := unusedReservations(q.DB, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("query reservations fail: %s", err.Error())
+	}
+	admitted, err := q.admittedGpu()
+	if err != nil {
+		return 0, fmt.Errorf("query gpu of launching jobs fail: %s", err.Error())
+	}
+	return total - used - admitted - q.launching - reservedForOthers(unused, classroomID), nil
+}
+
+// admittedGpu returns GPU of jobs launched within admissionWindow whose pods are not created by course controller yet.
+// They are not counted in pods, so jobs launched in a burst would otherwise see the same free GPU and over-admit.
+// It is called with mu held, so Course CRDs are read from informer cache of status controller,
+// and pods are counted with one list per namespace rather than per job.
+func (q *LaunchQueue) admittedGpu() (int64, error) {
+	jobs, err := db.JobsCreatedSince(q.DB, time.Now().Add(-admissionWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	// gpu of course, and of job whose pods are checked
+	courseGpu, jobGpu := map[string]int32{}, map[string]int32{}
+	courses := map[string][]*v1alpha1.Course{}
+	total := int64(0)
+	for _, job := range jobs {
+		if job.ClassroomID == nil {
+			continue
+		}
+		gpu, ok := courseGpu[job.CourseID]
+		if !ok {
+			if gpu, err = requestGpu(q.DB, job.CourseID); err != nil {
+				return 0, err
+			}
+			courseGpu[job.CourseID] = gpu
+		}
+		if gpu <= 0 {
+			continue
+		}
+
+		ns := *job.ClassroomID
+		course, err := q.job.statusCtrl.lister.Courses(ns).Get(job.ID)
+		if errors.IsNotFound(err) {
+			// CRD just created may not be in informer cache yet, it has no pod either
+			total += int64(gpu)
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		courses[ns] = append(courses[ns], course)
+		jobGpu[job.ID] = gpu
+	}
+
+	for ns, nsCourses := range courses {
+		podCount, err := coursePodCount(q.KClientSet, ns, nsCourses)
+		if err != nil {
+			return 0, err
+		}
+		for _, course := range nsCourses {
+			if podCount[course.UID] == 0 {
+				total += int64(jobGpu[course.Name])
+			}
+		}
+	}
+	return total, nil
+}
+
+// clusterGpu returns GPU allocatable on schedulable nodes and GPU requested by pods not yet terminated.
+// Pending pods which are not scheduled yet are also counted, since they will take GPU when scheduled.
+func clusterGpu(kclient *kubernetes.Clientset, resource v1.ResourceName) (int64, int64, error) {
+	nodes, err := kclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return 0, 0, fmt.Errorf("list nodes fail: %s", err.Error())
+	}
+
+	schedulable := map[string]bool{}
+	total := int64(0)
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable || !isNodeReady(&node) {
+			continue
+		}
+		schedulable[node.Name] = true
+		if gpu, ok := node.Status.Allocatable[resource]; ok {
+			total += gpu.Value()
+		}
+	}
+
+	pods, err := kclient.CoreV1().Pods(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("list pods fail: %s", err.Error())
+	}
+
+	used := int64(0)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" && !schedulable[pod.Spec.NodeName] {
+			continue
+		}
+		used += podGpu(&pod, resource)
+	}
+
+	return total, used, nil
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podGpu sums GPU of containers, extended resource limit is used when request is omitted.
+func podGpu(pod *v1.Pod, resource v1.ResourceName) int64 {
+	total := int64(0)
+	for _, c := range pod.Spec.Containers {
+		if gpu, ok := c.Resources.Requests[resource]; ok {
+			total += gpu.Value()
+		} else if gpu, ok := c.Resources.Limits[resource]; ok {
+			total += gpu.Value()
+		}
+	}
+	return total
+}
+
+func queuedJobStatus(entry db.QueuedJob, position int) model.JobStatus {
+	return model.JobStatus{
+		JobId:         entry.ID,
+		Ready:         false,
+		Status:        JobStatusQueued,
+		QueuePosition: position,
+	}
+}
+
+// userQueue returns queued launch requests of user as job info, with their position in queue.
+func (q *LaunchQueue) userQueue(user, provider string) ([]model.JobInfo, error) {
+	entries, err := db.UserQueuedJobs(q.DB, user, provider)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	positions, err := db.QueuePositions(q.DB, q.policy())
+	if err != nil {
+		return nil, err
+	}
+
+	jobList := []model.JobInfo{}
+	for _, entry := range entries {
+		info := model.JobInfo{
+			Id:            entry.ID,
+			Type:          db.CONTAINER,
+			CourseID:      entry.CourseID,
+			StartAt:       entry.CreatedAt,
+			Status:        JobStatusQueued,
+			GPU:           entry.Gpu,
+			QueuePosition: positions[entry.ID],
+		}
+		if course, err := db.GetCourse(q.DB, entry.CourseID); err == nil {
+			info.Name = course.Name
+			info.Image = course.Image
+			info.Level = course.Level
+			if course.Introduction != nil {
+				info.Introduction = *course.Introduction
+			}
+		}
+		jobList = append(jobList, info)
+	}
+	return jobList, nil
+}
+
+// findQueued returns queued launch request with id, nil if request is not in queue.
+func (q *LaunchQueue) findQueued(id string) *db.QueuedJob {
+	entry := db.QueuedJob{}
+	if err := q.DB.Where("id = ?", id).First(&entry).Error; err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// cancel removes queued launch request. False is returned if request has been launched by dispatcher in the meantime.
+func (q *LaunchQueue) cancel(entry *db.QueuedJob) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.findQueued(entry.ID) == nil {
+		return false, nil
+	}
+	delete(q.positions, entry.ID)
+	if err := entry.Delete(q.DB); err != nil {
+		return false, err
+	}
+	q.job.invalidateJobList(entry.User, entry.Provider)
+	return true, nil
+}
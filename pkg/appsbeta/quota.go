@@ -0,0 +1,113 @@
+package beta
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+)
+
+type Quota struct {
+	db *gorm.DB
+}
+
+// @Summary List all quota overrides
+// @Description List quota overrides of role, classroom and user. Default quota is defined in api-server config.
+// @Tags Quota
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} docs.QuotaListResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/quota/list [get]
+func (q *Quota) List(c *gin.Context) {
+	quotas, err := db.ListQuota(q.db)
+	if err != nil {
+		log.Errorf("List quota fail: %s", err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "List quota fail: %s", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, model.QuotaListResponse{
+		Error:  false,
+		Quotas: quotas,
+	})
+}
+
+// @Summary Create or update quota override
+// @Description Create or update quota override of a role, classroom or user. Omitted limit is inherited from less specific scope.
+// @Description Negative limit means unlimited, and zero limit allows nothing.
+// @Tags Quota
+// @Accept  json
+// @Produce  json
+// @Param quota body docs.Quota true "scope is one of role, classroom and user. user target is in provider:user format"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/quota/set [put]
+func (q *Quota) Set(c *gin.Context) {
+	var req db.Quota
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return
+	}
+
+	if !db.IsValidQuotaScope(req.Scope) || req.Target == "" {
+		log.Errorf("invalid quota scope {%s} or target {%s}", req.Scope, req.Target)
+		RespondWithError(c, http.StatusBadRequest, "invalid quota scope {%s} or target {%s}", req.Scope, req.Target)
+		return
+	}
+
+	if err := req.Save(q.db); err != nil {
+		log.Errorf("Save quota {%s:%s} fail: %s", req.Scope, req.Target, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Save quota {%s:%s} fail: %s", req.Scope, req.Target, err.Error())
+		return
+	}
+
+	RespondWithOk(c, "Quota {%s:%s} is saved successfully", req.Scope, req.Target)
+}
+
+// @Summary Delete quota override
+// @Description Delete quota override of a role, classroom or user, fall back to less specific scope.
+// @Tags Quota
+// @Accept  json
+// @Produce  json
+// @Param quota body docs.QuotaTarget true "scope and target of quota"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/quota/delete [delete]
+func (q *Quota) Delete(c *gin.Context) {
+	var req db.Quota
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return
+	}
+
+	if !db.IsValidQuotaScope(req.Scope) || req.Target == "" {
+		log.Errorf("invalid quota scope {%s} or target {%s}", req.Scope, req.Target)
+		RespondWithError(c, http.StatusBadRequest, "invalid quota scope {%s} or target {%s}", req.Scope, req.Target)
+		return
+	}
+
+	if err := req.Delete(q.db); err != nil {
+		log.Errorf("Delete quota {%s:%s} fail: %s", req.Scope, req.Target, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Delete quota {%s:%s} fail: %s", req.Scope, req.Target, err.Error())
+		return
+	}
+
+	RespondWithOk(c, "Quota {%s:%s} is deleted successfully", req.Scope, req.Target)
+}
@@ -0,0 +1,225 @@
+package beta
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
+	"github.com/nitishm/go-rejson/v4"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	reapInterval   = time.Minute
+	reapWarnBefore = 10 * time.Minute
+	idleCpuDefault = 50
+)
+
+// podMetricsList is the part of metrics.k8s.io PodMetricsList used to find active jobs.
+type podMetricsList struct {
+	Items []struct {
+		Metadata   metav1.ObjectMeta `json:"metadata"`
+		Containers []struct {
+			Usage v1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// JobReaper periodically deletes container jobs exceeding maximum runtime or idle timeout
+// defined in classroom and course, and warns job owner before deadline.
+// Job is active if user works in its terminal, or its containers use more cpu than IdleCpu in api server config,
+// so jobs used through service url, eg: jupyter, are not regarded as idle.
+type JobReaper struct {
+	DB              *gorm.DB
+	redis           *rejson.Handler
+	CourseCrdClient *versioned.Clientset
+	job             *Job
+	events          *jobEventHub
+	// job id -> deadline already warned, only accessed by reaper goroutine
+	warned map[string]time.Time
+}
+
+func NewJobReaper(DB *gorm.DB, redis *rejson.Handler, crdClient *versioned.Clientset, job *Job,
+	events *jobEventHub) *JobReaper {
+	return &JobReaper{
+		DB:              DB,
+		redis:           redis,
+		CourseCrdClient: crdClient,
+		job:             job,
+		events:          events,
+		warned:          make(map[string]time.Time),
+	}
+}
+
+// Run checks all jobs every reapInterval until stopCh is closed.
+func (r *JobReaper) Run(stopCh <-chan struct{}) {
+	log.Info("Job reaper is started")
+	wait.Until(r.reap, reapInterval, stopCh)
+	log.Info("Job reaper is stopped")
+}
+
+func (r *JobReaper) reap() {
+	jobs := []db.Job{}
+	if err := r.DB.Find(&jobs).Error; err != nil {
+		log.Warningf("reaper query job table fail: %s", err.Error())
+		return
+	}
+
+	now := time.Now()
+	alive := make(map[string]bool)
+	// namespace -> pod name -> cpu usage in millicores, fetched once per namespace
+	usage := make(map[string]map[string]int64)
+	for _, job := range jobs {
+		alive[job.ID] = true
+
+		lifetime, err := job.GetLifetime(r.DB)
+		if err != nil {
+			log.Warningf("reaper query lifetime policy of job {%s} fail: %s", job.ID, err.Error())
+			continue
+		}
+
+		deadline, reason := jobDeadline(&job, lifetime)
+		if deadline.IsZero() {
+			continue
+		}
+
+		// only check usage of jobs close to idle deadline, to save queries to metrics api
+		if reason == DeletedByIdle && deadline.Sub(now) <= reapWarnBefore && r.isBusy(&job, usage) {
+			if err := db.TouchJob(r.DB, job.ID); err != nil {
+				log.Warningf("update last active time of job {%s} fail: %s", job.ID, err.Error())
+			}
+			continue
+		}
+
+		if !now.Before(deadline) {
+			r.expire(job, reason)
+			continue
+		}
+
+		if deadline.Sub(now) <= reapWarnBefore && !r.warned[job.ID].Equal(deadline) {
+			r.warned[job.ID] = deadline
+			r.events.publish(jobOwnerKey(job.User, job.Provider), jobEventWarning, model.JobWarning{
+				JobId:    job.ID,
+				Reason:   reason,
+				Deadline: deadline,
+			})
+			log.Infof("job {%s} of user {%s} will be deleted at %s for %s", job.ID, job.User, deadline, reason)
+		}
+	}
+
+	for id := range r.warned {
+		if !alive[id] {
+			delete(r.warned, id)
+		}
+	}
+}
+
+// jobDeadline returns the earlier one of maximum runtime and idle deadline, and reason of deletion.
+func jobDeadline(job *db.Job, lifetime *db.Lifetime) (time.Time, string) {
+	deadline, reason := lifetime.ExpireAt(job), DeletedByTTL
+
+	idleAt := lifetime.IdleAt(job)
+	if !idleAt.IsZero() && (deadline.IsZero() || idleAt.Before(deadline)) {
+		deadline, reason = idleAt, DeletedByIdle
+	}
+	return deadline, reason
+}
+
+func (r *JobReaper) expire(job db.Job, reason string) {
+	if job.ClassroomID == nil {
+		log.Warningf("job {%s} has no classroom, skip", job.ID)
+		return
+	}
+
+	if errStr, err := job.DeleteCourseCRD(r.DB, r.redis, r.CourseCrdClient, *job.ClassroomID); err != nil {
+		log.Warningf("reaper delete job {%s} fail: %s", job.ID, errStr)
+		return
+	}
+	markAuditDeleted(r.DB, job.ID, reason)
+	delete(r.warned, job.ID)
+
+	r.events.publish(jobOwnerKey(job.User, job.Provider), jobEventStatus, model.JobStatus{
+		JobId:  job.ID,
+		Ready:  false,
+		Status: JobStatusDeleted,
+	})
+	log.Infof("job {%s} of user {%s} is deleted by %s", job.ID, job.User, reason)
+}
+
+// isBusy checks whether any container of job uses more cpu than IdleCpu.
+func (r *JobReaper) isBusy(job *db.Job, usage map[string]map[string]int64) bool {
+	if job.ClassroomID == nil {
+		return false
+	}
+	ns := *job.ClassroomID
+
+	if _, ok := usage[ns]; !ok {
+		podUsage, err := r.podCpuUsage(ns)
+		if err != nil {
+			log.Warningf("reaper query cpu usage of pods in namespace {%s} fail: %s", ns, err.Error())
+		}
+		usage[ns] = podUsage
+	}
+	if len(usage[ns]) == 0 {
+		return false
+	}
+
+	course, err := r.CourseCrdClient.NchcV1alpha1().Courses(ns).Get(context.Background(), job.ID, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Warningf("reaper get course crd of job {%s} fail: %s", job.ID, err.Error())
+		}
+		return false
+	}
+	pods, err := r.job.findJobPods(course)
+	if err != nil {
+		log.Warningf("reaper find pods of job {%s} fail: %s", job.ID, err.Error())
+		return false
+	}
+
+	threshold := int64(idleCpuDefault)
+	if r.job.config.APIConfig.IdleCpu > 0 {
+		threshold = int64(r.job.config.APIConfig.IdleCpu)
+	}
+	for _, pod := range pods {
+		if usage[ns][pod.Name] > threshold {
+			log.Infof("job {%s} uses %dm cpu, regarded as active", job.ID, usage[ns][pod.Name])
+			return true
+		}
+	}
+	return false
+}
+
+// podCpuUsage returns cpu usage in millicores of pods in namespace from metrics api.
+func (r *JobReaper) podCpuUsage(ns string) (map[string]int64, error) {
+	raw, err := r.job.KClientSet.CoreV1().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", ns, "pods").
+		DoRaw(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := podMetricsList{}
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64)
+	for _, pod := range metrics.Items {
+		total := resource.Quantity{}
+		for _, c := range pod.Containers {
+			total.Add(c.Usage[v1.ResourceCPU])
+		}
+		result[pod.Metadata.Name] = total.MilliValue()
+	}
+	return result, nil
+}
@@ -0,0 +1,255 @@
+package beta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
+	"github.com/nitishm/go-rejson/v4"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	reconcileInterval = 10 * time.Minute
+	// resources younger than grace period may be in the middle of launch or deletion, leave them alone
+	reconcileGrace = 10 * time.Minute
+)
+
+// kinds of drift between database and kubernetes
+const (
+	DriftJob       = "job"       // containerJobs row without Course CRD
+	DriftCourseCRD = "course"    // Course CRD without containerJobs row
+	DriftNamespace = "namespace" // labelled namespace without classroomInfo row
+	DriftPV        = "pv"        // PV labelled with deleted classroom
+)
+
+// Reconciler periodically finds and repairs drift left by failed multi-step database and kubernetes operations
+// in launching or deleting job and deleting classroom.
+type Reconciler struct {
+	DB              *gorm.DB
+	redis           *rejson.Handler
+	KClientSet      *kubernetes.Clientset
+	CourseCrdClient *versioned.Clientset
+	config          *config.Config
+	events          *jobEventHub
+	// only one reconcile at a time, either periodic or requested by admin
+	mu sync.Mutex
+}
+
+func NewReconciler(DB *gorm.DB, redis *rejson.Handler, kclient *kubernetes.Clientset, crdClient *versioned.Clientset,
+	config *config.Config, events *jobEventHub) *Reconciler {
+	return &Reconciler{
+		DB:              DB,
+		redis:           redis,
+		KClientSet:      kclient,
+		CourseCrdClient: crdClient,
+		config:          config,
+		events:          events,
+	}
+}
+
+// Run reports drift every reconcileInterval until stopCh is closed. Drift is repaired only if auto repair is enabled,
+// since a mislabelled namespace or a bad database read would otherwise remove resources still in use.
+func (r *Reconciler) Run(stopCh <-chan struct{}) {
+	dryRun := r.config == nil || !r.config.APIConfig.Reconcile.AutoRepair
+	log.Infof("Reconciler is started, dry run: %t", dryRun)
+	wait.Until(func() {
+		if _, err := r.reconcile(dryRun); err != nil {
+			log.Warningf("reconcile fail: %s", err.Error())
+		}
+	}, reconcileInterval, stopCh)
+	log.Info("Reconciler is stopped")
+}
+
+// @Summary Find and repair drift between database and kubernetes
+// @Description Report job rows without Course CRD, Course CRDs without job row, classroom namespaces without classroom
+// @Description and PVs of deleted classroom. Resources are only reported in dry run, which is the default, otherwise they are deleted.
+// @Tags Reconcile
+// @Accept  json
+// @Produce  json
+// @Param dryRun query bool false "only report drift, default true"
+// @Success 200 {object} docs.ReconcileResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/reconcile/run [post]
+func (r *Reconciler) Check(c *gin.Context) {
+	dryRun := c.Query("dryRun") != "false"
+
+	drifts, err := r.reconcile(dryRun)
+	if err != nil {
+		log.Errorf("reconcile fail: %s", err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "reconcile fail: %s", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ReconcileResponse{
+		Error:  false,
+		DryRun: dryRun,
+		Drifts: drifts,
+	})
+}
+
+func (r *Reconciler) reconcile(dryRun bool) ([]model.Drift, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	drifts := []model.Drift{}
+
+	classrooms := []db.ClassRoomInfo{}
+	if err := r.DB.Unscoped().Find(&classrooms).Error; err != nil {
+		return nil, fmt.Errorf("query classroom table fail: %s", err.Error())
+	}
+	classroomIDs := map[string]bool{
+		consts.PUBLIC_CLASSROOM:       true,
+		consts.TEACHER_CLASSROOM:      true,
+		consts.AiTrainSystemNamespace: true,
+	}
+	for _, cm := range classrooms {
+		classroomIDs[cm.ID] = true
+	}
+
+	namespaces, err := r.KClientSet.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{
+		LabelSelector: labels.FormatLabels(map[string]string{consts.NamespaceLabelInstance: r.config.APIConfig.NamespacePrefix}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list classroom namespace fail: %s", err.Error())
+	}
+	managed := map[string]bool{
+		consts.PUBLIC_CLASSROOM:  true,
+		consts.TEACHER_CLASSROOM: true,
+	}
+	for _, ns := range namespaces.Items {
+		managed[ns.Name] = true
+	}
+
+	// classroom namespace without classroom
+	for _, ns := range namespaces.Items {
+		if classroomIDs[ns.Name] || ns.DeletionTimestamp != nil || now.Sub(ns.CreationTimestamp.Time) < reconcileGrace {
+			continue
+		}
+		d := model.Drift{Kind: DriftNamespace, Name: ns.Name, Reason: "classroom is not found in database"}
+		if !dryRun {
+			deletePolicy := metav1.DeletePropagationForeground
+			d.Repaired, d.Error = repairResult(r.KClientSet.CoreV1().Namespaces().Delete(
+				context.Background(), ns.Name, metav1.DeleteOptions{PropagationPolicy: &deletePolicy}))
+		}
+		drifts = append(drifts, d)
+	}
+
+	// PV of deleted classroom
+	pvs, err := r.KClientSet.CoreV1().PersistentVolumes().List(context.Background(), metav1.ListOptions{LabelSelector: "classroom"})
+	if err != nil {
+		return nil, fmt.Errorf("list classroom PV fail: %s", err.Error())
+	}
+	for _, pv := range pvs.Items {
+		classroomID := pv.Labels["classroom"]
+		if classroomIDs[classroomID] || pv.DeletionTimestamp != nil || now.Sub(pv.CreationTimestamp.Time) < reconcileGrace {
+			continue
+		}
+		d := model.Drift{Kind: DriftPV, Name: pv.Name, Reason: fmt.Sprintf("classroom {%s} is not found in database", classroomID)}
+		if !dryRun {
+			d.Repaired, d.Error = repairResult(r.KClientSet.CoreV1().PersistentVolumes().Delete(
+				context.Background(), pv.Name, metav1.DeleteOptions{}))
+		}
+		drifts = append(drifts, d)
+	}
+
+	jobs := []db.Job{}
+	if err := r.DB.Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("query job table fail: %s", err.Error())
+	}
+	jobIDs := map[string]bool{}
+	for _, job := range jobs {
+		jobIDs[job.ID] = true
+	}
+
+	courses, err := r.CourseCrdClient.NchcV1alpha1().Courses(metav1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list Course CRD fail: %s", err.Error())
+	}
+	crds := map[string]bool{}
+	for _, course := range courses.Items {
+		crds[course.Namespace+"/"+course.Name] = true
+	}
+
+	// Course CRD without job
+	for _, course := range courses.Items {
+		if !managed[course.Namespace] || jobIDs[course.Name] || course.DeletionTimestamp != nil ||
+			now.Sub(course.CreationTimestamp.Time) < reconcileGrace {
+			continue
+		}
+		d := model.Drift{Kind: DriftCourseCRD, Namespace: course.Namespace, Name: course.Name, Reason: "job is not found in database"}
+		if !dryRun {
+			deletePolicy := metav1.DeletePropagationForeground
+			d.Repaired, d.Error = repairResult(r.CourseCrdClient.NchcV1alpha1().Courses(course.Namespace).Delete(
+				context.Background(), course.Name, metav1.DeleteOptions{PropagationPolicy: &deletePolicy}))
+		}
+		drifts = append(drifts, d)
+	}
+
+	// job without Course CRD
+	for _, job := range jobs {
+		ns := ""
+		if job.ClassroomID != nil {
+			ns = *job.ClassroomID
+		}
+		if crds[ns+"/"+job.ID] || now.Sub(job.CreatedAt) < reconcileGrace {
+			continue
+		}
+		d := model.Drift{Kind: DriftJob, Namespace: ns, Name: job.ID, Reason: "Course CRD is not found"}
+		if !dryRun {
+			d.Repaired, d.Error = repairResult(r.deleteJob(job))
+		}
+		drifts = append(drifts, d)
+	}
+
+	for _, d := range drifts {
+		log.Infof("drift %s {%s/%s}: %s, dry run: %t, repaired: %t %s", d.Kind, d.Namespace, d.Name, d.Reason, dryRun, d.Repaired, d.Error)
+	}
+	return drifts, nil
+}
+
+// deleteJob removes job whose Course CRD is already gone, and notifies job owner.
+func (r *Reconciler) deleteJob(job db.Job) error {
+	if err := r.DB.Unscoped().Delete(&job).Error; err != nil {
+		return err
+	}
+	markAuditDeleted(r.DB, job.ID, DeletedByReconcile)
+
+	redisKey := jobOwnerKey(job.User, job.Provider)
+	if _, err := r.redis.JSONDel(redisKey, "."); err != nil {
+		log.Warningf("Delete cache key {%s} fail for delete job: %s", redisKey, err.Error())
+	}
+
+	r.events.publish(redisKey, jobEventStatus, model.JobStatus{
+		JobId:  job.ID,
+		Ready:  false,
+		Status: JobStatusDeleted,
+	})
+	return nil
+}
+
+// repairResult regards resource already gone as repaired.
+func repairResult(err error) (bool, string) {
+	if err == nil || errors.IsNotFound(err) {
+		return true, ""
+	}
+	return false, err.Error()
+}
@@ -0,0 +1,288 @@
+package beta
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/course-cron/pkg/cron"
+	"k8s.io/client-go/kubernetes"
+)
+
+// a reservation covers one lecture, so window longer than one day is rejected
+const maxReservationWindow = 24 * time.Hour
+
+type Reservation struct {
+	DB         *gorm.DB
+	KClientSet *kubernetes.Clientset
+	config     *config.Config
+	// overlap check and insert of reservation must not interleave
+	mu sync.Mutex
+}
+
+// @Summary List GPU reservations of classroom
+// @Description List GPU reservations of classroom which are not yet ended, in order of start time.
+// @Tags Reservation
+// @Accept  json
+// @Produce  json
+// @Param id path string true "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Success 200 {object} docs.ReservationListResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/reservation/list/{id} [get]
+func (r *Reservation) List(c *gin.Context) {
+	classroomID := c.Param("id")
+	if classroomID == "" {
+		RespondWithError(c, http.StatusBadRequest, "Classroom Id is empty")
+		return
+	}
+
+	if !checkClassroomTeacher(c, r.DB, classroomID) {
+		return
+	}
+
+	reservations, err := db.ListReservations(r.DB, classroomID, time.Now())
+	if err != nil {
+		errStr := fmt.Sprintf("List reservations of classroom {%s} fail: %s", classroomID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ReservationListResponse{
+		Error:        false,
+		Reservations: reservations,
+	})
+}
+
+// @Summary Reserve GPU for classroom in a time window
+// @Description Reserve GPU for jobs of classroom. Time window must be in classroom schedule, and GPU reserved
+// @Description at the same time by all classrooms can not exceed GPU of cluster. Reserved GPU can not be used by other classrooms in the window.
+// @Tags Reservation
+// @Accept  json
+// @Produce  json
+// @Param reservation body docs.ReservationRequest true "classroom, gpu number and time window"
+// @Success 200 {object} docs.ReservationResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/reservation/create [post]
+func (r *Reservation) Add(c *gin.Context) {
+	var req db.Reservation
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return
+	}
+
+	if req.ClassroomID == "" {
+		RespondWithError(c, http.StatusBadRequest, "classroom_id can not be empty")
+		return
+	}
+
+	if !checkClassroomTeacher(c, r.DB, req.ClassroomID) {
+		return
+	}
+
+	if req.Gpu <= 0 {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_RESERVATION_GPU_FMT, req.Gpu)
+		return
+	}
+
+	req.StartAt = req.StartAt.Truncate(time.Minute)
+	req.EndAt = req.EndAt.Truncate(time.Minute)
+	if !req.EndAt.After(req.StartAt) || !req.EndAt.After(time.Now()) || req.EndAt.Sub(req.StartAt) > maxReservationWindow {
+		log.Errorf("invalid reservation window %s - %s", req.StartAt, req.EndAt)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_RESERVATION_WINDOW_FMT, int(maxReservationWindow.Hours()))
+		return
+	}
+
+	cmInfo := db.ClassRoomInfo{
+		Model: db.Model{
+			ID: req.ClassroomID,
+		},
+	}
+	cm, err := cmInfo.GetClassRoomDetail(r.DB)
+	if err != nil {
+		errStr := fmt.Sprintf("Query classroom {%s} fail: %s", req.ClassroomID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusBadRequest, errStr)
+		return
+	}
+
+	schedules, err := cmInfo.GetSchedule(r.DB)
+	if err != nil {
+		errStr := fmt.Sprintf("Query schedule of classroom {%s} fail: %s", req.ClassroomID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	if outside, ok := outOfSchedule(schedules.CronFormat, req.StartAt, req.EndAt); !ok {
+		log.Errorf("reservation window of classroom {%s} is out of schedule at %s", req.ClassroomID, outside)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_RESERVATION_SCHEDULE_FMT,
+			cm.Name, cm.ScheduleDescription, outside.Format("2006-01-02 15:04"))
+		return
+	}
+
+	total, _, err := clusterGpu(r.KClientSet, gpuResourceName(r.config))
+	if err != nil {
+		errStr := fmt.Sprintf("Query gpu of cluster fail: %s", err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	overlap, err := db.OverlapReservations(r.DB, req.StartAt, req.EndAt)
+	if err != nil {
+		errStr := fmt.Sprintf("Query reservations fail: %s", err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	reserved := db.PeakReserved(overlap)
+	if db.PeakReserved(append(overlap, req)) > total {
+		log.Errorf("cluster has %d gpu, %d gpu is reserved, reserve %d gpu for classroom {%s} fail",
+			total, reserved, req.Gpu, req.ClassroomID)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_RESERVATION_CAPACITY_FMT, total, reserved, req.Gpu)
+		return
+	}
+
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+	req.User = callerName(c, req.User)
+	req.Provider = provider.(string)
+	req.ID = uuid.New().String()
+	if err := req.NewEntry(r.DB); err != nil {
+		errStr := fmt.Sprintf("Insert reservation of classroom {%s} fail: %s", req.ClassroomID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	log.Infof("%d gpu is reserved for classroom {%s} from %s to %s", req.Gpu, req.ClassroomID, req.StartAt, req.EndAt)
+	c.JSON(http.StatusOK, model.ReservationResponse{
+		Error:       false,
+		Reservation: req,
+	})
+}
+
+// @Summary Cancel GPU reservation
+// @Description Cancel GPU reservation, reserved GPU is released to all classrooms.
+// @Tags Reservation
+// @Accept  json
+// @Produce  json
+// @Param id path string true "reservation uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/reservation/delete/{id} [delete]
+func (r *Reservation) Delete(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		RespondWithError(c, http.StatusBadRequest, "Reservation Id is empty")
+		return
+	}
+
+	reservation := db.Reservation{}
+	if err := r.DB.Where("id = ?", id).First(&reservation).Error; err != nil {
+		log.Errorf("Query reservation {%s} fail: %s", id, err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Query reservation {%s} fail: %s", id, err.Error())
+		return
+	}
+
+	if !checkClassroomTeacher(c, r.DB, reservation.ClassroomID) {
+		return
+	}
+
+	if err := reservation.Delete(r.DB); err != nil {
+		log.Errorf("Delete reservation {%s} fail: %s", id, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "Delete reservation {%s} fail: %s", id, err.Error())
+		return
+	}
+
+	RespondWithOk(c, "Reservation {%s} is deleted successfully", id)
+}
+
+// outOfSchedule checks every minute in [start, end) is in classroom schedule,
+// the first minute out of schedule is returned if not.
+func outOfSchedule(cronFormat []string, start, end time.Time) (time.Time, bool) {
+	twLocal, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		twLocal = time.Local
+	}
+
+	for ts := start.In(twLocal); ts.Before(end); ts = ts.Add(time.Minute) {
+		if !inSchedule(cronFormat, ts) {
+			return ts, false
+		}
+	}
+	return time.Time{}, true
+}
+
+// inSchedule checks ts matches one of classroom schedules.
+func inSchedule(cronFormat []string, ts time.Time) bool {
+	for _, schedule := range cronFormat {
+		if cron.IsTimeMatchCronExpression(ts, schedule) {
+			return true
+		}
+	}
+	return false
+}
+
+// unusedReservations returns GPU reserved by each classroom at t and not yet used by jobs of the classroom.
+func unusedReservations(DB *gorm.DB, t time.Time) (map[string]int64, error) {
+	active, err := db.ActiveReservations(DB, t)
+	if err != nil {
+		return nil, err
+	}
+
+	unused := map[string]int64{}
+	for _, r := range active {
+		unused[r.ClassroomID] += int64(r.Gpu)
+	}
+	for classroomID, reserved := range unused {
+		usage, err := db.ClassroomGpuUsage(DB, classroomID)
+		if err != nil {
+			return nil, err
+		}
+		if usage >= reserved {
+			delete(unused, classroomID)
+		} else {
+			unused[classroomID] = reserved - usage
+		}
+	}
+	return unused, nil
+}
+
+// reservedForOthers sums unused reservation of classrooms other than classroomID.
+func reservedForOthers(unused map[string]int64, classroomID string) int64 {
+	reserved := int64(0)
+	for id, gpu := range unused {
+		if id != classroomID {
+			reserved += gpu
+		}
+	}
+	return reserved
+}
@@ -0,0 +1,540 @@
+package beta
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	scheduleInterval = 30 * time.Second
+	// how far to look for next class window of classroom
+	scheduleLookahead = 14 * 24 * time.Hour
+	// pending scheduled jobs allowed for one user
+	maxPendingSchedules = 10
+)
+
+// JobScheduler launches and stops container jobs at time requested by users.
+// Scheduled jobs are persisted in database and checked periodically, so scheduled actions
+// missed while api server is down are executed once it is up again.
+type JobScheduler struct {
+	DB  *gorm.DB
+	job *Job
+	// only one execution or change at a time, so a scheduled job is never launched twice,
+	// and pending schedules of user never exceed maxPendingSchedules
+	mu sync.Mutex
+}
+
+func NewJobScheduler(DB *gorm.DB, job *Job) *JobScheduler {
+	return &JobScheduler{
+		DB:  DB,
+		job: job,
+	}
+}
+
+// Run executes due scheduled actions every scheduleInterval until stopCh is closed.
+func (s *JobScheduler) Run(stopCh <-chan struct{}) {
+	log.Info("Job scheduler is started")
+	wait.Until(s.execute, scheduleInterval, stopCh)
+	log.Info("Job scheduler is stopped")
+}
+
+func (s *JobScheduler) execute() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	launches, err := db.DueLaunches(s.DB, now)
+	if err != nil {
+		log.Warningf("scheduler query due launches fail: %s", err.Error())
+	}
+	for _, sj := range launches {
+		s.launch(sj, now)
+	}
+
+	stops, err := db.DueStops(s.DB, now)
+	if err != nil {
+		log.Warningf("scheduler query due stops fail: %s", err.Error())
+	}
+	for _, sj := range stops {
+		s.stop(sj)
+	}
+}
+
+// launch starts scheduled job with the same checks as a new launch.
+func (s *JobScheduler) launch(sj db.ScheduledJob, now time.Time) {
+	if sj.StopAt != nil && !now.Before(*sj.StopAt) {
+		s.finish(&sj, db.SCHEDULE_FAILED, "", fmt.Sprintf("launch time is missed, job should be stopped at %s", sj.StopAt))
+		return
+	}
+
+	req := model.LaunchCourseRequest{
+		User:        sj.User,
+		CourseId:    sj.CourseID,
+		ClassroomId: sj.ClassroomID,
+	}
+	if isVerified, errs := s.job.precheck(&req, sj.Provider); !isVerified {
+		log.Warningf("Pre-check scheduled launch {%s} of user {%s} fail: %s", sj.ID, sj.User, errs[0].Error())
+		s.finish(&sj, db.SCHEDULE_FAILED, "", errs[1].Error())
+		return
+	}
+
+	// if classroom_id is empty, and pass precheck, means own by some teacher, lauch in default namespace.
+	if req.ClassroomId == "" {
+		req.ClassroomId = consts.TEACHER_CLASSROOM
+	}
+
+	status, errs := s.job.startContainerJob(&req, sj.Provider)
+	if errs != nil {
+		log.Warningf("scheduled launch {%s} of user {%s} fail: %s", sj.ID, sj.User, errs[0].Error())
+		s.finish(&sj, db.SCHEDULE_FAILED, "", errs[1].Error())
+		return
+	}
+
+	log.Infof("scheduled job {%s} of user {%s} is launched as {%s}", sj.ID, sj.User, status.JobId)
+	s.finish(&sj, db.SCHEDULE_LAUNCHED, status.JobId, "")
+}
+
+// stop deletes job launched by schedule, job already deleted by user is regarded as stopped.
+func (s *JobScheduler) stop(sj db.ScheduledJob) {
+	owner := jobOwnerKey(sj.User, sj.Provider)
+	deleted := model.JobStatus{
+		JobId:  sj.JobID,
+		Ready:  false,
+		Status: JobStatusDeleted,
+	}
+
+	// job is still waiting in launch queue
+	if entry := s.job.queue.findQueued(sj.JobID); entry != nil {
+		if cancelled, err := s.job.queue.cancel(entry); err != nil {
+			log.Warningf("remove scheduled job {%s} from launch queue fail: %s", sj.JobID, err.Error())
+			return
+		} else if cancelled {
+			s.job.statusCtrl.events.publish(owner, jobEventStatus, deleted)
+			s.finish(&sj, db.SCHEDULE_STOPPED, sj.JobID, "")
+			return
+		}
+	}
+
+	job := db.Job{}
+	if err := s.DB.Where("id = ?", sj.JobID).First(&job).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			s.finish(&sj, db.SCHEDULE_STOPPED, sj.JobID, "job is already deleted")
+		} else {
+			log.Warningf("scheduler query job {%s} fail: %s", sj.JobID, err.Error())
+		}
+		return
+	}
+
+	if errStr, err := job.DeleteCourseCRD(s.DB, s.job.redis, s.job.CourseCrdClient, *job.ClassroomID); err != nil {
+		log.Warningf("stop scheduled job {%s} of user {%s} fail: %s", job.ID, job.User, errStr)
+		return
+	}
+	markAuditDeleted(s.DB, job.ID, DeletedBySchedule)
+
+	s.job.statusCtrl.events.publish(owner, jobEventStatus, deleted)
+	log.Infof("scheduled job {%s} of user {%s} is stopped", job.ID, job.User)
+	s.finish(&sj, db.SCHEDULE_STOPPED, sj.JobID, "")
+}
+
+func (s *JobScheduler) finish(sj *db.ScheduledJob, status, jobID, message string) {
+	sj.Status = status
+	sj.JobID = jobID
+	sj.Message = message
+	if err := sj.Update(s.DB); err != nil {
+		log.Warningf("update scheduled job {%s} to %s fail: %s", sj.ID, status, err.Error())
+	}
+}
+
+// @Summary List scheduled jobs of user
+// @Description List scheduled launch and stop of container jobs for a user, in order of start time.
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param user query string false "user name, only used when secure api is disabled"
+// @Success 200 {object} docs.ScheduledJobListResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/schedule/list [get]
+func (s *JobScheduler) List(c *gin.Context) {
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+
+	user := callerName(c, c.Query("user"))
+	if user == "" {
+		log.Errorf("Empty user name")
+		RespondWithError(c, http.StatusBadRequest, "Empty user name")
+		return
+	}
+
+	schedules, err := db.UserScheduledJobs(s.DB, user, provider.(string))
+	if err != nil {
+		errStr := fmt.Sprintf("Query scheduled jobs of user {%s} fail: %s", user, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ScheduledJobListResponse{
+		Error:     false,
+		Schedules: schedules,
+	})
+}
+
+// @Summary Schedule launch and stop of a container job
+// @Description Launch container job at startAt, or beforeClass minutes before next class window of classroom.
+// @Description Job is stopped at stopAt, or at the end of class window if stopAtClassEnd is set. Quota and classroom schedule are checked at launch time.
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param schedule body docs.ScheduleJobRequest true "course to launch and time to launch and stop"
+// @Success 200 {object} docs.ScheduledJobResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/schedule/create [post]
+func (s *JobScheduler) Add(c *gin.Context) {
+	var req model.ScheduleJobRequest
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return
+	}
+	req.User = callerName(c, req.User)
+
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+
+	if req.User == "" || req.CourseId == "" {
+		RespondWithError(c, http.StatusBadRequest, "user and course_id can not be empty")
+		return
+	}
+
+	// count and insert at once, or concurrent requests may all pass the limit
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, err := db.UserScheduledJobs(s.DB, req.User, provider.(string))
+	if err != nil {
+		errStr := fmt.Sprintf("Query scheduled jobs of user {%s} fail: %s", req.User, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+	count := 0
+	for _, sj := range pending {
+		if sj.Status == db.SCHEDULE_PENDING {
+			count++
+		}
+	}
+	if count >= maxPendingSchedules {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_LIMIT_FMT, maxPendingSchedules)
+		return
+	}
+
+	sj := db.ScheduledJob{
+		Model: db.Model{
+			ID: uuid.New().String(),
+		},
+		OauthUser: db.OauthUser{
+			User:     req.User,
+			Provider: provider.(string),
+		},
+		Status: db.SCHEDULE_PENDING,
+	}
+	if !s.resolve(c, &req, &sj) {
+		return
+	}
+
+	if err := sj.NewEntry(s.DB); err != nil {
+		errStr := fmt.Sprintf("Insert scheduled job of user {%s} fail: %s", req.User, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	log.Infof("course {%s} of user {%s} is scheduled at %s", sj.CourseID, sj.User, sj.StartAt)
+	c.JSON(http.StatusOK, model.ScheduledJobResponse{
+		Error:    false,
+		Schedule: sj,
+	})
+}
+
+// @Summary Update scheduled job
+// @Description Update course and time of pending scheduled job. Only stop time can be updated after job is launched.
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param id path string true "scheduled job uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param schedule body docs.ScheduleJobRequest true "course to launch and time to launch and stop"
+// @Success 200 {object} docs.ScheduledJobResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/schedule/update/{id} [put]
+func (s *JobScheduler) Update(c *gin.Context) {
+	var req model.ScheduleJobRequest
+	if err := c.BindJSON(&req); err != nil {
+		log.Errorf("Failed to parse spec request request: %s", err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Failed to parse spec request request: %s", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sj, ok := s.findScheduledJob(c)
+	if !ok {
+		return
+	}
+
+	switch sj.Status {
+	case db.SCHEDULE_PENDING:
+		if req.CourseId == "" {
+			req.CourseId = sj.CourseID
+		}
+		if !s.resolve(c, &req, sj) {
+			return
+		}
+	case db.SCHEDULE_LAUNCHED:
+		if req.StopAt != nil && !req.StopAt.After(time.Now()) {
+			RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_STOP_FMT,
+				req.StopAt.Format(time.RFC3339), sj.StartAt.Format(time.RFC3339))
+			return
+		}
+		sj.StopAt = req.StopAt
+	default:
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_STATE_FMT, sj.Status)
+		return
+	}
+
+	if err := sj.Update(s.DB); err != nil {
+		errStr := fmt.Sprintf("Update scheduled job {%s} fail: %s", sj.ID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.ScheduledJobResponse{
+		Error:    false,
+		Schedule: *sj,
+	})
+}
+
+// @Summary Delete scheduled job
+// @Description Cancel pending scheduled launch, or planned stop of launched job. Job already launched keeps running.
+// @Tags Schedule
+// @Accept  json
+// @Produce  json
+// @Param id path string true "scheduled job uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/schedule/delete/{id} [delete]
+func (s *JobScheduler) Delete(c *gin.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sj, ok := s.findScheduledJob(c)
+	if !ok {
+		return
+	}
+
+	if err := sj.Delete(s.DB); err != nil {
+		errStr := fmt.Sprintf("Delete scheduled job {%s} fail: %s", sj.ID, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	RespondWithOk(c, "Scheduled job {%s} is deleted successfully", sj.ID)
+}
+
+// findScheduledJob looks up scheduled job in id param, only owner, classroom teacher and superuser are allowed.
+func (s *JobScheduler) findScheduledJob(c *gin.Context) (*db.ScheduledJob, bool) {
+	id := c.Param("id")
+	if id == "" {
+		RespondWithError(c, http.StatusBadRequest, "Scheduled job Id is empty")
+		return nil, false
+	}
+
+	sj, err := db.GetScheduledJob(s.DB, id)
+	if err != nil {
+		log.Errorf("Query scheduled job {%s} fail: %s", id, err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Query scheduled job {%s} fail: %s", id, err.Error())
+		return nil, false
+	}
+
+	job := db.Job{
+		OauthUser:   sj.OauthUser,
+		CourseID:    sj.CourseID,
+		ClassroomID: &sj.ClassroomID,
+	}
+	if !checkJobOwner(c, s.DB, &job) {
+		return nil, false
+	}
+	return sj, true
+}
+
+// resolve fills course and time of scheduled job from request, class window is looked up if it is requested.
+func (s *JobScheduler) resolve(c *gin.Context, req *model.ScheduleJobRequest, sj *db.ScheduledJob) bool {
+	course := db.Course{
+		Model: db.Model{
+			ID: req.CourseId,
+		},
+	}
+	courseType, err := course.Type(s.DB)
+	if err != nil {
+		errStr := fmt.Sprintf("Query course {%s} type fail: %s", req.CourseId, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusBadRequest, errStr)
+		return false
+	}
+	if courseType != db.CONTAINER {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_TYPE_FMT, req.CourseId)
+		return false
+	}
+
+	now := time.Now()
+	startAt, stopAt := req.StartAt, req.StopAt
+
+	if req.BeforeClass != nil || req.StopAtClassEnd {
+		if req.ClassroomId == "" {
+			RespondWithError(c, http.StatusBadRequest, "classroom_id is required to schedule by class window")
+			return false
+		}
+		cmInfo := db.ClassRoomInfo{
+			Model: db.Model{
+				ID: req.ClassroomId,
+			},
+		}
+		cm, err := cmInfo.GetClassRoomDetail(s.DB)
+		if err != nil {
+			errStr := fmt.Sprintf("Query classroom {%s} fail: %s", req.ClassroomId, err.Error())
+			log.Error(errStr)
+			RespondWithError(c, http.StatusBadRequest, errStr)
+			return false
+		}
+		schedules, err := cmInfo.GetSchedule(s.DB)
+		if err != nil {
+			errStr := fmt.Sprintf("Query schedule of classroom {%s} fail: %s", req.ClassroomId, err.Error())
+			log.Error(errStr)
+			RespondWithError(c, http.StatusInternalServerError, errStr)
+			return false
+		}
+
+		var found bool
+		if startAt, stopAt, found = classWindowTimes(schedules.CronFormat, now, req); !found {
+			RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_CLASS_FMT, cm.Name, int(scheduleLookahead.Hours()/24))
+			return false
+		}
+	}
+
+	if startAt == nil || !startAt.After(now) {
+		start := ""
+		if startAt != nil {
+			start = startAt.Format(time.RFC3339)
+		}
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_START_FMT, start)
+		return false
+	}
+	if stopAt != nil && !stopAt.After(*startAt) {
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_SCHEDULE_STOP_FMT,
+			stopAt.Format(time.RFC3339), startAt.Format(time.RFC3339))
+		return false
+	}
+
+	sj.CourseID = req.CourseId
+	sj.ClassroomID = req.ClassroomId
+	sj.StartAt = startAt.Truncate(time.Minute)
+	if stopAt != nil {
+		t := stopAt.Truncate(time.Minute)
+		stopAt = &t
+	}
+	sj.StopAt = stopAt
+	return true
+}
+
+// classWindowTimes resolves start and stop time of request by next class window after now.
+// Start is beforeClass minutes before class window begins, and stop is the end of class window if stopAtClassEnd is set,
+// otherwise startAt and stopAt of request are kept. False is returned if no class window is found.
+func classWindowTimes(cronFormat []string, now time.Time, req *model.ScheduleJobRequest) (*time.Time, *time.Time, bool) {
+	startAt, stopAt := req.StartAt, req.StopAt
+
+	before := time.Duration(0)
+	if req.BeforeClass != nil && *req.BeforeClass > 0 {
+		before = time.Duration(*req.BeforeClass) * time.Minute
+	}
+	// class window must begin after launch time, so job is launched before class
+	from := now.Add(before)
+	if req.BeforeClass == nil && startAt != nil && startAt.After(now) {
+		from = *startAt
+	}
+	classStart, classEnd, found := nextClassWindow(cronFormat, from)
+	if !found {
+		return nil, nil, false
+	}
+	if req.BeforeClass != nil {
+		t := classStart.Add(-before)
+		startAt = &t
+	}
+	if req.StopAtClassEnd {
+		stopAt = &classEnd
+	}
+	return startAt, stopAt, true
+}
+
+// nextClassWindow finds first class window of classroom schedule which begins at or after from.
+// Class window is consecutive minutes matching schedule, end of window is the first minute out of schedule.
+func nextClassWindow(cronFormat []string, from time.Time) (time.Time, time.Time, bool) {
+	twLocal, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		twLocal = time.Local
+	}
+
+	ts := from.In(twLocal).Truncate(time.Minute)
+	if ts.Before(from) {
+		ts = ts.Add(time.Minute)
+	}
+	limit := ts.Add(scheduleLookahead)
+
+	// window already begun at from is skipped
+	prev := inSchedule(cronFormat, ts.Add(-time.Minute))
+	for ; ts.Before(limit); ts = ts.Add(time.Minute) {
+		cur := inSchedule(cronFormat, ts)
+		if cur && !prev {
+			end := ts.Add(time.Minute)
+			for end.Before(limit) && inSchedule(cronFormat, end) {
+				end = end.Add(time.Minute)
+			}
+			return ts, end, true
+		}
+		prev = cur
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,140 @@
+package beta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// classroom schedule is in local time of Taiwan, see nextClassWindow
+func taipeiTime(month time.Month, day, hour, min int) time.Time {
+	loc, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		loc = time.Local
+	}
+	return time.Date(2020, month, day, hour, min, 0, 0, loc)
+}
+
+func TestNextClassWindow(t *testing.T) {
+	// class is 9:00 to 11:00 on Monday, 2020-01-06 is Monday
+	monday := []string{"* 9-10 * * 1 *"}
+
+	for _, tc := range []struct {
+		name       string
+		cronFormat []string
+		from       time.Time
+		start      time.Time
+		end        time.Time
+		found      bool
+	}{
+		{
+			name: "before class", cronFormat: monday, from: taipeiTime(1, 6, 8, 0),
+			start: taipeiTime(1, 6, 9, 0), end: taipeiTime(1, 6, 11, 0), found: true,
+		},
+		{
+			name: "class begins at from", cronFormat: monday, from: taipeiTime(1, 6, 9, 0),
+			start: taipeiTime(1, 6, 9, 0), end: taipeiTime(1, 6, 11, 0), found: true,
+		},
+		{
+			name: "from is rounded up to minute", cronFormat: monday, from: taipeiTime(1, 6, 8, 59).Add(30 * time.Second),
+			start: taipeiTime(1, 6, 9, 0), end: taipeiTime(1, 6, 11, 0), found: true,
+		},
+		{
+			name: "class already begun is skipped", cronFormat: monday, from: taipeiTime(1, 6, 9, 30),
+			start: taipeiTime(1, 13, 9, 0), end: taipeiTime(1, 13, 11, 0), found: true,
+		},
+		{
+			name: "consecutive schedules are one window", cronFormat: []string{"* 9 * * 1 *", "* 10-11 * * 1 *"},
+			from:  taipeiTime(1, 6, 8, 0),
+			start: taipeiTime(1, 6, 9, 0), end: taipeiTime(1, 6, 12, 0), found: true,
+		},
+		{
+			name: "no class within lookahead", cronFormat: []string{"* 9 1 6 * *"}, from: taipeiTime(1, 6, 8, 0),
+		},
+		{
+			name: "no schedule", from: taipeiTime(1, 6, 8, 0),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, found := nextClassWindow(tc.cronFormat, tc.from)
+			assert.Equal(t, tc.found, found)
+			if !tc.found {
+				return
+			}
+			assert.True(t, tc.start.Equal(start), "start %s", start)
+			assert.True(t, tc.end.Equal(end), "end %s", end)
+		})
+	}
+}
+
+func TestClassWindowTimes(t *testing.T) {
+	monday := []string{"* 9-10 * * 1 *"}
+	now := taipeiTime(1, 6, 8, 0)
+	minutes := func(m int) *int {
+		return &m
+	}
+	at := func(tm time.Time) *time.Time {
+		return &tm
+	}
+
+	for _, tc := range []struct {
+		name  string
+		req   model.ScheduleJobRequest
+		start *time.Time
+		stop  *time.Time
+		found bool
+	}{
+		{
+			name:  "before class",
+			req:   model.ScheduleJobRequest{BeforeClass: minutes(30)},
+			start: at(taipeiTime(1, 6, 8, 30)), found: true,
+		},
+		{
+			name:  "zero minutes before class",
+			req:   model.ScheduleJobRequest{BeforeClass: minutes(0)},
+			start: at(taipeiTime(1, 6, 9, 0)), found: true,
+		},
+		{
+			// launch time must be before class, so the window after now+90m is used
+			name:  "before class longer than time to class",
+			req:   model.ScheduleJobRequest{BeforeClass: minutes(90)},
+			start: at(taipeiTime(1, 13, 7, 30)), found: true,
+		},
+		{
+			name:  "stop at class end keeps start",
+			req:   model.ScheduleJobRequest{StartAt: at(taipeiTime(1, 6, 8, 10)), StopAtClassEnd: true},
+			start: at(taipeiTime(1, 6, 8, 10)), stop: at(taipeiTime(1, 6, 11, 0)), found: true,
+		},
+		{
+			name:  "stop at end of class begun after start",
+			req:   model.ScheduleJobRequest{StartAt: at(taipeiTime(1, 6, 9, 30)), StopAtClassEnd: true},
+			start: at(taipeiTime(1, 6, 9, 30)), stop: at(taipeiTime(1, 13, 11, 0)), found: true,
+		},
+		{
+			name:  "before class and stop at class end",
+			req:   model.ScheduleJobRequest{BeforeClass: minutes(15), StopAtClassEnd: true},
+			start: at(taipeiTime(1, 6, 8, 45)), stop: at(taipeiTime(1, 6, 11, 0)), found: true,
+		},
+		{
+			name:  "before class keeps stop",
+			req:   model.ScheduleJobRequest{BeforeClass: minutes(15), StopAt: at(taipeiTime(1, 6, 10, 0))},
+			start: at(taipeiTime(1, 6, 8, 45)), stop: at(taipeiTime(1, 6, 10, 0)), found: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			start, stop, found := classWindowTimes(monday, now, &tc.req)
+			assert.Equal(t, tc.found, found)
+			assert.True(t, tc.start.Equal(*start), "start %s", start)
+			if tc.stop == nil {
+				assert.Nil(t, stop)
+			} else {
+				assert.True(t, tc.stop.Equal(*stop), "stop %s", stop)
+			}
+		})
+	}
+
+	_, _, found := classWindowTimes(nil, now, &model.ScheduleJobRequest{BeforeClass: minutes(30)})
+	assert.False(t, found)
+}
@@ -0,0 +1,368 @@
+package beta
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/backend-api/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+var repositoryInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+const (
+	commitSyncInterval = 15 * time.Second
+
+	runtimeDocker     = "docker"
+	runtimeContainerd = "containerd"
+
+	defaultCommitBuilderImage = "docker:24-cli"
+	defaultCommitTimeout      = 30 // minutes
+	// finished builder job is removed by kubernetes after ttl, logs are kept in database before that
+	commitBuilderTTL = int32(3600)
+	// bytes of builder logs kept in database
+	maxCommitLogBytes = 16 * 1024
+
+	commitDockerConfigPath = "/docker-config"
+)
+
+var defaultRuntimeSockets = map[string]string{
+	runtimeDocker:     "/var/run/docker.sock",
+	runtimeContainerd: "/run/containerd/containerd.sock",
+}
+
+// ImageCommitter commits container of running job into new image and pushes it to registry.
+// The image is built by a builder job on the node running the job, since only container runtime of the node
+// can access the container. Builder jobs are followed periodically and their result is persisted in database.
+type ImageCommitter struct {
+	DB         *gorm.DB
+	KClientSet *kubernetes.Clientset
+	config     *config.Config
+	// only one sync at a time, so result of a commit is never recorded twice
+	mu sync.Mutex
+}
+
+func NewImageCommitter(DB *gorm.DB, kclient *kubernetes.Clientset, config *config.Config) *ImageCommitter {
+	return &ImageCommitter{
+		DB:         DB,
+		KClientSet: kclient,
+		config:     config,
+	}
+}
+
+// Run syncs status of unfinished commits every commitSyncInterval until stopCh is closed.
+func (m *ImageCommitter) Run(stopCh <-chan struct{}) {
+	log.Info("Image committer is started")
+	wait.Until(m.sync, commitSyncInterval, stopCh)
+	log.Info("Image committer is stopped")
+}
+
+// Enabled checks registry is configured, commit is not possible without somewhere to push images.
+func (m *ImageCommitter) Enabled() bool {
+	return m.config.APIConfig.Snapshot.Registry != ""
+}
+
+// ImageRef returns full reference of image name:tag in configured registry.
+func (m *ImageCommitter) ImageRef(name, tag string) string {
+	return fmt.Sprintf("%s/%s:%s", strings.TrimSuffix(m.config.APIConfig.Snapshot.Registry, "/"), name, tag)
+}
+
+// ownerRepository returns repository path which images committed by owner are pushed under, so owners cannot
+// overwrite images of each other in the shared registry. User name is sanitized for repository path, and
+// suffixed with hash of provider and user to keep different owners with similar names apart.
+func ownerRepository(user, provider string) string {
+	name := strings.Trim(repositoryInvalidChars.ReplaceAllString(strings.ToLower(user), "-"), "-")
+	if name == "" {
+		name = "user"
+	}
+	sum := sha256.Sum256([]byte(provider + ":" + user))
+	return fmt.Sprintf("%s-%s", name, hex.EncodeToString(sum[:])[:12])
+}
+
+// Start creates builder job committing container of pod into commit.Image.
+func (m *ImageCommitter) Start(commit *db.ImageCommit, pod *v1.Pod) error {
+	builder, err := m.newBuilderJob(commit, pod)
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.KClientSet.BatchV1().Jobs(consts.AiTrainSystemNamespace).
+		Create(context.Background(), builder, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	commit.BuilderJob = builder.Name
+	commit.Status = db.COMMIT_RUNNING
+	return commit.Update(m.DB)
+}
+
+func (m *ImageCommitter) sync() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	commits, err := db.UnfinishedImageCommits(m.DB)
+	if err != nil {
+		log.Warningf("image committer query unfinished commits fail: %s", err.Error())
+		return
+	}
+
+	for i := range commits {
+		m.syncCommit(&commits[i])
+	}
+}
+
+func (m *ImageCommitter) syncCommit(commit *db.ImageCommit) {
+	if commit.BuilderJob == "" {
+		// builder job is created right after commit is recorded, api server may be down in between
+		if time.Since(commit.CreatedAt) > commitSyncInterval*4 {
+			m.finish(commit, db.COMMIT_FAILED, "builder job is not created")
+		}
+		return
+	}
+
+	builder, err := m.KClientSet.BatchV1().Jobs(consts.AiTrainSystemNamespace).
+		Get(context.Background(), commit.BuilderJob, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		m.finish(commit, db.COMMIT_FAILED, fmt.Sprintf("builder job {%s} is not found", commit.BuilderJob))
+		return
+	}
+	if err != nil {
+		log.Warningf("get builder job {%s} of image commit {%s} fail: %s", commit.BuilderJob, commit.ID, err.Error())
+		return
+	}
+
+	for _, cond := range builder.Status.Conditions {
+		if cond.Status != v1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			m.finish(commit, db.COMMIT_SUCCEEDED, "")
+			return
+		case batchv1.JobFailed:
+			m.finish(commit, db.COMMIT_FAILED, fmt.Sprintf("%s: %s", cond.Reason, cond.Message))
+			return
+		}
+	}
+}
+
+func (m *ImageCommitter) finish(commit *db.ImageCommit, status, message string) {
+	if commit.BuilderJob != "" {
+		logs, err := m.builderLogs(commit.BuilderJob)
+		if err != nil {
+			log.Warningf("get logs of builder job {%s} fail: %s", commit.BuilderJob, err.Error())
+		}
+		commit.Logs = logs
+	}
+
+	now := time.Now()
+	commit.Status = status
+	commit.Message = message
+	commit.FinishedAt = &now
+	if err := commit.Update(m.DB); err != nil {
+		log.Warningf("update image commit {%s} fail: %s", commit.ID, err.Error())
+		return
+	}
+	log.Infof("image commit {%s} of job {%s} into {%s} is %s", commit.ID, commit.JobID, commit.Image, status)
+}
+
+// builderPod returns the latest pod of builder job.
+func (m *ImageCommitter) builderPod(jobName string) (*v1.Pod, error) {
+	pods, err := m.KClientSet.CoreV1().Pods(consts.AiTrainSystemNamespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: "job-name=" + jobName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *v1.Pod
+	for i := range pods.Items {
+		if latest == nil || latest.CreationTimestamp.Before(&pods.Items[i].CreationTimestamp) {
+			latest = &pods.Items[i]
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("pod of builder job {%s} is not found", jobName)
+	}
+	return latest, nil
+}
+
+// builderLogs returns at most maxCommitLogBytes from the end of builder logs.
+func (m *ImageCommitter) builderLogs(jobName string) (string, error) {
+	pod, err := m.builderPod(jobName)
+	if err != nil {
+		return "", err
+	}
+
+	// LimitBytes of PodLogOptions returns head of logs, so the whole stream is read and only its tail is kept
+	stream, err := m.KClientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{}).Stream(context.Background())
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	data, err := tailBytes(stream, maxCommitLogBytes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// tailBytes reads r to the end and returns its last n bytes, with at most 2n bytes buffered.
+func tailBytes(r io.Reader, n int) ([]byte, error) {
+	buf := make([]byte, 0, 2*n)
+	chunk := make([]byte, n)
+	for {
+		read, err := r.Read(chunk)
+		buf = append(buf, chunk[:read]...)
+		if len(buf) > n {
+			buf = append(buf[:0], buf[len(buf)-n:]...)
+		}
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// containerID returns id of the first container of pod in container runtime, without runtime prefix.
+func containerID(pod *v1.Pod, runtime string) (string, error) {
+	if len(pod.Status.ContainerStatuses) == 0 || pod.Status.ContainerStatuses[0].ContainerID == "" {
+		return "", fmt.Errorf("container of pod {%s} is not started", pod.Name)
+	}
+
+	id := pod.Status.ContainerStatuses[0].ContainerID
+	prefix := runtime + "://"
+	if !strings.HasPrefix(id, prefix) {
+		return "", fmt.Errorf("container {%s} is not run by %s", id, runtime)
+	}
+	return strings.TrimPrefix(id, prefix), nil
+}
+
+// newBuilderJob returns job committing container of pod and pushing image, which runs on the node of pod
+// and uses container runtime of the node through its socket.
+func (m *ImageCommitter) newBuilderJob(commit *db.ImageCommit, pod *v1.Pod) (*batchv1.Job, error) {
+	cfg := m.config.APIConfig.Snapshot
+
+	runtime := cfg.Runtime
+	if runtime == "" {
+		runtime = runtimeDocker
+	}
+	socket := cfg.RuntimeSocket
+	if socket == "" {
+		socket = defaultRuntimeSockets[runtime]
+	}
+
+	var cli, image string
+	switch runtime {
+	case runtimeDocker:
+		cli = fmt.Sprintf("docker -H unix://%s", socket)
+		image = cfg.BuilderImage
+		if image == "" {
+			image = defaultCommitBuilderImage
+		}
+	case runtimeContainerd:
+		cli = fmt.Sprintf("nerdctl --address %s --namespace k8s.io", socket)
+		image = cfg.BuilderImage
+		if image == "" {
+			return nil, errors.New("builderImage with nerdctl is required for containerd runtime")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported container runtime {%s}", runtime)
+	}
+
+	id, err := containerID(pod, runtime)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultCommitTimeout
+	}
+	ttl := commitBuilderTTL
+	socketType := v1.HostPathSocket
+
+	container := v1.Container{
+		Name:  "commit",
+		Image: image,
+		// container and image are passed in env, so they are never interpreted by shell
+		Command: []string{"sh", "-c", fmt.Sprintf(`%[1]s commit "$CONTAINER" "$IMAGE" && %[1]s push "$IMAGE"`, cli)},
+		Env: []v1.EnvVar{
+			{Name: "CONTAINER", Value: id},
+			{Name: "IMAGE", Value: commit.Image},
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{Name: "runtime", MountPath: socket},
+		},
+	}
+	volumes := []v1.Volume{
+		{
+			Name: "runtime",
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{Path: socket, Type: &socketType},
+			},
+		},
+	}
+
+	if cfg.PushSecret != "" {
+		container.Env = append(container.Env, v1.EnvVar{Name: "DOCKER_CONFIG", Value: commitDockerConfigPath})
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      "push-secret",
+			MountPath: commitDockerConfigPath,
+			ReadOnly:  true,
+		})
+		volumes = append(volumes, v1.Volume{
+			Name: "push-secret",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: cfg.PushSecret,
+					Items:      []v1.KeyToPath{{Key: v1.DockerConfigJsonKey, Path: "config.json"}},
+				},
+			},
+		})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("image-commit-%s", commit.ID[:8]),
+			Namespace: consts.AiTrainSystemNamespace,
+			Labels: map[string]string{
+				consts.ImageCommitLabel: commit.ID,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            util.Int32Ptr(0),
+			ActiveDeadlineSeconds:   util.Int64Ptr(int64(timeout) * 60),
+			TTLSecondsAfterFinished: &ttl,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					NodeName:      pod.Spec.NodeName,
+					Containers:    []v1.Container{container},
+					Volumes:       volumes,
+				},
+			},
+		},
+	}, nil
+}
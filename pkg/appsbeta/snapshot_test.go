@@ -0,0 +1,39 @@
+package beta
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		n        int
+		expected string
+	}{
+		{name: "empty", data: "", n: 4, expected: ""},
+		{name: "shorter than limit", data: "abc", n: 4, expected: "abc"},
+		{name: "equal to limit", data: "abcd", n: 4, expected: "abcd"},
+		{name: "longer than limit", data: "head\nmiddle\ntail", n: 4, expected: "tail"},
+		{name: "many chunks", data: strings.Repeat("x", 100) + "end", n: 5, expected: "xxend"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := tailBytes(strings.NewReader(tc.data), tc.n)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+
+			// reader returning one byte at a time
+			data, err = tailBytes(iotest.OneByteReader(strings.NewReader(tc.data)), tc.n)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+
+	_, err := tailBytes(iotest.TimeoutReader(bytes.NewReader([]byte("abcdefgh"))), 4)
+	assert.Error(t, err)
+}
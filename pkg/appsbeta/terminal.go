@@ -0,0 +1,335 @@
+package beta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/gorilla/websocket"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/backend-api/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	terminalIdleDefault = 15 * time.Minute
+	terminalIdleCheck   = 30 * time.Second
+	terminalWriteWait   = 10 * time.Second
+)
+
+const (
+	terminalOpStdin  = "stdin"
+	terminalOpResize = "resize"
+	terminalOpStdout = "stdout"
+	terminalOpError  = "error"
+)
+
+// prefer bash, fall back to sh for minimal images
+var terminalShell = []string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash || exec sh"}
+
+// terminalUpgrader only accepts websocket from UI, so other sites cannot open terminal with token of user.
+func (j *Job) terminalUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin: func(r *http.Request) bool {
+			return isTerminalOrigin(r, j.config.APIConfig.Provider.RedirectURL)
+		},
+	}
+}
+
+// isTerminalOrigin checks origin of websocket request is the same as api server, or origin of uiURL,
+// which is oauth redirect url of UI. Request without origin is not from browser and is allowed.
+func isTerminalOrigin(r *http.Request, uiURL string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		log.Warningf("reject terminal from invalid origin {%s}: %s", origin, err.Error())
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	if ui, err := url.Parse(uiURL); err == nil && ui.Host != "" &&
+		strings.EqualFold(u.Scheme, ui.Scheme) && strings.EqualFold(u.Host, ui.Host) {
+		return true
+	}
+
+	log.Warningf("reject terminal from origin {%s}, which is not ui {%s}", origin, uiURL)
+	return false
+}
+
+// terminalSession bridges browser websocket and stdin/stdout of exec stream.
+// It implements io.Reader, io.Writer and remotecommand.TerminalSizeQueue.
+type terminalSession struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	sizeCh  chan remotecommand.TerminalSize
+	done    chan struct{}
+	pending []byte
+	// unix nano of last input from browser
+	lastInput int64
+}
+
+func newTerminalSession(conn *websocket.Conn) *terminalSession {
+	return &terminalSession{
+		conn:      conn,
+		sizeCh:    make(chan remotecommand.TerminalSize, 1),
+		done:      make(chan struct{}),
+		lastInput: time.Now().UnixNano(),
+	}
+}
+
+func (t *terminalSession) Read(p []byte) (int, error) {
+	for len(t.pending) == 0 {
+		_, raw, err := t.conn.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+
+		msg := model.TerminalMessage{}
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			log.Warningf("invalid terminal message: %s", err.Error())
+			continue
+		}
+		atomic.StoreInt64(&t.lastInput, time.Now().UnixNano())
+
+		switch msg.Op {
+		case terminalOpStdin:
+			t.pending = []byte(msg.Data)
+		case terminalOpResize:
+			size := remotecommand.TerminalSize{Width: msg.Cols, Height: msg.Rows}
+			// keep latest size only
+			select {
+			case <-t.sizeCh:
+			default:
+			}
+			t.sizeCh <- size
+		default:
+			log.Warningf("unknown terminal message op {%s}", msg.Op)
+		}
+	}
+
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
+func (t *terminalSession) Write(p []byte) (int, error) {
+	if err := t.send(terminalOpStdout, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Next returns terminal size requested by browser, nil means stream is closed.
+func (t *terminalSession) Next() *remotecommand.TerminalSize {
+	select {
+	case size := <-t.sizeCh:
+		return &size
+	case <-t.done:
+		return nil
+	}
+}
+
+func (t *terminalSession) send(op, data string) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.conn.SetWriteDeadline(time.Now().Add(terminalWriteWait))
+	return t.conn.WriteJSON(model.TerminalMessage{Op: op, Data: data})
+}
+
+func (t *terminalSession) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&t.lastInput)))
+}
+
+func (t *terminalSession) close(reason string) {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason),
+		time.Now().Add(terminalWriteWait))
+	t.conn.Close()
+}
+
+// @Summary Open terminal in container job
+// @Description Upgrade to websocket and attach a TTY shell to job pod. Messages are TerminalMessage in json.
+// @Description Browser sends {"op":"stdin","data":"ls\r"} and {"op":"resize","cols":80,"rows":24}, server sends {"op":"stdout","data":"..."}.
+// @Description Since browser can not set Authorization header of websocket, token is passed in access_token query when secure api is enabled.
+// @Description Access token is redacted from request log, and websocket is only accepted from origin of UI, which is origin of oauth redirect url.
+// @Description Terminal is closed after no input for idle timeout in api server config.
+// @Tags Job
+// @Param id path string true "course CRD uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param pod query string false "pod name, default is the first running pod of job"
+// @Param container query string false "container name, default is the only container of pod"
+// @Param access_token query string false "bearer token"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/exec/{id} [get]
+func (j *Job) Exec(c *gin.Context) {
+	job, ok := j.findJobForUser(c)
+	if !ok {
+		return
+	}
+
+	pod, ok := j.runningJobPod(c, job)
+	if !ok {
+		return
+	}
+
+	executor, err := j.podExecutor(pod, c.Query("container"), terminalShell, true, true)
+	if err != nil {
+		errStr := fmt.Sprintf("create exec of pod {%s} fail: %s", pod.Name, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	conn, err := j.terminalUpgrader().Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// upgrader already replies error to client
+		log.Errorf("upgrade terminal of job {%s} to websocket fail: %s", job.ID, err.Error())
+		return
+	}
+
+	if err := db.TouchJob(j.DB, job.ID); err != nil {
+		log.Warningf("update last active time of job {%s} fail: %s", job.ID, err.Error())
+	}
+
+	session := newTerminalSession(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	idleTimeout := terminalIdleDefault
+	if j.config.APIConfig.TerminalIdle > 0 {
+		idleTimeout = time.Duration(j.config.APIConfig.TerminalIdle) * time.Minute
+	}
+	go j.watchTerminalIdle(ctx, cancel, session, job, idleTimeout)
+
+	log.Infof("open terminal of job {%s} pod {%s}", job.ID, pod.Name)
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:             session,
+		Stdout:            session,
+		Tty:               true,
+		TerminalSizeQueue: session,
+	})
+	close(session.done)
+
+	reason := "terminal is closed"
+	if err != nil && ctx.Err() == nil {
+		reason = fmt.Sprintf("exec in pod {%s} fail: %s", pod.Name, err.Error())
+		log.Warningf(reason)
+		session.send(terminalOpError, reason)
+	}
+	session.close(reason)
+	log.Infof("close terminal of job {%s} pod {%s}", job.ID, pod.Name)
+}
+
+// runningJobPod finds running pod of job by pod query, or the first running pod if not given.
+// Error response is written if pod is not found.
+func (j *Job) runningJobPod(c *gin.Context, job *db.Job) (*v1.Pod, bool) {
+	course, err := j.CourseCrdClient.NchcV1alpha1().Courses(*job.ClassroomID).Get(context.Background(), job.ID, metav1.GetOptions{})
+	if err != nil {
+		errStr := fmt.Sprintf("Get Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return nil, false
+	}
+
+	pods, err := j.findJobPods(course)
+	if err != nil {
+		errStr := fmt.Sprintf("Find pods of Course CRD {%s} fail: %s", job.ID, err.Error())
+		log.Errorf(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return nil, false
+	}
+
+	podName := c.Query("pod")
+	for i := range pods {
+		if pods[i].Status.Phase != v1.PodRunning {
+			continue
+		}
+		if podName == "" || pods[i].Name == podName {
+			return &pods[i], true
+		}
+	}
+
+	RespondWithError(c, http.StatusBadRequest, "running pod {%s} of job {%s} is not found", podName, job.ID)
+	return nil, false
+}
+
+// podExecutor creates executor running command in container of pod, stdout is always attached.
+func (j *Job) podExecutor(pod *v1.Pod, container string, command []string, stdin, tty bool) (remotecommand.Executor, error) {
+	restConfig, err := util.GetConfig(j.config.APIConfig.IsOutsideCluster, j.config.K8SConfig.KUBECONFIG)
+	if err != nil {
+		return nil, fmt.Errorf("create kubenetes config fail: %s", err.Error())
+	}
+
+	req := j.KClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin,
+			Stdout:    true,
+			Stderr:    !tty,
+			TTY:       tty,
+		}, scheme.ParameterCodec)
+
+	return remotecommand.NewSPDYExecutor(restConfig, http.MethodPost, req.URL())
+}
+
+// watchTerminalIdle closes terminal after no input for idleTimeout,
+// and keeps job from being reaped for idle while user is typing.
+func (j *Job) watchTerminalIdle(ctx context.Context, cancel context.CancelFunc, session *terminalSession,
+	job *db.Job, idleTimeout time.Duration) {
+	ticker := time.NewTicker(terminalIdleCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			idle := session.idleFor()
+			if idle >= idleTimeout {
+				log.Infof("terminal of job {%s} is idle for %s, close it", job.ID, idle)
+				session.send(terminalOpError, fmt.Sprintf("terminal is closed after idle for %s", idleTimeout))
+				cancel()
+				// unblock reading stdin from browser
+				session.close("idle timeout")
+				return
+			}
+			if idle < terminalIdleCheck {
+				if err := db.TouchJob(j.DB, job.ID); err != nil {
+					log.Warningf("update last active time of job {%s} fail: %s", job.ID, err.Error())
+				}
+			}
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"regexp"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nchc-ai/backend-api/pkg/consts"
 	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
 )
 
 func RespondWithError(c *gin.Context, code int, format string, args ...interface{}) {
@@ -22,6 +24,15 @@ func RespondWithOk(c *gin.Context, format string, args ...interface{}) {
 	c.Abort()
 }
 
+func RespondWithForbidden(c *gin.Context, reason string, format string, args ...interface{}) {
+	c.JSON(http.StatusForbidden, model.ForbiddenResponse{
+		Error:   true,
+		Message: fmt.Sprintf(format, args...),
+		Reason:  reason,
+	})
+	c.Abort()
+}
+
 func genericResponse(isError bool, format string, args ...interface{}) model.GenericResponse {
 	resp := model.GenericResponse{
 		Error:   isError,
@@ -30,6 +41,25 @@ func genericResponse(isError bool, format string, args ...interface{}) model.Gen
 	return resp
 }
 
+// getLoginUser returns local user resolved from bearer token by authMiddleware.
+// If secure api is disabled, authMiddleware is not used and no login user is found.
+func getLoginUser(c *gin.Context) (*db.User, bool) {
+	u, exist := c.Get(consts.ContextLoginUser)
+	if !exist {
+		return nil, false
+	}
+	return u.(*db.User), true
+}
+
+// callerName returns user name of login user, user field in request body is only trusted
+// when secure api is disabled.
+func callerName(c *gin.Context, reqUser string) string {
+	if u, ok := getLoginUser(c); ok {
+		return u.User
+	}
+	return reqUser
+}
+
 const dns1035LabelFmt string = "[a-z]([-a-z0-9]*[a-z0-9])?"
 
 var dns1035LabelRegexp = regexp.MustCompile("^" + dns1035LabelFmt + "$")
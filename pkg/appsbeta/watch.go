@@ -0,0 +1,235 @@
+package beta
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+)
+
+const (
+	jobEventHistory   = 512
+	jobEventBuffer    = 16
+	jobWatchHeartbeat = 30 * time.Second
+)
+
+const (
+	jobEventStatus  = "status"
+	jobEventWarning = "warning"
+)
+
+type jobEvent struct {
+	id    uint64
+	owner string
+	event string
+	data  interface{}
+}
+
+// jobEventHub fans out job status changes to watchers of job owner,
+// and keeps recent events for watchers reconnecting with last event id.
+type jobEventHub struct {
+	mu          sync.Mutex
+	seq         uint64
+	history     []jobEvent
+	subscribers map[string]map[chan jobEvent]struct{}
+}
+
+func newJobEventHub() *jobEventHub {
+	return &jobEventHub{
+		// start from boot time, so ids issued before restart are always older than history
+		seq:         uint64(time.Now().UnixNano()),
+		subscribers: make(map[string]map[chan jobEvent]struct{}),
+	}
+}
+
+func jobOwnerKey(user, provider string) string {
+	return fmt.Sprintf("%s:%s", provider, user)
+}
+
+// publish sends event with data to all watchers of owner, data is either model.JobStatus or model.JobWarning.
+func (h *jobEventHub) publish(owner string, event string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	e := jobEvent{id: h.seq, owner: owner, event: event, data: data}
+
+	h.history = append(h.history, e)
+	if len(h.history) > jobEventHistory {
+		h.history = h.history[len(h.history)-jobEventHistory:]
+	}
+
+	for ch := range h.subscribers[owner] {
+		select {
+		case ch <- e:
+		default:
+			// slow watcher, close stream and let it reconnect with last event id
+			log.Warningf("watcher of {%s} is too slow, close stream", owner)
+			delete(h.subscribers[owner], ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a watcher of owner. Missed events after lastID are returned if they are still in history,
+// otherwise replay is false and caller should send current state.
+func (h *jobEventHub) subscribe(owner string, lastID uint64) (ch chan jobEvent, missed []jobEvent, current uint64, replay bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch = make(chan jobEvent, jobEventBuffer)
+	if h.subscribers[owner] == nil {
+		h.subscribers[owner] = make(map[chan jobEvent]struct{})
+	}
+	h.subscribers[owner][ch] = struct{}{}
+
+	oldest := h.seq + 1
+	if len(h.history) > 0 {
+		oldest = h.history[0].id
+	}
+	if lastID == 0 || lastID+1 < oldest || lastID > h.seq {
+		return ch, nil, h.seq, false
+	}
+
+	for _, e := range h.history {
+		if e.id > lastID && e.owner == owner {
+			missed = append(missed, e)
+		}
+	}
+	return ch, missed, h.seq, true
+}
+
+func (h *jobEventHub) unsubscribe(owner string, ch chan jobEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[owner][ch]; ok {
+		delete(h.subscribers[owner], ch)
+		close(ch)
+	}
+	if len(h.subscribers[owner]) == 0 {
+		delete(h.subscribers, owner)
+	}
+}
+
+func toJobStatus(job db.Job) model.JobStatus {
+	return model.JobStatus{
+		JobId:  job.ID,
+		Ready:  job.Status == JobStatueReady,
+		Status: job.Status,
+	}
+}
+
+// @Summary Watch status change of user's container jobs
+// @Description Server-Sent Events stream of job status. Each "status" event is a JobStatus in json with id.
+// @Description "warning" event is a JobWarning sent before job is deleted for reaching maximum runtime or idle timeout.
+// @Description Reconnect with Last-Event-ID header to receive missed events, otherwise current status of all jobs is sent first.
+// @Tags Job
+// @Produce  text/event-stream
+// @Param user query string false "user to watch, only used when secure api is disabled"
+// @Param Last-Event-ID header string false "id of last received event"
+// @Success 200 {object} docs.JobStatus
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/job/watch [get]
+func (j *Job) Watch(c *gin.Context) {
+	provider, exist := c.Get("Provider")
+	if exist == false {
+		provider = db.DEFAULT_PROVIDER
+	}
+
+	user := callerName(c, c.Query("user"))
+	if user == "" {
+		log.Errorf("Empty user name")
+		RespondWithError(c, http.StatusBadRequest, "Empty user name")
+		return
+	}
+
+	lastID := uint64(0)
+	if v := c.GetHeader("Last-Event-ID"); v != "" {
+		lastID, _ = strconv.ParseUint(v, 10, 64)
+	}
+
+	owner := jobOwnerKey(user, provider.(string))
+	hub := j.statusCtrl.events
+	ch, missed, current, replay := hub.subscribe(owner, lastID)
+	defer hub.unsubscribe(owner, ch)
+
+	if !replay {
+		job := db.Job{
+			OauthUser: db.OauthUser{
+				User:     user,
+				Provider: provider.(string),
+			},
+		}
+		jobs, err := job.GetJobOwnByUser(j.DB)
+		if err != nil {
+			strErr := fmt.Sprintf("Query Job table for user {%s} fail: %s", user, err.Error())
+			log.Errorf(strErr)
+			RespondWithError(c, http.StatusInternalServerError, strErr)
+			return
+		}
+		for _, job := range jobs {
+			missed = append(missed, jobEvent{id: current, owner: owner, event: jobEventStatus, data: toJobStatus(job)})
+		}
+		queued, err := j.queue.userQueue(user, provider.(string))
+		if err != nil {
+			log.Warningf("List queued job of user {%s} fail: %s", user, err.Error())
+		}
+		for _, q := range queued {
+			missed = append(missed, jobEvent{id: current, owner: owner, event: jobEventStatus, data: model.JobStatus{
+				JobId:         q.Id,
+				Ready:         false,
+				Status:        JobStatusQueued,
+				QueuePosition: q.QueuePosition,
+			}})
+		}
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, e := range missed {
+		renderJobEvent(c, e)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(jobWatchHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return false
+			}
+			renderJobEvent(c, e)
+			return true
+		case <-heartbeat.C:
+			c.Render(-1, sse.Event{Event: "ping", Data: ""})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func renderJobEvent(c *gin.Context, e jobEvent) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(e.id, 10),
+		Event: e.event,
+		Data:  e.data,
+	})
+}
@@ -0,0 +1,111 @@
+package beta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Webhook is mutating admission webhook of deployments. Course controller builds deployment from fields of Course CRD spec only,
+// so resources, node selector and env of job kept in CRD annotations are applied to pod template when deployment is created,
+// instead of updating deployment owned by course controller afterwards.
+type Webhook struct {
+	CourseCrdClient versioned.Interface
+}
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MutateDeployment answers AdmissionReview of deployment creation. Creation is rejected if Course CRD owning deployment
+// can not be queried or has invalid annotations, so course controller retries rather than runs job without its settings.
+func (w *Webhook) MutateDeployment(c *gin.Context) {
+	review := admissionv1.AdmissionReview{}
+	if err := c.BindJSON(&review); err != nil || review.Request == nil {
+		log.Errorf("invalid admission review: %v", err)
+		RespondWithError(c, http.StatusBadRequest, "invalid admission review")
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+	patch, err := w.deploymentPatch(review.Request)
+	if err != nil {
+		log.Errorf("mutate deployment {%s/%s} fail: %s", review.Request.Namespace, review.Request.Name, err.Error())
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+		}
+	} else if patch != nil {
+		patchType := admissionv1.PatchTypeJSONPatch
+		response.Patch = patch
+		response.PatchType = &patchType
+	}
+
+	review.Request = nil
+	review.Response = response
+	c.JSON(http.StatusOK, review)
+}
+
+// deploymentPatch returns json patch replacing pod template spec of deployment with annotations of Course CRD applied,
+// nil if deployment is not created for a Course CRD or there is nothing to apply.
+func (w *Webhook) deploymentPatch(req *admissionv1.AdmissionRequest) ([]byte, error) {
+	if req.Operation != admissionv1.Create || req.Kind.Group != appsv1.GroupName || req.Kind.Kind != "Deployment" {
+		return nil, nil
+	}
+
+	deployment := appsv1.Deployment{}
+	if err := json.Unmarshal(req.Object.Raw, &deployment); err != nil {
+		return nil, fmt.Errorf("decode deployment fail: %s", err.Error())
+	}
+	owner := courseOwner(deployment.OwnerReferences)
+	if owner == nil {
+		return nil, nil
+	}
+
+	course, err := w.CourseCrdClient.NchcV1alpha1().Courses(req.Namespace).Get(context.Background(), owner.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// CRD is deleted before its deployment is created, deployment is garbage collected anyway
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get Course CRD {%s} fail: %s", owner.Name, err.Error())
+	}
+	if course.UID != owner.UID || !hasCourseAnnotations(course) {
+		return nil, nil
+	}
+
+	spec := deployment.Spec.Template.Spec.DeepCopy()
+	if err := applyPodAnnotations(spec, course.Annotations); err != nil {
+		return nil, fmt.Errorf("apply annotations of Course CRD {%s} fail: %s", course.Name, err.Error())
+	}
+	log.Infof("annotations of Course CRD {%s} are applied to deployment {%s/%s}", course.Name, req.Namespace, deployment.Name)
+	return json.Marshal([]jsonPatchOperation{
+		{Op: "replace", Path: "/spec/template/spec", Value: spec},
+	})
+}
+
+// courseOwner returns owner reference of Course CRD, nil if object is not owned by Course CRD.
+func courseOwner(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Kind == "Course" && refs[i].APIVersion == v1alpha1.SchemeGroupVersion.String() {
+			return &refs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package beta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
+	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func deploymentAdmission(t *testing.T, d *appsv1.Deployment) *admissionv1.AdmissionRequest {
+	raw, err := json.Marshal(d)
+	assert.NoError(t, err)
+	return &admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace: d.Namespace,
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func TestWebhook_deploymentPatch(t *testing.T) {
+	course := &v1alpha1.Course{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job-1",
+			Namespace: "classroom-1",
+			UID:       "course-uid",
+			Annotations: map[string]string{
+				consts.CourseAnnotationResources:    `{"limits":{"cpu":"2"},"requests":{"cpu":"1"}}`,
+				consts.CourseAnnotationNodeSelector: `{"gpu":"v100"}`,
+				consts.CourseAnnotationEnv:          `[{"name":"MODE","value":"course"},{"name":"TOKEN","value":"t"}]`,
+			},
+		},
+	}
+	webhook := Webhook{CourseCrdClient: fake.NewSimpleClientset(course)}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "job-1",
+			Namespace: "classroom-1",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "Course", Name: "job-1", UID: "course-uid"},
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "job",
+						Env:  []v1.EnvVar{{Name: "MODE", Value: "controller"}, {Name: "HOME", Value: "/home"}},
+						Resources: v1.ResourceRequirements{
+							Limits: v1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	patch, err := webhook.deploymentPatch(deploymentAdmission(t, deployment))
+	assert.NoError(t, err)
+	ops := []struct {
+		Op    string
+		Path  string
+		Value v1.PodSpec
+	}{}
+	assert.NoError(t, json.Unmarshal(patch, &ops))
+	assert.Len(t, ops, 1)
+	assert.Equal(t, "replace", ops[0].Op)
+	assert.Equal(t, "/spec/template/spec", ops[0].Path)
+
+	spec := ops[0].Value
+	assert.Equal(t, map[string]string{"gpu": "v100"}, spec.NodeSelector)
+	c := spec.Containers[0]
+	// env of the same name is replaced, others are kept
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "MODE", Value: "course"},
+		{Name: "HOME", Value: "/home"},
+		{Name: "TOKEN", Value: "t"},
+	}, c.Env)
+	// gpu set by course controller is kept
+	assert.True(t, resource.MustParse("1").Equal(c.Resources.Limits["nvidia.com/gpu"]))
+	assert.True(t, resource.MustParse("2").Equal(c.Resources.Limits[v1.ResourceCPU]))
+	assert.True(t, resource.MustParse("1").Equal(c.Resources.Requests[v1.ResourceCPU]))
+
+	// deployment not owned by Course CRD is left unchanged
+	deployment.OwnerReferences = nil
+	patch, err = webhook.deploymentPatch(deploymentAdmission(t, deployment))
+	assert.NoError(t, err)
+	assert.Nil(t, patch)
+
+	// Course CRD of another uid is regarded as deleted
+	deployment.OwnerReferences = []metav1.OwnerReference{
+		{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "Course", Name: "job-1", UID: "old-uid"},
+	}
+	patch, err = webhook.deploymentPatch(deploymentAdmission(t, deployment))
+	assert.NoError(t, err)
+	assert.Nil(t, patch)
+
+	// invalid annotation rejects creation
+	course.Annotations[consts.CourseAnnotationEnv] = "{"
+	webhook.CourseCrdClient = fake.NewSimpleClientset(course)
+	deployment.OwnerReferences[0].UID = "course-uid"
+	_, err = webhook.deploymentPatch(deploymentAdmission(t, deployment))
+	assert.Error(t, err)
+}
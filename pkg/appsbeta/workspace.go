@@ -0,0 +1,412 @@
+package beta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/golang/glog"
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/backend-api/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultWorkspaceResetImage = "busybox"
+	workspaceMountPath         = "/workspace"
+	// finished reset job is removed by kubernetes after ttl
+	workspaceResetTTL = int32(600)
+)
+
+// Workspace manages writable volumes created for courses with WritablePath.
+// Volumes are identified by labels set by job status controller, see labelWorkspace.
+type Workspace struct {
+	DB         *gorm.DB
+	KClientSet *kubernetes.Clientset
+	config     *config.Config
+}
+
+// @Summary List someone's workspaces
+// @Description List writable volumes of user in all classrooms, with size and last time used by container job.
+// @Tags Workspace
+// @Accept  json
+// @Produce  json
+// @Param user query string false "user name, only used when secure api is disabled"
+// @Success 200 {object} docs.WorkspaceListResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/workspace/list [get]
+func (w *Workspace) List(c *gin.Context) {
+	provider, exist := c.Get("Provider")
+	if !exist {
+		provider = db.DEFAULT_PROVIDER
+	}
+
+	user := callerName(c, c.Query("user"))
+	if user == "" {
+		log.Errorf("Empty user name")
+		RespondWithError(c, http.StatusBadRequest, "Empty user name")
+		return
+	}
+
+	workspaces, err := w.listWorkspaces(user, provider.(string))
+	if err != nil {
+		errStr := fmt.Sprintf("List workspaces of user {%s} fail: %s", user, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.WorkspaceListResponse{
+		Error:      false,
+		Workspaces: workspaces,
+	})
+}
+
+// @Summary Delete workspace
+// @Description Delete writable volume and all files in it. A new empty volume is created when course is launched again in the classroom.
+// @Tags Workspace
+// @Accept  json
+// @Produce  json
+// @Param classroom path string true "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param name path string true "workspace name"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/workspace/delete/{classroom}/{name} [delete]
+func (w *Workspace) Delete(c *gin.Context) {
+	pvc, ok := w.findIdleWorkspace(c)
+	if !ok {
+		return
+	}
+
+	if err := w.KClientSet.CoreV1().PersistentVolumeClaims(pvc.Namespace).
+		Delete(context.Background(), pvc.Name, metav1.DeleteOptions{}); err != nil {
+		log.Errorf("Delete workspace {%s/%s} fail: %s", pvc.Namespace, pvc.Name, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, consts.ERROR_WORKSPACE_DELETE_FMT, pvc.Name)
+		return
+	}
+
+	log.Infof("workspace {%s/%s} of user {%s} is deleted", pvc.Namespace, pvc.Name, workspaceOwner(pvc))
+	RespondWithOk(c, "Workspace {%s} is deleted successfully", pvc.Name)
+}
+
+// @Summary Reset workspace to clean state
+// @Description Remove all files in writable volume by a job running in classroom namespace, volume itself is kept.
+// @Description Reset is done in background, course should not be launched in the classroom until it is finished.
+// @Tags Workspace
+// @Accept  json
+// @Produce  json
+// @Param classroom path string true "classroom uuid, eg: 131ba8a9-b60b-44f9-83b5-46590f756f41"
+// @Param name path string true "workspace name"
+// @Success 200 {object} docs.GenericOKResponse
+// @Failure 400 {object} docs.GenericErrorResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/workspace/reset/{classroom}/{name} [post]
+func (w *Workspace) Reset(c *gin.Context) {
+	pvc, ok := w.findIdleWorkspace(c)
+	if !ok {
+		return
+	}
+
+	if _, err := w.KClientSet.BatchV1().Jobs(pvc.Namespace).
+		Create(context.Background(), w.newResetJob(pvc), metav1.CreateOptions{}); err != nil {
+		log.Errorf("Create reset job of workspace {%s/%s} fail: %s", pvc.Namespace, pvc.Name, err.Error())
+		RespondWithError(c, http.StatusInternalServerError, consts.ERROR_WORKSPACE_RESET_FMT, pvc.Name)
+		return
+	}
+
+	log.Infof("reset of workspace {%s/%s} of user {%s} is started", pvc.Namespace, pvc.Name, workspaceOwner(pvc))
+	RespondWithOk(c, "Reset of workspace {%s} is started", pvc.Name)
+}
+
+// @Summary Delete workspaces of users who are no longer enrolled
+// @Description Find writable volumes whose owner is neither student nor teacher of the classroom, or is not a user anymore
+// @Description in public and teacher classroom. Workspaces are only reported in dry run, which is the default, otherwise they are deleted.
+// @Tags Workspace
+// @Accept  json
+// @Produce  json
+// @Param dryRun query bool false "only report workspaces, default true"
+// @Success 200 {object} docs.WorkspaceGCResponse
+// @Failure 401 {object} docs.GenericErrorResponse
+// @Failure 403 {object} docs.ForbiddenResponse
+// @Failure 500 {object} docs.GenericErrorResponse
+// @Security ApiKeyAuth
+// @Router /beta/workspace/gc [post]
+func (w *Workspace) GarbageCollect(c *gin.Context) {
+	dryRun := c.Query("dryRun") != "false"
+
+	collected, err := w.collect(dryRun)
+	if err != nil {
+		log.Errorf("garbage collect workspaces fail: %s", err.Error())
+		RespondWithError(c, http.StatusInternalServerError, "garbage collect workspaces fail: %s", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, model.WorkspaceGCResponse{
+		Error:      false,
+		DryRun:     dryRun,
+		Workspaces: collected,
+	})
+}
+
+// listWorkspaces returns writable volumes of user of provider, or of all users if user is empty.
+func (w *Workspace) listWorkspaces(user, provider string) ([]model.WorkspaceInfo, error) {
+	pvcs, err := w.workspacePVCs()
+	if err != nil {
+		return nil, err
+	}
+
+	uses := map[string]map[string]*db.WorkspaceUse{}
+	result := []model.WorkspaceInfo{}
+	for _, pvc := range pvcs {
+		owner := workspaceOwner(&pvc)
+		if user != "" && (owner != user || workspaceProvider(&pvc) != provider) {
+			continue
+		}
+
+		if _, ok := uses[owner]; !ok {
+			u, err := db.UserWorkspaceUse(w.DB, owner)
+			if err != nil {
+				return nil, err
+			}
+			uses[owner] = u
+		}
+
+		info := workspaceInfo(&pvc)
+		if use, ok := uses[owner][pvc.Namespace]; ok {
+			info.LastUsedAt = use.LastUsedAt
+			info.InUse = use.InUse
+		}
+		cm, err := (&db.ClassRoomInfo{Model: db.Model{ID: pvc.Namespace}}).GetClassRoomDetail(w.DB)
+		if err == nil {
+			info.ClassroomName = cm.Name
+		}
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, k int) bool {
+		if result[i].Owner != result[k].Owner {
+			return result[i].Owner < result[k].Owner
+		}
+		return result[i].CreatedAt.Before(result[k].CreatedAt)
+	})
+	return result, nil
+}
+
+func (w *Workspace) workspacePVCs() ([]v1.PersistentVolumeClaim, error) {
+	pvcs, err := w.KClientSet.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(
+		context.Background(), metav1.ListOptions{LabelSelector: consts.WorkspaceLabel})
+	if err != nil {
+		return nil, err
+	}
+	return pvcs.Items, nil
+}
+
+// findIdleWorkspace finds workspace in path, checks caller owns it and it is not used by running job.
+// Error response is written if workspace can not be changed.
+func (w *Workspace) findIdleWorkspace(c *gin.Context) (*v1.PersistentVolumeClaim, bool) {
+	classroomID, name := c.Param("classroom"), c.Param("name")
+	if classroomID == "" || name == "" {
+		RespondWithError(c, http.StatusBadRequest, "Classroom Id or workspace name is empty")
+		return nil, false
+	}
+
+	pvc, err := w.KClientSet.CoreV1().PersistentVolumeClaims(classroomID).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		log.Errorf("Query workspace {%s/%s} fail: %s", classroomID, name, err.Error())
+		RespondWithError(c, http.StatusBadRequest, "Query workspace {%s/%s} fail: %s", classroomID, name, err.Error())
+		return nil, false
+	}
+	if _, ok := pvc.Labels[consts.WorkspaceLabel]; !ok {
+		RespondWithError(c, http.StatusBadRequest, "{%s/%s} is not a workspace", classroomID, name)
+		return nil, false
+	}
+
+	owner := workspaceOwner(pvc)
+	if !checkWorkspaceOwner(c, owner, workspaceProvider(pvc), name) {
+		return nil, false
+	}
+
+	uses, err := db.UserWorkspaceUse(w.DB, owner)
+	if err != nil {
+		errStr := fmt.Sprintf("Query jobs of user {%s} fail: %s", owner, err.Error())
+		log.Error(errStr)
+		RespondWithError(c, http.StatusInternalServerError, errStr)
+		return nil, false
+	}
+	if use, ok := uses[classroomID]; ok && use.InUse {
+		log.Errorf("workspace {%s/%s} is used by running job of user {%s}", classroomID, name, owner)
+		RespondWithError(c, http.StatusBadRequest, consts.ERROR_WORKSPACE_IN_USE_FMT, classroomID, name)
+		return nil, false
+	}
+
+	return pvc, true
+}
+
+func (w *Workspace) newResetJob(pvc *v1.PersistentVolumeClaim) *batchv1.Job {
+	image := w.config.APIConfig.Workspace.ResetImage
+	if image == "" {
+		image = defaultWorkspaceResetImage
+	}
+	ttl := workspaceResetTTL
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("workspace-reset-%s", uuid.New().String()[:8]),
+			Namespace: pvc.Namespace,
+			Labels: map[string]string{
+				consts.WorkspaceResetLabel: pvc.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            util.Int32Ptr(2),
+			TTLSecondsAfterFinished: &ttl,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					Containers: []v1.Container{
+						{
+							Name:    "reset",
+							Image:   image,
+							Command: []string{"sh", "-c", fmt.Sprintf("find %s -mindepth 1 -delete", workspaceMountPath)},
+							VolumeMounts: []v1.VolumeMount{
+								{
+									Name:      "workspace",
+									MountPath: workspaceMountPath,
+								},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: "workspace",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+									ClaimName: pvc.Name,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// collect finds workspaces whose owner is not enrolled in classroom anymore, and deletes them if not dryRun.
+// Workspaces in use or younger than reconcileGrace are kept.
+func (w *Workspace) collect(dryRun bool) ([]model.WorkspaceGC, error) {
+	workspaces, err := w.listWorkspaces("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	collected := []model.WorkspaceGC{}
+	for _, ws := range workspaces {
+		if ws.Owner == "" || ws.InUse || now.Sub(ws.CreatedAt) < reconcileGrace {
+			continue
+		}
+
+		reason, err := w.orphanReason(ws)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+
+		gc := model.WorkspaceGC{
+			WorkspaceInfo: ws,
+			Reason:        reason,
+		}
+		if !dryRun {
+			if err := w.KClientSet.CoreV1().PersistentVolumeClaims(ws.ClassroomID).
+				Delete(context.Background(), ws.Name, metav1.DeleteOptions{}); err != nil {
+				log.Warningf("garbage collect workspace {%s/%s} fail: %s", ws.ClassroomID, ws.Name, err.Error())
+				gc.Error = err.Error()
+			} else {
+				log.Infof("workspace {%s/%s} is garbage collected: %s", ws.ClassroomID, ws.Name, reason)
+				gc.Deleted = true
+			}
+		}
+		collected = append(collected, gc)
+	}
+	return collected, nil
+}
+
+// orphanReason returns why owner of workspace is not enrolled in classroom, empty string if owner is enrolled.
+// Everyone is allowed in public and teacher classroom, so only deleted users are checked there.
+func (w *Workspace) orphanReason(ws model.WorkspaceInfo) (string, error) {
+	count := 0
+	if err := w.DB.Model(&db.User{}).Where("user = ? AND provider = ?", ws.Owner, ws.Provider).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return fmt.Sprintf("user {%s/%s} is not found", ws.Provider, ws.Owner), nil
+	}
+
+	if ws.ClassroomID == consts.PUBLIC_CLASSROOM || ws.ClassroomID == consts.TEACHER_CLASSROOM {
+		return "", nil
+	}
+
+	member, err := db.IsClassroomMember(w.DB, ws.ClassroomID, ws.Owner, ws.Provider)
+	if err != nil {
+		return "", err
+	}
+	if !member {
+		return fmt.Sprintf("user {%s/%s} is not enrolled in classroom {%s}", ws.Provider, ws.Owner, ws.ClassroomID), nil
+	}
+	return "", nil
+}
+
+func workspaceOwner(pvc *v1.PersistentVolumeClaim) string {
+	return pvc.Annotations[consts.WorkspaceOwnerAnnotation]
+}
+
+func workspaceProvider(pvc *v1.PersistentVolumeClaim) string {
+	return pvc.Annotations[consts.WorkspaceProviderAnnotation]
+}
+
+func workspaceInfo(pvc *v1.PersistentVolumeClaim) model.WorkspaceInfo {
+	info := model.WorkspaceInfo{
+		ClassroomID: pvc.Namespace,
+		Name:        pvc.Name,
+		Owner:       workspaceOwner(pvc),
+		Provider:    workspaceProvider(pvc),
+		Status:      string(pvc.Status.Phase),
+		CreatedAt:   pvc.CreationTimestamp.Time,
+	}
+	if pvc.Spec.StorageClassName != nil {
+		info.StorageClass = *pvc.Spec.StorageClassName
+	}
+	size, ok := pvc.Status.Capacity[v1.ResourceStorage]
+	if !ok {
+		size, ok = pvc.Spec.Resources.Requests[v1.ResourceStorage]
+	}
+	if ok {
+		info.Size = size.String()
+	}
+	return info
+}
@@ -3,6 +3,34 @@ package consts
 const NamespaceLabelInstance = "instance"
 const DatasetPVCPrefix = "dataset-"
 
+// Course CRD spec has no field of resources, node selector and env, they are kept in annotations of CRD,
+// and applied to pod template of deployment created by course controller by job status controller of api server
+const (
+	CourseAnnotationResources    = "nchc.ai/resources"     // json of core/v1 ResourceRequirements
+	CourseAnnotationNodeSelector = "nchc.ai/node-selector" // json of node selector map
+	CourseAnnotationEnv          = "nchc.ai/env"           // json of core/v1 EnvVar list
+)
+
+// Writable volume created by course controller for WritableVolume of Course CRD is found by mount point in deployment of CRD,
+// and labelled with WorkspaceLabel by job status controller of api server. User name and provider of job owner are kept
+// in WorkspaceOwnerAnnotation and WorkspaceProviderAnnotation, since user name may not be a valid label value.
+const (
+	WorkspaceLabel              = "nchc.ai/workspace"
+	WorkspaceOwnerAnnotation    = "nchc.ai/workspace-owner"
+	WorkspaceProviderAnnotation = "nchc.ai/workspace-provider"
+	WorkspaceResetLabel         = "nchc.ai/workspace-reset" // name of writable volume cleaned by reset job
+)
+
+// Only secret in system namespace labelled with CourseSecretLabel=true can be referenced by environment variables of course,
+// other secrets of system, eg: database and oauth credentials, must not be exposed to containers of course authors.
+const CourseSecretLabel = "nchc.ai/course-secret"
+
+// Builder job committing container into new image is labelled with ImageCommitLabel, value is id of image commit
+const ImageCommitLabel = "nchc.ai/image-commit"
+
+// Image label telling image is built for GPU, in addition to labels of nvidia/cuda base images
+const ImageGpuLabel = "nchc.ai/gpu"
+
 const BaseDockerHubUrl = "https://registry.hub.docker.com/v2/repositories/"
 const AiTrainUser = "nchcai"
 const AiTrainImagePrefix = "train"
@@ -17,22 +45,114 @@ const TlsSecretName = "nchc-tls-secret"
 const SccRoleName = "scc-role"
 const SccRoleBindingName = "scc-role-binding"
 
+// keys of caller identity put in gin context by authMiddleware
+const (
+	ContextUserInfo  = "UserInfo"
+	ContextLoginUser = "LoginUser"
+)
+
 const LOGIN_ERROR = "登入失敗: "
 const (
 	ERROR_LOGIN_ROLE_NOT_FOUND = LOGIN_ERROR + "帳號 {%s} 查無對應的身份，請先註冊您的帳號為學生/老師/管理員之一"
 )
 
+// machine-readable reason in 403 response
+const (
+	FORBIDDEN_ROLE              = "ROLE_NOT_ALLOWED"
+	FORBIDDEN_COURSE_OWNER      = "NOT_COURSE_OWNER"
+	FORBIDDEN_CLASSROOM_TEACHER = "NOT_CLASSROOM_TEACHER"
+	FORBIDDEN_JOB_OWNER         = "NOT_JOB_OWNER"
+	FORBIDDEN_USER_SELF         = "NOT_SELF"
+	FORBIDDEN_WORKSPACE_OWNER   = "NOT_WORKSPACE_OWNER"
+)
+
+const FORBIDDEN_ERROR = "權限不足: "
+
+const (
+	ERROR_FORBIDDEN_ROLE_FMT              = FORBIDDEN_ERROR + "身份 {%s} 不允許執行 {%s}"
+	ERROR_FORBIDDEN_COURSE_OWNER_FMT      = FORBIDDEN_ERROR + "課程 {%s} 只能由建立者修改，但您 {%s} 不是課程建立者"
+	ERROR_FORBIDDEN_CLASSROOM_TEACHER_FMT = FORBIDDEN_ERROR + "教室 {%s} 只能由教室老師管理，但您 {%s} 不是教室老師"
+	ERROR_FORBIDDEN_JOB_OWNER_FMT         = FORBIDDEN_ERROR + "課程環境 {%s} 只能由啟動者或教室老師操作，但您 {%s} 不是"
+	ERROR_FORBIDDEN_USER_SELF_FMT         = FORBIDDEN_ERROR + "您 {%s} 只能修改自己的帳號資訊"
+	ERROR_FORBIDDEN_WORKSPACE_OWNER_FMT   = FORBIDDEN_ERROR + "工作空間 {%s} 只能由擁有者操作，但您 {%s} 不是"
+)
+
 // Job launch error message format
 const JOB_LAUNCH_ERROR = "啟動課程失敗: "
 
 const (
-	ERROR_JOB_LAUNCH_QUOTA_FMT    = JOB_LAUNCH_ERROR + "同時間只能啟用1個課程，但您 {%s} 已經啟動 {%d} 個課程"
+	ERROR_JOB_LAUNCH_QUOTA_FMT    = JOB_LAUNCH_ERROR + "同時間只能啟用 {%d} 個課程，但您 {%s} 已經啟動 {%d} 個課程"
+	ERROR_JOB_LAUNCH_GPU_FMT      = JOB_LAUNCH_ERROR + "GPU 配額為 {%d} 張，您 {%s} 已使用 {%d} 張，無法再啟動需要 {%d} 張的課程"
+	ERROR_JOB_LAUNCH_CPU_FMT      = JOB_LAUNCH_ERROR + "CPU 配額為 {%d}m，您 {%s} 已使用 {%d}m，無法再啟動需要 {%d}m 的課程"
+	ERROR_JOB_LAUNCH_MEMORY_FMT   = JOB_LAUNCH_ERROR + "記憶體配額為 {%d}Mi，您 {%s} 已使用 {%d}Mi，無法再啟動需要 {%d}Mi 的課程"
 	ERROR_JOB_LAUNCH_OWNER_FMT    = JOB_LAUNCH_ERROR + "開課列表內課程只能由建立者啟動，但您 {%s} 不是課程建立者"
 	ERROR_JOB_LAUNCH_TIME_FMT     = JOB_LAUNCH_ERROR + "教室 {%s} 的課程只能在 {%s} 啟動，現在不是允許的使用時間"
 	ERROR_JOB_LAUNCH_MEMBER_FMT   = JOB_LAUNCH_ERROR + "只有成員可以啟動教室內課程，但您 {%s} 並不屬於教室 {%s}"
 	ERROR_JOB_LAUNCH_PORT_FMT     = JOB_LAUNCH_ERROR + "課程 {%s} 沒有定義所需要端口，請洽 {%s} 修改設定"
 	ERROR_JOB_LAUNCH_BUILDCRD_FMT = JOB_LAUNCH_ERROR + "讀取課程 {%s} 參數錯誤"
 	ERROR_JOB_LAUNCH_RUNCRD_FMT   = JOB_LAUNCH_ERROR + "啟動課程 {%s} 後台資源系統出錯"
+	ERROR_JOB_LAUNCH_RESERVED_FMT = JOB_LAUNCH_ERROR + "GPU 已被其他教室預約，目前可用 {%d} 張，無法啟動需要 {%d} 張的課程"
+	ERROR_JOB_LAUNCH_SECRET_FMT   = JOB_LAUNCH_ERROR + "課程 {%s} 所需的密鑰 {%s} 無法複製到教室，請洽 {%s} 修改設定"
+	ERROR_JOB_LAUNCH_IMAGE_FMT    = JOB_LAUNCH_ERROR + "課程 {%s} 的映像檔 {%s} 不在教室 {%s} 允許清單，請洽 {%s} 修改設定"
+)
+
+const JOB_EXTEND_ERROR = "延長使用時間失敗: "
+
+const (
+	ERROR_JOB_EXTEND_NOLIMIT_FMT  = JOB_EXTEND_ERROR + "課程環境 {%s} 沒有使用時間限制，不需要延長"
+	ERROR_JOB_EXTEND_MINUTES_FMT  = JOB_EXTEND_ERROR + "延長時間 {%d} 分鐘必須介於 1 到 %d 分鐘"
+	ERROR_JOB_EXTEND_QUOTA_FMT    = JOB_EXTEND_ERROR + "教室 {%s} 每個課程環境最多只能延長 {%d} 分鐘，已延長 {%d} 分鐘"
+	ERROR_JOB_EXTEND_TIME_FMT     = JOB_EXTEND_ERROR + "教室 {%s} 的課程只能在 {%s} 使用，延長後的時間 {%s} 不是允許的使用時間"
+	ERROR_JOB_EXTEND_CONFLICT_FMT = JOB_EXTEND_ERROR + "課程環境 {%s} 正在被延長，請稍後再試"
+)
+
+const JOB_FILE_ERROR = "工作目錄檔案傳輸失敗: "
+
+const (
+	ERROR_JOB_FILE_NOPATH_FMT   = JOB_FILE_ERROR + "課程 {%s} 沒有設定工作目錄"
+	ERROR_JOB_FILE_PATH_FMT     = JOB_FILE_ERROR + "路徑 {%s} 不合法"
+	ERROR_JOB_FILE_FORMAT_FMT   = JOB_FILE_ERROR + "不支援的格式 {%s}，只支援 tar 或 zip"
+	ERROR_JOB_FILE_SIZE_FMT     = JOB_FILE_ERROR + "檔案大小 {%d}MiB 超過上限 {%d}MiB"
+	ERROR_JOB_FILE_NOTFOUND_FMT = JOB_FILE_ERROR + "找不到 {%s}"
+	ERROR_JOB_FILE_ARCHIVE_FMT  = JOB_FILE_ERROR + "上傳檔案 {%s} 不合法: %s"
+)
+
+const SCHEDULE_ERROR = "排程課程失敗: "
+
+const (
+	ERROR_SCHEDULE_TYPE_FMT  = SCHEDULE_ERROR + "只有容器課程 {%s} 可以排程啟動"
+	ERROR_SCHEDULE_START_FMT = SCHEDULE_ERROR + "啟動時間 {%s} 必須在未來"
+	ERROR_SCHEDULE_STOP_FMT  = SCHEDULE_ERROR + "停止時間 {%s} 必須晚於啟動時間 {%s}"
+	ERROR_SCHEDULE_CLASS_FMT = SCHEDULE_ERROR + "教室 {%s} 在 {%d} 天內沒有允許的使用時間"
+	ERROR_SCHEDULE_LIMIT_FMT = SCHEDULE_ERROR + "每人最多只能有 {%d} 個等待中的排程"
+	ERROR_SCHEDULE_STATE_FMT = SCHEDULE_ERROR + "排程狀態為 {%s}，無法修改"
+)
+
+const WORKSPACE_ERROR = "工作空間操作失敗: "
+
+const (
+	ERROR_WORKSPACE_IN_USE_FMT = WORKSPACE_ERROR + "教室 {%s} 的工作空間 {%s} 正在被課程使用，請先停止課程"
+	ERROR_WORKSPACE_DELETE_FMT = WORKSPACE_ERROR + "刪除工作空間 {%s} 失敗"
+	ERROR_WORKSPACE_RESET_FMT  = WORKSPACE_ERROR + "清除工作空間 {%s} 失敗"
+)
+
+const IMAGE_COMMIT_ERROR = "儲存映像檔失敗: "
+
+const (
+	ERROR_IMAGE_COMMIT_DISABLED    = IMAGE_COMMIT_ERROR + "系統沒有設定映像檔倉庫"
+	ERROR_IMAGE_COMMIT_TYPE_FMT    = IMAGE_COMMIT_ERROR + "只有容器課程 {%s} 可以儲存映像檔"
+	ERROR_IMAGE_COMMIT_NAME_FMT    = IMAGE_COMMIT_ERROR + "映像檔名稱 {%s} 不合法，格式為 名稱:標籤，只能使用小寫英數字及 . _ -"
+	ERROR_IMAGE_COMMIT_RUNNING_FMT = IMAGE_COMMIT_ERROR + "映像檔 {%s} 正在儲存中，請等待完成後再試"
+	ERROR_IMAGE_COMMIT_CREATE_FMT  = IMAGE_COMMIT_ERROR + "建立儲存映像檔 {%s} 的工作失敗"
+)
+
+const RESERVATION_ERROR = "預約 GPU 失敗: "
+
+const (
+	ERROR_RESERVATION_GPU_FMT      = RESERVATION_ERROR + "預約 GPU 數量 {%d} 必須大於0"
+	ERROR_RESERVATION_WINDOW_FMT   = RESERVATION_ERROR + "預約時段必須在未來，且不可超過 {%d} 小時"
+	ERROR_RESERVATION_SCHEDULE_FMT = RESERVATION_ERROR + "教室 {%s} 只能在 {%s} 使用，預約時段內的 {%s} 不是允許的使用時間"
+	ERROR_RESERVATION_CAPACITY_FMT = RESERVATION_ERROR + "叢集共有 {%d} 張 GPU，同時段已被預約 {%d} 張，無法再預約 {%d} 張"
 )
 
 const CLASSROOM_CREATE_ERROR = "教室建立失敗: "
@@ -81,6 +201,11 @@ const (
 	ERROR_COURSE_CREATE_PORT_EMPTY_FMT  = COURSE_CREATE_ERROR + "課程 {%s} 建立課程端口資訊失敗，端口名稱為空"
 	ERROR_COURSE_CREATE_PORT_INVLID_FMT = COURSE_CREATE_ERROR + "課程 {%s} 建立課程端口資訊失敗，端口名稱不合法"
 	ERROR_COURSE_CREATE_PORT_FMT        = COURSE_CREATE_ERROR + "課程 {%s} 建立課程端口資訊失敗"
+	ERROR_COURSE_CREATE_RESOURCE_FMT    = COURSE_CREATE_ERROR + "課程 {%s} 資源設定不合法: %s"
+	ERROR_COURSE_CREATE_ENV_INVALID_FMT = COURSE_CREATE_ERROR + "課程 {%s} 環境變數設定不合法: %s"
+	ERROR_COURSE_CREATE_ENV_FMT         = COURSE_CREATE_ERROR + "課程 {%s} 建立課程環境變數資訊失敗"
+	ERROR_COURSE_CREATE_IMAGE_FMT       = COURSE_CREATE_ERROR + "課程 {%s} 映像檔不合法: %s"
+	ERROR_COURSE_CREATE_IMAGE_DENY_FMT  = COURSE_CREATE_ERROR + "課程 {%s} 映像檔不在允許清單: %s"
 )
 
 // course update error message format
@@ -90,6 +215,11 @@ const (
 	ERROR_COURSE_UPDATE_PORT_FMT        = COURSE_UPDATE_ERROR + "課程 {%s} 端口資訊失敗"
 	ERROR_COURSE_UPDATE_PORT_EMPTY_FMT  = COURSE_UPDATE_ERROR + "課程 {%s} 端口資訊失敗，端口名稱為空"
 	ERROR_COURSE_UPDATE_PORT_INVLID_FMT = COURSE_UPDATE_ERROR + "課程 {%s} 端口資訊失敗，端口名稱不合法"
+	ERROR_COURSE_UPDATE_RESOURCE_FMT    = COURSE_UPDATE_ERROR + "課程 {%s} 資源設定不合法: %s"
+	ERROR_COURSE_UPDATE_ENV_INVALID_FMT = COURSE_UPDATE_ERROR + "課程 {%s} 環境變數設定不合法: %s"
+	ERROR_COURSE_UPDATE_ENV_FMT         = COURSE_UPDATE_ERROR + "課程 {%s} 環境變數資訊失敗"
+	ERROR_COURSE_UPDATE_IMAGE_FMT       = COURSE_UPDATE_ERROR + "課程 {%s} 映像檔不合法: %s"
+	ERROR_COURSE_UPDATE_IMAGE_DENY_FMT  = COURSE_UPDATE_ERROR + "課程 {%s} 映像檔不在允許清單: %s"
 )
 
 // course delete error message format
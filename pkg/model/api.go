@@ -5,6 +5,8 @@ import (
 
 	"github.com/nchc-ai/backend-api/pkg/model/common"
 	"github.com/nchc-ai/backend-api/pkg/model/db"
+	"github.com/nchc-ai/backend-api/pkg/registry"
+	"github.com/nchc-ai/course-crd/pkg/apis/coursecontroller/v1alpha1"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -18,6 +20,12 @@ type GenericResponse struct {
 	Message string `json:"message"`
 }
 
+type ForbiddenResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
 type Node struct {
 	Name   string               `json:"name"`
 	Status v1.NodeConditionType `json:"status"`
@@ -48,8 +56,40 @@ type DatasetsListResponse struct {
 }
 
 type ImagesListResponse struct {
-	Error  bool                `json:"error"`
-	Images []common.LabelValue `json:"images"`
+	Error  bool          `json:"error"`
+	Images []ImageOption `json:"images"`
+}
+
+// ImageOption is an image to choose in course, pinned is name@digest which can be used instead of mutable tag.
+type ImageOption struct {
+	Label  string `json:"label"`
+	Value  string `json:"value"`
+	Pinned string `json:"pinned,omitempty"`
+	registry.Image
+}
+
+type ImageCommitResponse struct {
+	Error  bool           `json:"error"`
+	Commit db.ImageCommit `json:"commit"`
+}
+
+// CatalogRepository is result of refreshing images of a repository.
+type CatalogRepository struct {
+	Registry   string     `json:"registry"`
+	Repository string     `json:"repository"`
+	Images     int        `json:"images"`
+	FetchedAt  *time.Time `json:"fetchedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type ImageCatalogRefreshResponse struct {
+	Error        bool                `json:"error"`
+	Repositories []CatalogRepository `json:"repositories"`
+}
+
+type ImageCommitListResponse struct {
+	Error   bool             `json:"error"`
+	Commits []db.ImageCommit `json:"commits"`
 }
 
 type LaunchCourseRequest struct {
@@ -63,10 +103,91 @@ type LaunchCourseResponse struct {
 	Job   JobStatus `json:"job"`
 }
 
+type ClassroomJobRequest struct {
+	CourseId    string `json:"course_id"`
+	ClassroomId string `json:"classroom_id"`
+}
+
+type ClassroomJobResponse struct {
+	Error   bool                 `json:"error"`
+	Results []ClassroomJobResult `json:"results"`
+}
+
+// ClassroomJobResult is outcome of launching or stopping job of one classroom member.
+type ClassroomJobResult struct {
+	User     string `json:"user"`
+	Provider string `json:"provider"`
+	JobId    string `json:"job_id,omitempty"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+}
+
 type JobStatus struct {
 	JobId  string `json:"job_id"`
 	Ready  bool   `json:"ready"`
 	Status string `json:"status"`
+	// 1-based position in launch queue when status is Queued
+	QueuePosition int `json:"queuePosition,omitempty"`
+}
+
+type ExtendJobRequest struct {
+	Minutes int32 `json:"minutes"`
+}
+
+type ExtendJobResponse struct {
+	Error    bool      `json:"error"`
+	JobId    string    `json:"job_id"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+type JobWarning struct {
+	JobId    string    `json:"job_id"`
+	Reason   string    `json:"reason"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// TerminalMessage is exchanged over job terminal websocket in json.
+// Op is "stdin" and "resize" from browser, "stdout" and "error" from api server.
+type TerminalMessage struct {
+	Op   string `json:"op"`
+	Data string `json:"data,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+}
+
+type JobDetailResponse struct {
+	Error bool      `json:"error"`
+	Job   JobDetail `json:"job"`
+}
+
+type JobDetail struct {
+	Id          string                `json:"id"`
+	CourseID    string                `json:"course_id"`
+	ClassroomID string                `json:"classroom_id"`
+	User        string                `json:"user"`
+	StartAt     time.Time             `json:"startAt"`
+	Status      string                `json:"status"`
+	Spec        v1alpha1.CourseSpec   `json:"spec"`
+	CRDStatus   v1alpha1.CourseStatus `json:"crdStatus"`
+	Events      []KubernetesEvent     `json:"events"`
+	Pods        []JobPod              `json:"pods"`
+}
+
+type JobPod struct {
+	Name              string               `json:"name"`
+	Phase             v1.PodPhase          `json:"phase"`
+	NodeName          string               `json:"nodeName"`
+	Conditions        []v1.PodCondition    `json:"conditions"`
+	ContainerStatuses []v1.ContainerStatus `json:"containerStatuses"`
+	Events            []KubernetesEvent    `json:"events"`
+}
+
+type KubernetesEvent struct {
+	Type          string    `json:"type"`
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"lastTimestamp"`
 }
 
 type JobListResponse struct {
@@ -76,6 +197,7 @@ type JobListResponse struct {
 
 type JobInfo struct {
 	Id           string              `json:"id"`
+	Type         string              `json:"type"`
 	CourseID     string              `json:"course_id"`
 	StartAt      time.Time           `json:"startAt"`
 	Status       string              `json:"status"`
@@ -86,6 +208,21 @@ type JobInfo struct {
 	Level        string              `json:"level"`
 	CanSnapshot  bool                `json:"canSnapshot"`
 	Service      []common.LabelValue `json:"service"`
+	ExpireAt     *time.Time          `json:"expireAt,omitempty"`
+	// 1-based position in launch queue when status is Queued
+	QueuePosition int `json:"queuePosition,omitempty"`
+}
+
+type ClassroomJobListResponse struct {
+	Error bool               `json:"error"`
+	Jobs  []ClassroomJobInfo `json:"jobs"`
+}
+
+// ClassroomJobInfo is job of classroom member shown to teacher.
+type ClassroomJobInfo struct {
+	User     string `json:"user"`
+	Provider string `json:"provider"`
+	JobInfo
 }
 
 type Search struct {
@@ -126,3 +263,92 @@ type UserLabelValue struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
+
+type QuotaListResponse struct {
+	Error  bool       `json:"error"`
+	Quotas []db.Quota `json:"quotas"`
+}
+
+type ImageRuleListResponse struct {
+	Error bool           `json:"error"`
+	Rules []db.ImageRule `json:"rules"`
+}
+
+type ScheduleJobRequest struct {
+	User        string     `json:"user"`
+	CourseId    string     `json:"course_id"`
+	ClassroomId string     `json:"classroom_id"`
+	StartAt     *time.Time `json:"startAt"`
+	StopAt      *time.Time `json:"stopAt"`
+	// launch given minutes before next class window of classroom, instead of startAt
+	BeforeClass *int `json:"beforeClass"`
+	// stop when the class window ends, instead of stopAt
+	StopAtClassEnd bool `json:"stopAtClassEnd"`
+}
+
+type ScheduledJobListResponse struct {
+	Error     bool              `json:"error"`
+	Schedules []db.ScheduledJob `json:"schedules"`
+}
+
+type ScheduledJobResponse struct {
+	Error    bool            `json:"error"`
+	Schedule db.ScheduledJob `json:"schedule"`
+}
+
+type ReservationListResponse struct {
+	Error        bool             `json:"error"`
+	Reservations []db.Reservation `json:"reservations"`
+}
+
+type ReservationResponse struct {
+	Error       bool           `json:"error"`
+	Reservation db.Reservation `json:"reservation"`
+}
+
+type ReconcileResponse struct {
+	Error  bool    `json:"error"`
+	DryRun bool    `json:"dryRun"`
+	Drifts []Drift `json:"drifts"`
+}
+
+type Drift struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+	Repaired  bool   `json:"repaired"`
+	Error     string `json:"error,omitempty"`
+}
+
+type WorkspaceInfo struct {
+	ClassroomID   string     `json:"classroom_id"`
+	ClassroomName string     `json:"classroomName,omitempty"`
+	Name          string     `json:"name"`
+	Owner         string     `json:"owner"`
+	Provider      string     `json:"provider"`
+	Size          string     `json:"size"`
+	StorageClass  string     `json:"storageClass,omitempty"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"createAt"`
+	LastUsedAt    *time.Time `json:"lastUsedAt,omitempty"`
+	InUse         bool       `json:"inUse"`
+}
+
+type WorkspaceListResponse struct {
+	Error      bool            `json:"error"`
+	Workspaces []WorkspaceInfo `json:"workspaces"`
+}
+
+type WorkspaceGCResponse struct {
+	Error      bool          `json:"error"`
+	DryRun     bool          `json:"dryRun"`
+	Workspaces []WorkspaceGC `json:"workspaces"`
+}
+
+type WorkspaceGC struct {
+	WorkspaceInfo
+	Reason  string `json:"reason"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
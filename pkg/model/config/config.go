@@ -8,11 +8,12 @@ import (
 )
 
 type Config struct {
-	APIConfig     *APIConfig     `json:"api-server"`
-	DBConfig      *DBConfig      `json:"database"`
-	K8SConfig     *K8SConfig     `json:"kubernetes"`
-	RFStackConfig *RFStackConfig `json:"rfstack"`
-	RedisConfig   *RedisConfig   `json:"redis"`
+	APIConfig     *APIConfig       `json:"api-server"`
+	DBConfig      *DBConfig        `json:"database"`
+	K8SConfig     *K8SConfig       `json:"kubernetes"`
+	RFStackConfig *RFStackConfig   `json:"rfstack"`
+	RedisConfig   *RedisConfig     `json:"redis"`
+	Registries    []RegistryConfig `json:"registries"`
 }
 
 // Snake-Case JSON Fields Ignored by UnmarshalKey(), so we write our unmarsh function
@@ -46,6 +47,12 @@ func UnmarshConfig(v *viper.Viper) (*Config, error) {
 		return nil, err
 	}
 
+	registries := []RegistryConfig{}
+	err = v.UnmarshalKey("registries", &registries)
+	if err != nil {
+		return nil, err
+	}
+
 	apiconfig := APIConfig{}
 	err = v.UnmarshalKey("api-server", &apiconfig)
 
@@ -53,6 +60,8 @@ func UnmarshConfig(v *viper.Viper) (*Config, error) {
 		return nil, err
 	}
 
+	setQuotaDefaults(v, &apiconfig.Quota)
+
 	providerConfigstr := v.GetStringMapString("api-server.provider")
 	var vconf provider_config.ProviderConfig
 
@@ -76,6 +85,7 @@ func UnmarshConfig(v *viper.Viper) (*Config, error) {
 		APIConfig:     &apiconfig,
 		RFStackConfig: &stackConfig,
 		RedisConfig:   &redisConfig,
+		Registries:    registries,
 	}
 	return &config, nil
 }
@@ -87,6 +97,99 @@ type APIConfig struct {
 	Provider         provider_config.ProviderConfig `json:"provider"`
 	NamespacePrefix  string                         `json:"namespacePrefix"`
 	UidRange         string                         `json:"uidRange"`
+	Quota            QuotaConfig                    `json:"quota"`
+	TerminalIdle     int                            `json:"terminalIdle"` // minutes without input before job terminal is closed, default 15
+	IdleCpu          int                            `json:"idleCpu"`      // millicores above which job containers are regarded as active, default 50
+	Queue            QueueConfig                    `json:"queue"`
+	Resource         ResourceConfig                 `json:"resource"`
+	Workspace        WorkspaceConfig                `json:"workspace"`
+	Snapshot         SnapshotConfig                 `json:"snapshot"`
+	Catalog          CatalogConfig                  `json:"catalog"`
+	Reconcile        ReconcileConfig                `json:"reconcile"`
+	Webhook          WebhookConfig                  `json:"webhook"`
+}
+
+// WebhookConfig controls mutating admission webhook, which applies resources, node selector and env of job to deployment
+// when course controller creates it. Kubernetes calls webhook over https only, so webhook is disabled if CertFile is empty.
+type WebhookConfig struct {
+	Port     int    `json:"port"`     // port webhook is served with TLS, default 8443
+	CertFile string `json:"certFile"` // path of TLS certificate trusted by caBundle of MutatingWebhookConfiguration
+	KeyFile  string `json:"keyFile"`  // path of TLS private key
+}
+
+// ReconcileConfig controls periodic reconcile, which only reports drift unless AutoRepair is enabled.
+type ReconcileConfig struct {
+	AutoRepair bool `json:"autoRepair"` // delete drifted resources in periodic reconcile, default false
+}
+
+// CatalogConfig controls cache of images listed from registries.
+type CatalogConfig struct {
+	TTL             int `json:"ttl"`             // minutes before cached images are refreshed on listing, default 30
+	RefreshInterval int `json:"refreshInterval"` // minutes between background refresh of all repositories, default 10
+	Concurrency     int `json:"concurrency"`     // repositories fetched from registries at a time, default 4
+}
+
+// SnapshotConfig controls committing running container job into new image. Image is committed on the node
+// running the job by a builder job, which talks to container runtime through its socket, and pushed to Registry.
+type SnapshotConfig struct {
+	Registry      string `json:"registry"`      // registry host and optional path images are pushed to, commit is disabled if empty
+	Runtime       string `json:"runtime"`       // docker or containerd, default docker
+	RuntimeSocket string `json:"runtimeSocket"` // default /var/run/docker.sock or /run/containerd/containerd.sock
+	BuilderImage  string `json:"builderImage"`  // image with docker or nerdctl cli, default docker:24-cli
+	PushSecret    string `json:"pushSecret"`    // dockerconfigjson secret in system namespace used to push image
+	Timeout       int    `json:"timeout"`       // minutes before builder job is failed, default 30
+}
+
+// WorkspaceConfig controls management of writable volumes of users.
+type WorkspaceConfig struct {
+	ResetImage  string `json:"resetImage"`  // image of job cleaning writable volume, default busybox
+	MaxDownload int64  `json:"maxDownload"` // MiB of files downloaded from job at a time, default 1024
+	MaxUpload   int64  `json:"maxUpload"`   // MiB of files uploaded to job at a time, default 100
+}
+
+// ResourceConfig is maximum resource profile allowed in a course, zero value means unlimited.
+type ResourceConfig struct {
+	MaxCpu        int64    `json:"maxCpu"`        // millicores
+	MaxMemory     int64    `json:"maxMemory"`     // MiB
+	MaxStorage    int64    `json:"maxStorage"`    // MiB of ephemeral storage
+	GpuModels     []string `json:"gpuModels"`     // allowed GPU models, empty means any model
+	GpuModelLabel string   `json:"gpuModelLabel"` // node label of GPU model, default nvidia.com/gpu.product
+}
+
+// QueueConfig controls admission queue of GPU jobs. When free GPU on nodes is not enough,
+// launch request is queued and launched later in fifo order, or teachers first in priority order.
+type QueueConfig struct {
+	Enable      bool   `json:"enable"`
+	Policy      string `json:"policy"`      // fifo or priority, default fifo
+	GpuResource string `json:"gpuResource"` // extended resource name of GPU, default nvidia.com/gpu
+}
+
+// QuotaConfig is default quota applied to every user, and can be overridden per role, per classroom and per user.
+// Every limit follows the same convention as db.Quota: negative means unlimited, zero allows nothing.
+// Limit omitted in config file keeps the original behavior, one job and unlimited gpu, cpu and memory, see setQuotaDefaults.
+type QuotaConfig struct {
+	MaxJobs   int   `json:"maxJobs"`
+	MaxGpu    int32 `json:"maxGpu"`
+	MaxCpu    int64 `json:"maxCpu"`    // millicores
+	MaxMemory int64 `json:"maxMemory"` // MiB
+	JobCpu    int64 `json:"jobCpu"`    // millicores counted for each job
+	JobMemory int64 `json:"jobMemory"` // MiB counted for each job
+}
+
+// setQuotaDefaults sets limit omitted in config file, since zero limit allows nothing.
+func setQuotaDefaults(v *viper.Viper, quota *QuotaConfig) {
+	if !v.IsSet("api-server.quota.maxJobs") {
+		quota.MaxJobs = 1
+	}
+	if !v.IsSet("api-server.quota.maxGpu") {
+		quota.MaxGpu = -1
+	}
+	if !v.IsSet("api-server.quota.maxCpu") {
+		quota.MaxCpu = -1
+	}
+	if !v.IsSet("api-server.quota.maxMemory") {
+		quota.MaxMemory = -1
+	}
 }
 
 type DBConfig struct {
@@ -113,6 +216,18 @@ type RFStackConfig struct {
 	Url    string `json:"url"`
 }
 
+// RegistryConfig is a container registry whose images are listed in image catalog.
+// Docker Hub with nchcai repository is used if no registry is configured.
+type RegistryConfig struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`           // dockerhub, oci or harbor, default dockerhub
+	Url            string   `json:"url"`            // base url of registry, default Docker Hub repositories api for dockerhub
+	Username       string   `json:"username"`       // optional, user or robot account
+	Password       string   `json:"password"`       // password or access token of Username
+	Repositories   []string `json:"repositories"`   // Docker Hub namespaces, Harbor projects or OCI repository paths
+	UserRepository bool     `json:"userRepository"` // list repository in user profile from this registry
+}
+
 type RedisConfig struct {
 	Host string `json:"host"`
 	Port int    `json:"port"`
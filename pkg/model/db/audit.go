@@ -1,6 +1,10 @@
 package db
 
-import "github.com/jinzhu/gorm"
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
 
 type Audit struct {
 	Model
@@ -22,3 +26,25 @@ func (a *Audit) NewEntry(DB *gorm.DB) error {
 
 	return nil
 }
+
+// ExtensionAudit records each extension of job maximum runtime.
+type ExtensionAudit struct {
+	Model
+	OauthUser
+	JobID    string    `gorm:"size:72;not null;index"`
+	Minutes  int32     `gorm:"not null"`
+	ExpireAt time.Time `gorm:"not null"`
+}
+
+func (ExtensionAudit) TableName() string {
+	return "jobExtensionAudit"
+}
+
+func (a *ExtensionAudit) NewEntry(DB *gorm.DB) error {
+
+	if err := DB.Create(a).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
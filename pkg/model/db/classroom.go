@@ -49,6 +49,9 @@ type ClassRoomInfo struct {
 	SelectedType        *int32                      `gorm:"selectedType" json:"-"`
 	StartAt             string                      `gorm:"startAt" json:"-"`
 	EndAt               string                      `gorm:"endAt" json:"-"`
+	MaxRuntime          *int32                      `gorm:"not null;default:0" json:"maxRuntime,omitempty"`
+	IdleTimeout         *int32                      `gorm:"not null;default:0" json:"idleTimeout,omitempty"`
+	MaxExtension        *int32                      `gorm:"not null;default:0" json:"maxExtension,omitempty"`
 	IsPublicBool        bool                        `gorm:"-" json:"public"`
 	StudentCount        *int32                      `gorm:"-" json:"studentCount,omitempty"`
 	ScheduleTime        *Schedule                   `gorm:"-" json:"schedule,omitempty"`
@@ -237,6 +240,20 @@ func (classroom *ClassRoomInfo) GetStudentList(db *gorm.DB) (*[]common.LabelValu
 	return &finalResult, nil
 }
 
+func (classroom *ClassRoomInfo) GetStudents(db *gorm.DB) ([]ClassRoomStudentRelation, error) {
+	cm := ClassRoomStudentRelation{
+		ClassRoomUser: ClassRoomUser{
+			ClassroomID: classroom.ID,
+		},
+	}
+
+	result := []ClassRoomStudentRelation{}
+	if err := db.Where(&cm).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (classroom *ClassRoomInfo) GetCalendar(db *gorm.DB) (*[]CalendarTime, error) {
 
 	cm := ClassRoomCalendarRelation{
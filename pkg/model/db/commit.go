@@ -0,0 +1,103 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// status of image commit
+const (
+	COMMIT_PENDING   = "Pending"
+	COMMIT_RUNNING   = "Running"
+	COMMIT_SUCCEEDED = "Succeeded"
+	COMMIT_FAILED    = "Failed"
+)
+
+// ImageCommit is a snapshot of container job into a new image, built by a builder job in kubernetes.
+type ImageCommit struct {
+	Model
+	OauthUser
+	JobID       string `gorm:"size:72;not null" json:"job_id"`
+	CourseID    string `gorm:"size:36;not null" json:"course_id"`
+	ClassroomID string `gorm:"size:72" json:"classroom_id"`
+	// full reference of image pushed to registry
+	Image  string `gorm:"size:500;not null" json:"image"`
+	Status string `gorm:"size:20;not null;index" json:"status"`
+	// name of builder job in kubernetes
+	BuilderJob string     `gorm:"size:63" json:"builderJob,omitempty"`
+	Message    string     `gorm:"size:1000" json:"message,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// tail of builder logs kept after builder job is removed
+	Logs string `gorm:"type:text" json:"-"`
+}
+
+func (ImageCommit) TableName() string {
+	return "imageCommit"
+}
+
+func (i *ImageCommit) NewEntry(DB *gorm.DB) error {
+	if err := DB.Create(i).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (i *ImageCommit) Update(DB *gorm.DB) error {
+	if err := DB.Save(i).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// IsFinished checks builder job of commit is either succeeded or failed.
+func (i *ImageCommit) IsFinished() bool {
+	return i.Status == COMMIT_SUCCEEDED || i.Status == COMMIT_FAILED
+}
+
+func GetImageCommit(DB *gorm.DB, id string) (*ImageCommit, error) {
+	result := ImageCommit{}
+	if err := DB.Where("id = ?", id).First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UserImageCommits returns image commits of user, newest first.
+func UserImageCommits(DB *gorm.DB, user, provider string) ([]ImageCommit, error) {
+	results := []ImageCommit{}
+	if err := DB.Where("user = ? AND provider = ?", user, provider).
+		Order("created_at desc").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UnfinishedImageCommits returns commits whose builder job is not yet finished.
+func UnfinishedImageCommits(DB *gorm.DB) ([]ImageCommit, error) {
+	results := []ImageCommit{}
+	if err := DB.Where("status IN (?)", []string{COMMIT_PENDING, COMMIT_RUNNING}).
+		Order("created_at").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UserCommittedImages returns distinct images successfully committed by user, newest first.
+func UserCommittedImages(DB *gorm.DB, user, provider string) ([]string, error) {
+	commits := []ImageCommit{}
+	if err := DB.Where("user = ? AND provider = ? AND status = ?", user, provider, COMMIT_SUCCEEDED).
+		Order("created_at desc").Find(&commits).Error; err != nil {
+		return nil, err
+	}
+
+	images := []string{}
+	seen := map[string]bool{}
+	for _, c := range commits {
+		if !seen[c.Image] {
+			seen[c.Image] = true
+			images = append(images, c.Image)
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageCommit(t *testing.T) {
+	created := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	createFixtures(t, created,
+		&ImageCommit{Model: Model{ID: "commit-1"}, OauthUser: OauthUser{User: "c1", Provider: GO_OAUTH}, JobID: "job-1", Image: "registry.local/c1/app:v1", Status: COMMIT_SUCCEEDED},
+		&ImageCommit{Model: Model{ID: "commit-2"}, OauthUser: OauthUser{User: "c1", Provider: GO_OAUTH}, JobID: "job-1", Image: "registry.local/c1/app:v1", Status: COMMIT_SUCCEEDED},
+		&ImageCommit{Model: Model{ID: "commit-3"}, OauthUser: OauthUser{User: "c1", Provider: GO_OAUTH}, JobID: "job-2", Image: "registry.local/c1/app:v2", Status: COMMIT_RUNNING},
+		&ImageCommit{Model: Model{ID: "commit-4"}, OauthUser: OauthUser{User: "c2", Provider: GO_OAUTH}, JobID: "job-3", Image: "registry.local/c2/app:v1", Status: COMMIT_PENDING},
+	)
+
+	unfinished, err := UnfinishedImageCommits(Sqlite)
+	assert.NoError(t, err)
+	assert.Len(t, unfinished, 2)
+	assert.Equal(t, "commit-3", unfinished[0].ID)
+	assert.False(t, unfinished[0].IsFinished())
+
+	images, err := UserCommittedImages(Sqlite, "c1", GO_OAUTH)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"registry.local/c1/app:v1"}, images)
+
+	unfinished[0].Status = COMMIT_SUCCEEDED
+	assert.NoError(t, unfinished[0].Update(Sqlite))
+	images, err = UserCommittedImages(Sqlite, "c1", GO_OAUTH)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"registry.local/c1/app:v2", "registry.local/c1/app:v1"}, images)
+
+	commits, err := UserImageCommits(Sqlite, "c1", GO_OAUTH)
+	assert.NoError(t, err)
+	assert.Len(t, commits, 3)
+	assert.Equal(t, "commit-3", commits[0].ID)
+
+	commit, err := GetImageCommit(Sqlite, "commit-4")
+	assert.NoError(t, err)
+	assert.Equal(t, "c2", commit.User)
+}
@@ -35,6 +35,7 @@ func (CourseID) TableName() string {
 type Course struct {
 	Model
 	OauthUser
+	ResourceProfile
 	Name         string                `gorm:"not null" json:"name"`
 	Level        string                `gorm:"not null;default:'basic';size:10" json:"level"`
 	Introduction *string               `gorm:"size:3000" json:"introduction,omitempty"`
@@ -42,10 +43,13 @@ type Course struct {
 	AccessType   v1alpha1.AccessType   `gorm:"not null;default:'NodePort'" json:"accessType,omitempty"`
 	Gpu          *int32                `gorm:"not null;default:0" json:"-"`
 	WritablePath *string               `gorm:"not null" json:"writablePath,omitempty"`
+	MaxRuntime   *int32                `gorm:"not null;default:0" json:"maxRuntime,omitempty"`
+	IdleTimeout  *int32                `gorm:"not null;default:0" json:"idleTimeout,omitempty"`
 	ImageLV      *common.LabelValue    `gorm:"-" json:"image,omitempty"`
 	GpuLV        *common.LabelIntValue `gorm:"-" json:"gpu,omitempty"`
 	Datasets     *[]common.LabelValue  `gorm:"-" json:"datasets,omitempty"`
 	Ports        *[]Port               `gorm:"-" json:"ports,omitempty"`
+	Envs         *[]EnvVar             `gorm:"-" json:"envs,omitempty"`
 	CourseType   *string               `gorm:"-" json:"type,omitempty"`
 	ClasroomID   *string               `gorm:"-" json:"roomId,omitempty"`
 }
@@ -148,6 +152,19 @@ func (course *Course) GetPort(DB *gorm.DB) ([]Port, error) {
 	return portResult, nil
 }
 
+func (course *Course) GetEnv(DB *gorm.DB) ([]EnvVar, error) {
+	env := EnvVar{
+		CourseID: course.ID,
+	}
+
+	envResult := []EnvVar{}
+	if err := DB.Where(&env).Order("name").Find(&envResult).Error; err != nil {
+		return nil, err
+	}
+
+	return envResult, nil
+}
+
 func (course *Course) Type(DB *gorm.DB) (string, error) {
 
 	co := Course{
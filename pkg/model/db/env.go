@@ -0,0 +1,106 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// SECRET_VALUE_MASK replaces value of environment variable from secret when course is shown to user
+const SECRET_VALUE_MASK = "******"
+
+// EnvVar is environment variable of course container. Value is either given directly,
+// or read from key of a secret in system namespace, which is copied to classroom namespace when job is launched.
+type EnvVar struct {
+	Name       string  `gorm:"primary_key;size:100" json:"name"`
+	Value      *string `gorm:"size:3000" json:"value,omitempty"`
+	SecretName *string `gorm:"size:253" json:"secretName,omitempty"`
+	SecretKey  *string `gorm:"size:253" json:"secretKey,omitempty"`
+	// foreign key
+	CourseID string `gorm:"primary_key;size:36" json:"-"`
+}
+
+func (EnvVar) TableName() string {
+	return "containerEnvs"
+}
+
+// FromSecret checks value of environment variable is read from secret.
+func (e *EnvVar) FromSecret() bool {
+	return e.SecretName != nil && *e.SecretName != ""
+}
+
+// ValidateEnvs checks name of environment variables is valid and not duplicated,
+// and each variable has either value or secret reference.
+func ValidateEnvs(envs []EnvVar) error {
+	names := map[string]bool{}
+	for _, e := range envs {
+		if errs := validation.IsEnvVarName(e.Name); len(errs) > 0 {
+			return fmt.Errorf("environment variable name {%s} is invalid: %s", e.Name, strings.Join(errs, ", "))
+		}
+		if names[e.Name] {
+			return fmt.Errorf("environment variable {%s} is duplicated", e.Name)
+		}
+		names[e.Name] = true
+
+		if !e.FromSecret() {
+			if e.SecretKey != nil && *e.SecretKey != "" {
+				return fmt.Errorf("environment variable {%s} has secret key but no secret name", e.Name)
+			}
+			continue
+		}
+
+		if e.Value != nil && *e.Value != "" {
+			return fmt.Errorf("environment variable {%s} can not have both value and secret", e.Name)
+		}
+		if errs := validation.IsDNS1123Subdomain(*e.SecretName); len(errs) > 0 {
+			return fmt.Errorf("secret name {%s} is invalid: %s", *e.SecretName, strings.Join(errs, ", "))
+		}
+		if e.SecretKey == nil || *e.SecretKey == "" {
+			return fmt.Errorf("environment variable {%s} has secret name but no secret key", e.Name)
+		}
+		if errs := validation.IsConfigMapKey(*e.SecretKey); len(errs) > 0 {
+			return fmt.Errorf("secret key {%s} is invalid: %s", *e.SecretKey, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// SecretNames returns distinct secrets referenced by environment variables.
+func SecretNames(envs []EnvVar) []string {
+	names := []string{}
+	seen := map[string]bool{}
+	for _, e := range envs {
+		if e.FromSecret() && !seen[*e.SecretName] {
+			seen[*e.SecretName] = true
+			names = append(names, *e.SecretName)
+		}
+	}
+	return names
+}
+
+// MaskSecretEnvs sets value of environment variables from secret to SECRET_VALUE_MASK,
+// so user can tell these variables have hidden value.
+func MaskSecretEnvs(envs []EnvVar) []EnvVar {
+	masked := []EnvVar{}
+	for _, e := range envs {
+		if e.FromSecret() {
+			mask := SECRET_VALUE_MASK
+			e.Value = &mask
+		}
+		masked = append(masked, e)
+	}
+	return masked
+}
+
+// UnmaskSecretEnvs drops SECRET_VALUE_MASK put by MaskSecretEnvs, so masked course can be sent back to update.
+func UnmaskSecretEnvs(envs []EnvVar) []EnvVar {
+	unmasked := []EnvVar{}
+	for _, e := range envs {
+		if e.FromSecret() && e.Value != nil && *e.Value == SECRET_VALUE_MASK {
+			e.Value = nil
+		}
+		unmasked = append(unmasked, e)
+	}
+	return unmasked
+}
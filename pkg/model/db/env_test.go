@@ -0,0 +1,65 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEnvs(t *testing.T) {
+	valid := []EnvVar{
+		{Name: "API_ENDPOINT", Value: util.StringPtr("http://api.example.com")},
+		{Name: "NOTEBOOK_TOKEN", SecretName: util.StringPtr("notebook"), SecretKey: util.StringPtr("token")},
+		{Name: "EMPTY"},
+	}
+	assert.NoError(t, ValidateEnvs(valid))
+	assert.Equal(t, []string{"notebook"}, SecretNames(append(valid,
+		EnvVar{Name: "NOTEBOOK_USER", SecretName: util.StringPtr("notebook"), SecretKey: util.StringPtr("user")})))
+
+	for _, envs := range [][]EnvVar{
+		{{Name: "1NVALID", Value: util.StringPtr("v")}},
+		{{Name: "DUP", Value: util.StringPtr("a")}, {Name: "DUP", Value: util.StringPtr("b")}},
+		{{Name: "BOTH", Value: util.StringPtr("v"), SecretName: util.StringPtr("s"), SecretKey: util.StringPtr("k")}},
+		{{Name: "NO_KEY", SecretName: util.StringPtr("s")}},
+		{{Name: "NO_NAME", SecretKey: util.StringPtr("k")}},
+		{{Name: "BAD_SECRET", SecretName: util.StringPtr("Bad_Secret"), SecretKey: util.StringPtr("k")}},
+	} {
+		assert.Error(t, ValidateEnvs(envs))
+	}
+}
+
+func TestMaskSecretEnvs(t *testing.T) {
+	envs := []EnvVar{
+		{Name: "PLAIN", Value: util.StringPtr("value")},
+		{Name: "SECRET", SecretName: util.StringPtr("s"), SecretKey: util.StringPtr("k")},
+	}
+
+	masked := MaskSecretEnvs(envs)
+	assert.Equal(t, "value", *masked[0].Value)
+	assert.Equal(t, SECRET_VALUE_MASK, *masked[1].Value)
+	assert.Nil(t, envs[1].Value)
+	assert.Error(t, ValidateEnvs(masked))
+
+	unmasked := UnmaskSecretEnvs(masked)
+	assert.Equal(t, envs, unmasked)
+	assert.NoError(t, ValidateEnvs(unmasked))
+}
+
+func TestGetEnv(t *testing.T) {
+	for _, e := range []EnvVar{
+		{CourseID: "course-env", Name: "B", Value: util.StringPtr("b")},
+		{CourseID: "course-env", Name: "A", SecretName: util.StringPtr("s"), SecretKey: util.StringPtr("k")},
+		{CourseID: "course-other", Name: "C", Value: util.StringPtr("c")},
+	} {
+		env := e
+		assert.NoError(t, Sqlite.Create(&env).Error)
+	}
+
+	course := Course{Model: Model{ID: "course-env"}}
+	envs, err := course.GetEnv(Sqlite)
+	assert.NoError(t, err)
+	assert.Len(t, envs, 2)
+	assert.Equal(t, "A", envs[0].Name)
+	assert.Equal(t, "b", *envs[1].Value)
+}
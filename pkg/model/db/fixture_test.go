@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixture interface {
+	NewEntry(DB *gorm.DB) error
+	setCreatedAt(t time.Time)
+}
+
+func (m *Model) setCreatedAt(t time.Time) {
+	m.CreatedAt = t
+}
+
+// createFixtures creates rows in order. Unless created is zero, CreatedAt of rows is one minute apart from created,
+// so rows are sorted by creation in the given order.
+func createFixtures(t *testing.T, created time.Time, rows ...fixture) {
+	t.Helper()
+	for i, row := range rows {
+		if !created.IsZero() {
+			row.setCreatedAt(created.Add(time.Duration(i) * time.Minute))
+		}
+		assert.NoError(t, row.NewEntry(Sqlite))
+	}
+}
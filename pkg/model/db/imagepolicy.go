@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/registry"
+)
+
+const (
+	IMAGE_SCOPE_GLOBAL    = "global"
+	IMAGE_SCOPE_CLASSROOM = "classroom"
+
+	// target of global rules, which are not bound to any classroom
+	IMAGE_TARGET_GLOBAL = "*"
+)
+
+// ImageRule is an image pattern allowed as course image, see registry.MatchPattern for pattern format.
+// Global rules are maintained by superuser, all images are allowed if there is no global rule.
+// Classroom rules narrow global rules for courses launched in classroom, Target is classroom id.
+type ImageRule struct {
+	Scope       string    `gorm:"primary_key;size:20" json:"scope"`
+	Target      string    `gorm:"primary_key;size:72" json:"target"`
+	Pattern     string    `gorm:"primary_key;size:200" json:"pattern"`
+	Description string    `gorm:"size:200" json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createAt"`
+}
+
+func (ImageRule) TableName() string {
+	return "imageRules"
+}
+
+func IsValidImageScope(scope string) bool {
+	return scope == IMAGE_SCOPE_GLOBAL || scope == IMAGE_SCOPE_CLASSROOM
+}
+
+// Normalize sets target of global rule, and checks scope, target and pattern of rule.
+func (r *ImageRule) Normalize() error {
+	if !IsValidImageScope(r.Scope) {
+		return fmt.Errorf("invalid image rule scope {%s}", r.Scope)
+	}
+	if r.Scope == IMAGE_SCOPE_GLOBAL {
+		r.Target = IMAGE_TARGET_GLOBAL
+	}
+	if r.Target == "" {
+		return fmt.Errorf("classroom of image rule is empty")
+	}
+	return registry.ValidatePattern(r.Pattern)
+}
+
+func (r *ImageRule) Save(DB *gorm.DB) error {
+	if err := DB.Save(r).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ImageRule) Delete(DB *gorm.DB) error {
+	if err := DB.Where("scope = ? AND target = ? AND pattern = ?", r.Scope, r.Target, r.Pattern).
+		Delete(&ImageRule{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListImageRules returns global rules, and rules of classroom if classroomID is not empty.
+func ListImageRules(DB *gorm.DB, classroomID string) ([]ImageRule, error) {
+	results := []ImageRule{}
+	query := DB.Where("scope = ? AND target = ?", IMAGE_SCOPE_GLOBAL, IMAGE_TARGET_GLOBAL)
+	if classroomID != "" {
+		query = query.Or("scope = ? AND target = ?", IMAGE_SCOPE_CLASSROOM, classroomID)
+	}
+	if err := query.Order("scope DESC, pattern").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func findImageRules(DB *gorm.DB, scope, target string) ([]ImageRule, error) {
+	results := []ImageRule{}
+	if err := DB.Where("scope = ? AND target = ?", scope, target).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CheckImageAllowed checks image is a valid reference allowed by global rules and rules of each classroom.
+// Rules of the same scope are or-ed, so image is allowed if it matches any of them, and scope without rule allows all.
+func CheckImageAllowed(DB *gorm.DB, image string, classroomIDs ...string) error {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return err
+	}
+
+	rules, err := findImageRules(DB, IMAGE_SCOPE_GLOBAL, IMAGE_TARGET_GLOBAL)
+	if err != nil {
+		return err
+	}
+	if !matchImageRules(rules, ref) {
+		return fmt.Errorf("image {%s} is not in allowlist", image)
+	}
+
+	for _, id := range classroomIDs {
+		rules, err := findImageRules(DB, IMAGE_SCOPE_CLASSROOM, id)
+		if err != nil {
+			return err
+		}
+		if !matchImageRules(rules, ref) {
+			return fmt.Errorf("image {%s} is not allowed in classroom {%s}", image, id)
+		}
+	}
+	return nil
+}
+
+func matchImageRules(rules []ImageRule, image registry.Image) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	for _, r := range rules {
+		if registry.MatchPattern(r.Pattern, image) {
+			return true
+		}
+	}
+	return false
+}
+
+// CourseClassrooms returns id of classrooms which course is added to.
+func CourseClassrooms(DB *gorm.DB, courseID string) ([]string, error) {
+	relations := []ClassRoomCourseRelation{}
+	if err := DB.Where(ClassRoomCourseRelation{CourseID: courseID}).Find(&relations).Error; err != nil {
+		return nil, err
+	}
+
+	results := []string{}
+	for _, r := range relations {
+		results = append(results, r.ClassroomID)
+	}
+	return results, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckImageAllowed(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("b", 64)
+
+	// everything is allowed without rules
+	assert.NoError(t, CheckImageAllowed(Sqlite, "anyone/image:v1", "classroom-image"))
+	assert.Error(t, CheckImageAllowed(Sqlite, "Invalid Image", "classroom-image"))
+
+	rules := []ImageRule{
+		{Scope: IMAGE_SCOPE_GLOBAL, Pattern: "nchcai/*"},
+		{Scope: IMAGE_SCOPE_GLOBAL, Pattern: "harbor.local/ai/**"},
+		{Scope: IMAGE_SCOPE_CLASSROOM, Target: "classroom-image", Pattern: "nchcai/train:v1.*"},
+		{Scope: IMAGE_SCOPE_CLASSROOM, Target: "classroom-image", Pattern: "harbor.local/ai/base@" + digest},
+	}
+	for idx := range rules {
+		assert.NoError(t, rules[idx].Normalize())
+		assert.NoError(t, rules[idx].Save(Sqlite))
+	}
+
+	invalid := ImageRule{Scope: IMAGE_SCOPE_CLASSROOM, Pattern: "nchcai/*"}
+	assert.Error(t, invalid.Normalize())
+	invalid = ImageRule{Scope: IMAGE_SCOPE_GLOBAL, Pattern: "nchcai/*@" + digest}
+	assert.Error(t, invalid.Normalize())
+
+	list, err := ListImageRules(Sqlite, "")
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	list, err = ListImageRules(Sqlite, "classroom-image")
+	assert.NoError(t, err)
+	assert.Len(t, list, 4)
+
+	assert.NoError(t, CheckImageAllowed(Sqlite, "nchcai/train:latest"))
+	assert.NoError(t, CheckImageAllowed(Sqlite, "harbor.local/ai/team/app:v2"))
+	assert.Error(t, CheckImageAllowed(Sqlite, "nchcai/train/sub:v1"))
+	assert.Error(t, CheckImageAllowed(Sqlite, "tensorflow/tensorflow:latest"))
+
+	// classroom narrows global rules
+	assert.NoError(t, CheckImageAllowed(Sqlite, "nchcai/train:v1.2", "classroom-image"))
+	assert.NoError(t, CheckImageAllowed(Sqlite, "harbor.local/ai/base@"+digest, "classroom-image"))
+	assert.Error(t, CheckImageAllowed(Sqlite, "nchcai/train:latest", "classroom-image"))
+	assert.Error(t, CheckImageAllowed(Sqlite, "harbor.local/ai/base:v1", "classroom-image"))
+	// classroom without rules inherits global rules
+	assert.NoError(t, CheckImageAllowed(Sqlite, "nchcai/train:latest", "classroom-other"))
+
+	for _, r := range rules {
+		assert.NoError(t, r.Delete(Sqlite))
+	}
+	assert.NoError(t, CheckImageAllowed(Sqlite, "tensorflow/tensorflow:latest", "classroom-image"))
+}
+
+func TestCourseClassrooms(t *testing.T) {
+	for _, id := range []string{"classroom-a", "classroom-b"} {
+		assert.NoError(t, Sqlite.Create(&ClassRoomCourseRelation{ClassroomID: id, CourseID: "course-classrooms"}).Error)
+	}
+
+	ids, err := CourseClassrooms(Sqlite, "course-classrooms")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"classroom-a", "classroom-b"}, ids)
+
+	ids, err = CourseClassrooms(Sqlite, "course-none")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+}
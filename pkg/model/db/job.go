@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	log "github.com/golang/glog"
 	"github.com/jinzhu/gorm"
 	"github.com/nchc-ai/backend-api/pkg/model/common"
 	"github.com/nchc-ai/course-crd/pkg/client/clientset/versioned"
 	"github.com/nitishm/go-rejson/v4"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -21,6 +23,10 @@ type Job struct {
 	// foreign key
 	ClassroomID *string `gorm:"size:72"`
 	Status      string  `gorm:"not null"`
+	// last time owner is seen using job, used to detect idle job
+	LastActiveAt *time.Time
+	// minutes added to maximum runtime by owner
+	Extended int32 `gorm:"not null;default:0"`
 }
 
 func (Job) TableName() string {
@@ -76,11 +82,13 @@ func (j *Job) GetCourse(DB *gorm.DB) (*Course, error) {
 	}, nil
 }
 
+// DeleteCourseCRD deletes Course CRD of job, then job record and cache of job owner.
+// Course CRD already gone, eg: deleted with its namespace, is regarded as deleted, so job record is still removed.
 func (j *Job) DeleteCourseCRD(db *gorm.DB, redis *rejson.Handler, crdClient *versioned.Clientset, ns string) (string, error) {
 
 	deletePolicy := metav1.DeletePropagationForeground
 	if err := crdClient.NchcV1alpha1().Courses(ns).
-		Delete(context.Background(), j.ID, metav1.DeleteOptions{PropagationPolicy: &deletePolicy}); err != nil {
+		Delete(context.Background(), j.ID, metav1.DeleteOptions{PropagationPolicy: &deletePolicy}); err != nil && !errors.IsNotFound(err) {
 		return fmt.Sprintf("Failed to delete Course CRD {%s}: %s", j.ID, err.Error()), err
 	}
 
@@ -113,3 +121,49 @@ func (j *Job) UserJobCount(db *gorm.DB) (int, error) {
 	}
 	return count, nil
 }
+
+// ClassroomJobs returns all jobs launched in classroom.
+func ClassroomJobs(db *gorm.DB, classroomID string) ([]Job, error) {
+	jobs := []Job{}
+	if err := db.Where("classroom_id = ?", classroomID).Order("created_at").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ClassroomCourseJobs returns all jobs of course launched in classroom.
+func ClassroomCourseJobs(db *gorm.DB, classroomID, courseID string) ([]Job, error) {
+	jobs := []Job{}
+	if err := db.Where("classroom_id = ? AND course_id = ?", classroomID, courseID).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// JobsCreatedSince returns jobs created at or after since.
+func JobsCreatedSince(db *gorm.DB, since time.Time) ([]Job, error) {
+	jobs := []Job{}
+	if err := db.Where("created_at >= ?", since).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ExtendJob adds minutes to extension of job, only if job is still extended by from minutes and new extension
+// does not exceed maxExtension, zero maxExtension means unlimited. False is returned if job is not updated.
+func ExtendJob(db *gorm.DB, id string, from, minutes, maxExtension int32) (bool, error) {
+	query := db.Model(&Job{}).Where("id = ? AND extended = ?", id, from)
+	if maxExtension > 0 {
+		query = query.Where("extended + ? <= ?", minutes, maxExtension)
+	}
+	result := query.UpdateColumn("extended", gorm.Expr("extended + ?", minutes))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// TouchJob marks job is active now.
+func TouchJob(db *gorm.DB, id string) error {
+	return db.Model(&Job{}).Where("id = ?", id).UpdateColumn("last_active_at", time.Now()).Error
+}
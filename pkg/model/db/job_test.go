@@ -0,0 +1,100 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassroomCourseJobs(t *testing.T) {
+	createFixtures(t, time.Time{},
+		&Job{Model: Model{ID: "job-1"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH}, CourseID: "course-a", ClassroomID: util.StringPtr("room-1"), Status: "Ready"},
+		&Job{Model: Model{ID: "job-2"}, OauthUser: OauthUser{User: "s2", Provider: GO_OAUTH}, CourseID: "course-a", ClassroomID: util.StringPtr("room-1"), Status: "Pending"},
+		&Job{Model: Model{ID: "job-3"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH}, CourseID: "course-b", ClassroomID: util.StringPtr("room-1"), Status: "Ready"},
+		&Job{Model: Model{ID: "job-4"}, OauthUser: OauthUser{User: "s3", Provider: GO_OAUTH}, CourseID: "course-a", ClassroomID: util.StringPtr("room-2"), Status: "Ready"},
+	)
+
+	jobs, err := ClassroomCourseJobs(Sqlite, "room-1", "course-a")
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	assert.ElementsMatch(t, []string{"job-1", "job-2"}, ids)
+
+	jobs, err = ClassroomCourseJobs(Sqlite, "room-3", "course-a")
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	jobs, err = ClassroomJobs(Sqlite, "room-1")
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 3)
+}
+
+func TestJobsCreatedSince(t *testing.T) {
+	now := time.Now()
+	old := Job{Model: Model{ID: "job-since-old", CreatedAt: now.Add(-time.Hour)}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH},
+		CourseID: "course-since", Status: "Ready"}
+	recent := Job{Model: Model{ID: "job-since-new"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH},
+		CourseID: "course-since", Status: "Created"}
+	assert.NoError(t, old.NewEntry(Sqlite))
+	assert.NoError(t, recent.NewEntry(Sqlite))
+
+	jobs, err := JobsCreatedSince(Sqlite, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	assert.Contains(t, ids, "job-since-new")
+	assert.NotContains(t, ids, "job-since-old")
+}
+
+func TestTouchJob(t *testing.T) {
+	touched := Job{Model: Model{ID: "job-touch-1"}, OauthUser: OauthUser{User: "s2", Provider: GO_OAUTH},
+		CourseID: "course-touch", Status: "Ready"}
+	other := Job{Model: Model{ID: "job-touch-2"}, OauthUser: OauthUser{User: "s2", Provider: GO_OAUTH},
+		CourseID: "course-touch", Status: "Ready"}
+	assert.NoError(t, touched.NewEntry(Sqlite))
+	assert.NoError(t, other.NewEntry(Sqlite))
+
+	assert.NoError(t, TouchJob(Sqlite, "job-touch-1"))
+
+	result := Job{}
+	assert.NoError(t, Sqlite.Where("id = ?", "job-touch-1").First(&result).Error)
+	assert.NotNil(t, result.LastActiveAt)
+	result = Job{}
+	assert.NoError(t, Sqlite.Where("id = ?", "job-touch-2").First(&result).Error)
+	assert.Nil(t, result.LastActiveAt)
+}
+
+func TestExtendJob(t *testing.T) {
+	job := Job{Model: Model{ID: "job-extend"}, OauthUser: OauthUser{User: "s3", Provider: GO_OAUTH},
+		CourseID: "course-extend", Status: "Ready"}
+	assert.NoError(t, job.NewEntry(Sqlite))
+
+	ok, err := ExtendJob(Sqlite, "job-extend", 0, 30, 60)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// stale extension is rejected, as job is extended by another request
+	ok, err = ExtendJob(Sqlite, "job-extend", 0, 30, 60)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// exceed maximum extension
+	ok, err = ExtendJob(Sqlite, "job-extend", 30, 40, 60)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// zero maximum extension is unlimited
+	ok, err = ExtendJob(Sqlite, "job-extend", 30, 120, 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	result := Job{}
+	assert.NoError(t, Sqlite.Where("id = ?", "job-extend").First(&result).Error)
+	assert.Equal(t, int32(150), result.Extended)
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Lifetime is effective maximum runtime and idle timeout of a job in minutes, zero means unlimited.
+type Lifetime struct {
+	MaxRuntime  int32
+	IdleTimeout int32
+}
+
+// stricter returns the smaller positive limit, zero or nil is treated as unlimited.
+func stricter(current int32, limit *int32) int32 {
+	if limit == nil || *limit <= 0 {
+		return current
+	}
+	if current == 0 || *limit < current {
+		return *limit
+	}
+	return current
+}
+
+// GetLifetime merges lifetime policy of classroom and course where job is launched, the stricter one wins.
+func (j *Job) GetLifetime(DB *gorm.DB) (*Lifetime, error) {
+	lifetime := Lifetime{}
+
+	course := Course{}
+	if result := DB.Where("id = ?", j.CourseID).First(&course); result.Error != nil && !result.RecordNotFound() {
+		return nil, result.Error
+	}
+	lifetime.MaxRuntime = stricter(lifetime.MaxRuntime, course.MaxRuntime)
+	lifetime.IdleTimeout = stricter(lifetime.IdleTimeout, course.IdleTimeout)
+
+	if j.ClassroomID != nil {
+		classroom := ClassRoomInfo{}
+		if result := DB.Where("id = ?", *j.ClassroomID).First(&classroom); result.Error != nil && !result.RecordNotFound() {
+			return nil, result.Error
+		}
+		lifetime.MaxRuntime = stricter(lifetime.MaxRuntime, classroom.MaxRuntime)
+		lifetime.IdleTimeout = stricter(lifetime.IdleTimeout, classroom.IdleTimeout)
+	}
+
+	return &lifetime, nil
+}
+
+// ExpireAt returns when job reaches maximum runtime plus extension, zero time if unlimited.
+func (l *Lifetime) ExpireAt(job *Job) time.Time {
+	if l.MaxRuntime <= 0 {
+		return time.Time{}
+	}
+	// sum of int32 minutes may overflow int32, and a Duration of it may overflow int64 nanoseconds,
+	// so whole days are added by date
+	minutes := int64(l.MaxRuntime) + int64(job.Extended)
+	return job.CreatedAt.AddDate(0, 0, int(minutes/(24*60))).Add(time.Duration(minutes%(24*60)) * time.Minute)
+}
+
+// IdleAt returns when job is regarded as idle, zero time if unlimited.
+func (l *Lifetime) IdleAt(job *Job) time.Time {
+	if l.IdleTimeout <= 0 {
+		return time.Time{}
+	}
+	lastActive := job.CreatedAt
+	if job.LastActiveAt != nil && job.LastActiveAt.After(lastActive) {
+		lastActive = *job.LastActiveAt
+	}
+	return lastActive.Add(time.Duration(l.IdleTimeout) * time.Minute)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStricter(t *testing.T) {
+	assert.Equal(t, int32(0), stricter(0, nil))
+	assert.Equal(t, int32(0), stricter(0, util.Int32Ptr(0)))
+	assert.Equal(t, int32(60), stricter(0, util.Int32Ptr(60)))
+	assert.Equal(t, int32(30), stricter(60, util.Int32Ptr(30)))
+	assert.Equal(t, int32(30), stricter(30, util.Int32Ptr(60)))
+}
+
+func TestLifetime_Deadline(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	job := Job{
+		Model: Model{
+			CreatedAt: start,
+		},
+	}
+
+	unlimited := Lifetime{}
+	assert.True(t, unlimited.ExpireAt(&job).IsZero())
+	assert.True(t, unlimited.IdleAt(&job).IsZero())
+
+	lifetime := Lifetime{MaxRuntime: 60, IdleTimeout: 15}
+	assert.Equal(t, start.Add(time.Hour), lifetime.ExpireAt(&job))
+	assert.Equal(t, start.Add(15*time.Minute), lifetime.IdleAt(&job))
+
+	active := start.Add(30 * time.Minute)
+	job.LastActiveAt = &active
+	assert.Equal(t, active.Add(15*time.Minute), lifetime.IdleAt(&job))
+}
+
+func TestLifetime_ExpireAtOverflow(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	job := Job{
+		Model: Model{
+			CreatedAt: start,
+		},
+		Extended: math.MaxInt32,
+	}
+
+	lifetime := Lifetime{MaxRuntime: 60}
+	minutes := int64(math.MaxInt32) + 60
+	expected := start.AddDate(0, 0, int(minutes/(24*60))).Add(time.Duration(minutes%(24*60)) * time.Minute)
+	assert.Equal(t, expected, lifetime.ExpireAt(&job))
+	assert.True(t, lifetime.ExpireAt(&job).After(start))
+
+	job.Extended = 24*60 + 30
+	assert.Equal(t, start.Add(25*time.Hour+30*time.Minute), lifetime.ExpireAt(&job))
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+)
+
+const (
+	QUEUE_POLICY_FIFO     = "fifo"
+	QUEUE_POLICY_PRIORITY = "priority"
+)
+
+// priority of queued launch request, higher is launched first in priority policy
+const (
+	QUEUE_PRIORITY_STUDENT = 0
+	QUEUE_PRIORITY_TEACHER = 10
+)
+
+// QueuedJob is a launch request waiting for free GPU. ID is reused as job id when it is launched.
+type QueuedJob struct {
+	Model
+	OauthUser
+	CourseID    string `gorm:"size:36;not null"`
+	ClassroomID string `gorm:"size:72;not null"`
+	Gpu         int32  `gorm:"not null"`
+	Priority    int    `gorm:"not null;default:0"`
+}
+
+func (QueuedJob) TableName() string {
+	return "jobQueue"
+}
+
+func (q *QueuedJob) NewEntry(DB *gorm.DB) error {
+	if err := DB.Create(q).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *QueuedJob) Delete(DB *gorm.DB) error {
+	if err := DB.Unscoped().Delete(q).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListQueue returns queued launch requests in launch order of policy.
+func ListQueue(DB *gorm.DB, policy string) ([]QueuedJob, error) {
+	query := DB
+	if policy == QUEUE_POLICY_PRIORITY {
+		query = query.Order("priority desc")
+	}
+
+	results := []QueuedJob{}
+	if err := query.Order("created_at").Order("id").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueuePositions returns 1-based position of every queued launch request.
+func QueuePositions(DB *gorm.DB, policy string) (map[string]int, error) {
+	queue, err := ListQueue(DB, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int)
+	for i, q := range queue {
+		positions[q.ID] = i + 1
+	}
+	return positions, nil
+}
+
+// ClassroomCourseQueuedJobs returns queued launch requests of course in classroom.
+func ClassroomCourseQueuedJobs(DB *gorm.DB, classroomID, courseID string) ([]QueuedJob, error) {
+	results := []QueuedJob{}
+	if err := DB.Where("classroom_id = ? AND course_id = ?", classroomID, courseID).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UserQueuedJobs returns queued launch requests of user.
+func UserQueuedJobs(DB *gorm.DB, user, provider string) ([]QueuedJob, error) {
+	results := []QueuedJob{}
+	if err := DB.Where("user = ? AND provider = ?", user, provider).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	createFixtures(t, start,
+		&QueuedJob{Model: Model{ID: "queue-1"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH}, CourseID: "course-1", ClassroomID: "classroom-1", Gpu: 1, Priority: QUEUE_PRIORITY_STUDENT},
+		&QueuedJob{Model: Model{ID: "queue-2"}, OauthUser: OauthUser{User: "t1", Provider: GO_OAUTH}, Gpu: 2, Priority: QUEUE_PRIORITY_TEACHER},
+		&QueuedJob{Model: Model{ID: "queue-3"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH}, CourseID: "course-1", ClassroomID: "classroom-2", Gpu: 1, Priority: QUEUE_PRIORITY_STUDENT},
+	)
+
+	positions, err := QueuePositions(Sqlite, QUEUE_POLICY_FIFO)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"queue-1": 1, "queue-2": 2, "queue-3": 3}, positions)
+
+	positions, err = QueuePositions(Sqlite, QUEUE_POLICY_PRIORITY)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"queue-2": 1, "queue-1": 2, "queue-3": 3}, positions)
+
+	queued, err := UserQueuedJobs(Sqlite, "s1", GO_OAUTH)
+	assert.NoError(t, err)
+	assert.Len(t, queued, 2)
+
+	queued, err = ClassroomCourseQueuedJobs(Sqlite, "classroom-1", "course-1")
+	assert.NoError(t, err)
+	assert.Len(t, queued, 1)
+	assert.Equal(t, "queue-1", queued[0].ID)
+
+	head := QueuedJob{Model: Model{ID: "queue-2"}}
+	assert.NoError(t, head.Delete(Sqlite))
+	positions, err = QueuePositions(Sqlite, QUEUE_POLICY_PRIORITY)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"queue-1": 1, "queue-3": 2}, positions)
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+)
+
+const (
+	QUOTA_SCOPE_ROLE      = "role"
+	QUOTA_SCOPE_CLASSROOM = "classroom"
+	QUOTA_SCOPE_USER      = "user"
+)
+
+// Quota overrides default quota in APIConfig for a role, a classroom or a user.
+// Nil field means inherit from less specific scope, negative limit means unlimited and zero limit allows nothing.
+// Target is role name, classroom id or "provider:user" according to Scope.
+type Quota struct {
+	Scope     string `gorm:"primary_key;size:20" json:"scope"`
+	Target    string `gorm:"primary_key;size:100" json:"target"`
+	MaxJobs   *int   `json:"maxJobs,omitempty"`
+	MaxGpu    *int32 `json:"maxGpu,omitempty"`
+	MaxCpu    *int64 `json:"maxCpu,omitempty"`
+	MaxMemory *int64 `json:"maxMemory,omitempty"`
+}
+
+func (Quota) TableName() string {
+	return "quotas"
+}
+
+func UserQuotaTarget(user, provider string) string {
+	return fmt.Sprintf("%s:%s", provider, user)
+}
+
+func IsValidQuotaScope(scope string) bool {
+	return scope == QUOTA_SCOPE_ROLE || scope == QUOTA_SCOPE_CLASSROOM || scope == QUOTA_SCOPE_USER
+}
+
+func (q *Quota) Save(DB *gorm.DB) error {
+	if err := DB.Save(q).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *Quota) Delete(DB *gorm.DB) error {
+	if err := DB.Where("scope = ? AND target = ?", q.Scope, q.Target).Delete(&Quota{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func ListQuota(DB *gorm.DB) ([]Quota, error) {
+	results := []Quota{}
+	if err := DB.Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func findQuota(DB *gorm.DB, scope, target string) (*Quota, error) {
+	q := Quota{}
+	result := DB.Where("scope = ? AND target = ?", scope, target).First(&q)
+	if result.RecordNotFound() {
+		return nil, nil
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &q, nil
+}
+
+// Limit is the effective quota of a user when launching job, negative limit means unlimited.
+type Limit struct {
+	Jobs   int
+	Gpu    int32
+	Cpu    int64
+	Memory int64
+}
+
+func (l *Limit) override(q *Quota) {
+	if q == nil {
+		return
+	}
+	if q.MaxJobs != nil {
+		l.Jobs = *q.MaxJobs
+	}
+	if q.MaxGpu != nil {
+		l.Gpu = *q.MaxGpu
+	}
+	if q.MaxCpu != nil {
+		l.Cpu = *q.MaxCpu
+	}
+	if q.MaxMemory != nil {
+		l.Memory = *q.MaxMemory
+	}
+}
+
+// EffectiveLimit merges default quota with role, classroom and user overrides, the most specific one wins.
+// classroomID can be empty when job is launched outside classroom.
+func EffectiveLimit(DB *gorm.DB, defaults config.QuotaConfig, role, classroomID, user, provider string) (*Limit, error) {
+	limit := Limit{
+		Jobs:   defaults.MaxJobs,
+		Gpu:    defaults.MaxGpu,
+		Cpu:    defaults.MaxCpu,
+		Memory: defaults.MaxMemory,
+	}
+
+	scopes := [][]string{
+		{QUOTA_SCOPE_ROLE, role},
+		{QUOTA_SCOPE_CLASSROOM, classroomID},
+		{QUOTA_SCOPE_USER, UserQuotaTarget(user, provider)},
+	}
+
+	for _, s := range scopes {
+		if s[1] == "" {
+			continue
+		}
+		q, err := findQuota(DB, s[0], s[1])
+		if err != nil {
+			return nil, err
+		}
+		limit.override(q)
+	}
+
+	return &limit, nil
+}
+
+// Usage is resource occupied by running jobs of a user.
+type Usage struct {
+	Jobs   int
+	Gpu    int32
+	Cpu    int64
+	Memory int64
+}
+
+// UserContainerUsage sums job count, gpu, cpu and memory of running and queued container jobs of user.
+// Cpu and memory of job are taken from resource profile of course, or defaults of each job if not specified.
+func UserContainerUsage(DB *gorm.DB, user, provider string, defaults config.QuotaConfig) (*Usage, error) {
+	usage := Usage{}
+
+	course := Course{}.TableName()
+	rows, err := DB.Table(Job{}.TableName()).
+		Select(fmt.Sprintf("COUNT(*), COALESCE(SUM(%s.gpu), 0), COALESCE(SUM(COALESCE(%s.cpu_request, ?)), 0), "+
+			"COALESCE(SUM(COALESCE(%s.memory_request, ?)), 0)", course, course, course), defaults.JobCpu, defaults.JobMemory).
+		Joins(fmt.Sprintf("LEFT JOIN %s ON %s.id = %s.course_id",
+			course, course, Job{}.TableName())).
+		Where(fmt.Sprintf("%s.user = ? AND %s.provider = ? AND %s.deleted_at IS NULL",
+			Job{}.TableName(), Job{}.TableName(), Job{}.TableName()), user, provider).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&usage.Jobs, &usage.Gpu, &usage.Cpu, &usage.Memory); err != nil {
+			return nil, err
+		}
+	}
+
+	// queued launch requests will be running jobs, count them in advance
+	queued, err := UserQueuedJobs(DB, user, provider)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range queued {
+		usage.Jobs++
+		usage.Gpu += q.Gpu
+		profile := ResourceProfile{}
+		if c, err := GetCourse(DB, q.CourseID); err == nil {
+			profile = c.ResourceProfile
+		}
+		usage.Cpu += profile.QuotaCpu(defaults)
+		usage.Memory += profile.QuotaMemory(defaults)
+	}
+
+	return &usage, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveLimit(t *testing.T) {
+	defaults := config.QuotaConfig{
+		MaxJobs:   1,
+		MaxGpu:    1,
+		MaxCpu:    -1,
+		MaxMemory: -1,
+	}
+	provider := GO_OAUTH + ":" + "test-provider"
+
+	limit, err := EffectiveLimit(Sqlite, defaults, RoleStudent, "", "user@quota", provider)
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Jobs: 1, Gpu: 1, Cpu: -1, Memory: -1}, *limit)
+
+	// zero is kept as is, it allows nothing rather than falls back to default
+	limit, err = EffectiveLimit(Sqlite, config.QuotaConfig{}, RoleStudent, "", "user@quota", provider)
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{}, *limit)
+
+	overrides := []Quota{
+		{Scope: QUOTA_SCOPE_ROLE, Target: RoleTeacher, MaxJobs: util.IntPtr(3), MaxGpu: util.Int32Ptr(2)},
+		{Scope: QUOTA_SCOPE_CLASSROOM, Target: "classroom-quota", MaxJobs: util.IntPtr(2)},
+		{Scope: QUOTA_SCOPE_USER, Target: UserQuotaTarget("user@quota", provider), MaxGpu: util.Int32Ptr(4)},
+		{Scope: QUOTA_SCOPE_USER, Target: UserQuotaTarget("user@unlimited", provider), MaxJobs: util.IntPtr(-1), MaxGpu: util.Int32Ptr(0)},
+	}
+	for _, q := range overrides {
+		assert.NoError(t, q.Save(Sqlite))
+	}
+
+	limit, err = EffectiveLimit(Sqlite, defaults, RoleTeacher, "", "user1@quota", provider)
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Jobs: 3, Gpu: 2, Cpu: -1, Memory: -1}, *limit)
+
+	// classroom overrides role, user overrides classroom
+	limit, err = EffectiveLimit(Sqlite, defaults, RoleTeacher, "classroom-quota", "user@quota", provider)
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Jobs: 2, Gpu: 4, Cpu: -1, Memory: -1}, *limit)
+
+	assert.NoError(t, overrides[2].Delete(Sqlite))
+	limit, err = EffectiveLimit(Sqlite, defaults, RoleTeacher, "classroom-quota", "user@quota", provider)
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Jobs: 2, Gpu: 2, Cpu: -1, Memory: -1}, *limit)
+
+	// negative override lifts limit, zero override forbids gpu
+	limit, err = EffectiveLimit(Sqlite, defaults, RoleStudent, "", "user@unlimited", provider)
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Jobs: -1, Gpu: 0, Cpu: -1, Memory: -1}, *limit)
+}
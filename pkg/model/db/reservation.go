@@ -0,0 +1,123 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Reservation books GPU for a classroom in a time window, made by classroom teacher.
+// Reserved GPU can only be used by jobs of the classroom in the window.
+type Reservation struct {
+	Model
+	OauthUser
+	ClassroomID string    `gorm:"size:36;not null;index" json:"classroom_id"`
+	Gpu         int32     `gorm:"not null" json:"gpu"`
+	StartAt     time.Time `gorm:"not null" json:"startAt"`
+	EndAt       time.Time `gorm:"not null" json:"endAt"`
+}
+
+func (Reservation) TableName() string {
+	return "gpuReservation"
+}
+
+func (r *Reservation) NewEntry(DB *gorm.DB) error {
+	if err := DB.Create(r).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Reservation) Delete(DB *gorm.DB) error {
+	if err := DB.Unscoped().Delete(r).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListReservations returns reservations not yet ended at from, in order of start time.
+// All classrooms are returned if classroomID is empty.
+func ListReservations(DB *gorm.DB, classroomID string, from time.Time) ([]Reservation, error) {
+	query := DB.Where("end_at > ?", from)
+	if classroomID != "" {
+		query = query.Where("classroom_id = ?", classroomID)
+	}
+
+	results := []Reservation{}
+	if err := query.Order("start_at").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// OverlapReservations returns reservations whose window overlaps [start, end).
+func OverlapReservations(DB *gorm.DB, start, end time.Time) ([]Reservation, error) {
+	results := []Reservation{}
+	if err := DB.Where("start_at < ? AND end_at > ?", end, start).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ActiveReservations returns reservations whose window covers t.
+func ActiveReservations(DB *gorm.DB, t time.Time) ([]Reservation, error) {
+	results := []Reservation{}
+	if err := DB.Where("start_at <= ? AND end_at > ?", t, t).Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// PeakReserved returns maximum GPU reserved at the same time by reservations.
+func PeakReserved(reservations []Reservation) int64 {
+	type point struct {
+		at  time.Time
+		gpu int64
+	}
+
+	points := []point{}
+	for _, r := range reservations {
+		points = append(points, point{at: r.StartAt, gpu: int64(r.Gpu)}, point{at: r.EndAt, gpu: -int64(r.Gpu)})
+	}
+	// window is half-open, release at the same time is handled before booking
+	sort.Slice(points, func(i, k int) bool {
+		if points[i].at.Equal(points[k].at) {
+			return points[i].gpu < points[k].gpu
+		}
+		return points[i].at.Before(points[k].at)
+	})
+
+	peak, current := int64(0), int64(0)
+	for _, p := range points {
+		current += p.gpu
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
+
+// ClassroomGpuUsage sums gpu of running container jobs in classroom.
+func ClassroomGpuUsage(DB *gorm.DB, classroomID string) (int64, error) {
+	rows, err := DB.Table(Job{}.TableName()).
+		Select(fmt.Sprintf("COALESCE(SUM(%s.gpu), 0)", Course{}.TableName())).
+		Joins(fmt.Sprintf("LEFT JOIN %s ON %s.id = %s.course_id",
+			Course{}.TableName(), Course{}.TableName(), Job{}.TableName())).
+		Where(fmt.Sprintf("%s.classroom_id = ? AND %s.deleted_at IS NULL",
+			Job{}.TableName(), Job{}.TableName()), classroomID).
+		Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	usage := int64(0)
+	if rows.Next() {
+		if err := rows.Scan(&usage); err != nil {
+			return 0, err
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservation(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	createFixtures(t, time.Time{},
+		&Reservation{Model: Model{ID: "reserve-1"}, ClassroomID: "classroom-a", Gpu: 2, StartAt: start, EndAt: start.Add(2 * time.Hour)},
+		&Reservation{Model: Model{ID: "reserve-2"}, ClassroomID: "classroom-b", Gpu: 3, StartAt: start.Add(time.Hour), EndAt: start.Add(3 * time.Hour)},
+		&Reservation{Model: Model{ID: "reserve-3"}, ClassroomID: "classroom-a", Gpu: 4, StartAt: start.Add(3 * time.Hour), EndAt: start.Add(4 * time.Hour)},
+	)
+
+	// reserve-1 and reserve-2 overlap, reserve-3 starts when reserve-2 ends
+	overlap, err := OverlapReservations(Sqlite, start, start.Add(4*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, overlap, 3)
+	assert.Equal(t, int64(5), PeakReserved(overlap))
+
+	overlap, err = OverlapReservations(Sqlite, start.Add(2*time.Hour), start.Add(4*time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, overlap, 2)
+	assert.Equal(t, int64(4), PeakReserved(overlap))
+
+	active, err := ActiveReservations(Sqlite, start.Add(90*time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, active, 2)
+
+	listed, err := ListReservations(Sqlite, "classroom-a", start.Add(150*time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, listed, 1)
+	assert.Equal(t, "reserve-3", listed[0].ID)
+
+	assert.NoError(t, listed[0].Delete(Sqlite))
+	listed, err = ListReservations(Sqlite, "", start)
+	assert.NoError(t, err)
+	assert.Len(t, listed, 2)
+}
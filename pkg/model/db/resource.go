@@ -0,0 +1,116 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const DEFAULT_GPU_MODEL_LABEL = "nvidia.com/gpu.product"
+
+// ResourceProfile is resource requests and limits of course job. Nil field is not specified and left to cluster default.
+type ResourceProfile struct {
+	CpuRequest     *int64  `json:"cpuRequest,omitempty"`                   // millicores
+	CpuLimit       *int64  `json:"cpuLimit,omitempty"`                     // millicores
+	MemoryRequest  *int64  `json:"memoryRequest,omitempty"`                // MiB
+	MemoryLimit    *int64  `json:"memoryLimit,omitempty"`                  // MiB
+	StorageRequest *int64  `json:"storageRequest,omitempty"`               // MiB of ephemeral storage
+	StorageLimit   *int64  `json:"storageLimit,omitempty"`                 // MiB of ephemeral storage
+	NodeSelector   *string `gorm:"size:500" json:"nodeSelector,omitempty"` // in k1=v1,k2=v2 format
+	GpuModel       *string `gorm:"size:100" json:"gpuModel,omitempty"`
+}
+
+// Validate checks request is not larger than limit, and both are within maximum defined by admin.
+func (p *ResourceProfile) Validate(max config.ResourceConfig) error {
+	checks := []struct {
+		name           string
+		request, limit *int64
+		maximum        int64
+		unit           string
+	}{
+		{"cpu", p.CpuRequest, p.CpuLimit, max.MaxCpu, "m"},
+		{"memory", p.MemoryRequest, p.MemoryLimit, max.MaxMemory, "Mi"},
+		{"ephemeral storage", p.StorageRequest, p.StorageLimit, max.MaxStorage, "Mi"},
+	}
+
+	for _, c := range checks {
+		for _, v := range []*int64{c.request, c.limit} {
+			if v == nil {
+				continue
+			}
+			if *v < 0 {
+				return fmt.Errorf("%s can not be negative", c.name)
+			}
+			if c.maximum > 0 && *v > c.maximum {
+				return fmt.Errorf("%s %d%s exceeds maximum %d%s", c.name, *v, c.unit, c.maximum, c.unit)
+			}
+		}
+		if c.request != nil && c.limit != nil && *c.limit > 0 && *c.request > *c.limit {
+			return fmt.Errorf("%s request %d%s is larger than limit %d%s", c.name, *c.request, c.unit, *c.limit, c.unit)
+		}
+	}
+
+	if _, err := p.NodeSelectorMap(max.GpuModelLabel); err != nil {
+		return err
+	}
+
+	if p.GpuModel != nil && *p.GpuModel != "" && len(max.GpuModels) > 0 {
+		allowed := false
+		for _, m := range max.GpuModels {
+			allowed = allowed || m == *p.GpuModel
+		}
+		if !allowed {
+			return fmt.Errorf("gpu model {%s} is not allowed", *p.GpuModel)
+		}
+	}
+
+	return nil
+}
+
+// NodeSelectorMap merges node selector and GPU model label, nil is returned if neither is specified.
+func (p *ResourceProfile) NodeSelectorMap(gpuModelLabel string) (map[string]string, error) {
+	selector := map[string]string{}
+	if p.NodeSelector != nil && *p.NodeSelector != "" {
+		m, err := labels.ConvertSelectorToLabelsMap(*p.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector {%s}: %s", *p.NodeSelector, err.Error())
+		}
+		selector = m
+	}
+
+	if p.GpuModel != nil && *p.GpuModel != "" {
+		if gpuModelLabel == "" {
+			gpuModelLabel = DEFAULT_GPU_MODEL_LABEL
+		}
+		selector[gpuModelLabel] = *p.GpuModel
+	}
+
+	if len(selector) == 0 {
+		return nil, nil
+	}
+	return selector, nil
+}
+
+// QuotaCpu is cpu counted in quota for job of course, cpu request or default of each job.
+// Limit is counted if only limit is set, since kubernetes defaults request to limit.
+func (p *ResourceProfile) QuotaCpu(defaults config.QuotaConfig) int64 {
+	return quotaOf(p.CpuRequest, p.CpuLimit, defaults.JobCpu)
+}
+
+// QuotaMemory is memory counted in quota for job of course, memory request or default of each job.
+// Limit is counted if only limit is set, since kubernetes defaults request to limit.
+func (p *ResourceProfile) QuotaMemory(defaults config.QuotaConfig) int64 {
+	return quotaOf(p.MemoryRequest, p.MemoryLimit, defaults.JobMemory)
+}
+
+// quotaOf returns positive request, or positive limit, or default, the same as resourceRequirements skips zero value.
+func quotaOf(request, limit *int64, def int64) int64 {
+	if request != nil && *request > 0 {
+		return *request
+	}
+	if limit != nil && *limit > 0 {
+		return *limit
+	}
+	return def
+}
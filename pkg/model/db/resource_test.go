@@ -0,0 +1,89 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceProfileValidate(t *testing.T) {
+	max := config.ResourceConfig{
+		MaxCpu:    4000,
+		MaxMemory: 8192,
+		GpuModels: []string{"Tesla-V100"},
+	}
+
+	valid := ResourceProfile{
+		CpuRequest:     util.Int64Ptr(1000),
+		CpuLimit:       util.Int64Ptr(4000),
+		StorageRequest: util.Int64Ptr(100000),
+		NodeSelector:   util.StringPtr("zone=lab1"),
+		GpuModel:       util.StringPtr("Tesla-V100"),
+	}
+	assert.NoError(t, valid.Validate(max))
+
+	selector, err := valid.NodeSelectorMap("")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"zone": "lab1", DEFAULT_GPU_MODEL_LABEL: "Tesla-V100"}, selector)
+
+	for _, p := range []ResourceProfile{
+		{CpuLimit: util.Int64Ptr(8000)},
+		{MemoryRequest: util.Int64Ptr(4096), MemoryLimit: util.Int64Ptr(2048)},
+		{StorageLimit: util.Int64Ptr(-1)},
+		{NodeSelector: util.StringPtr("zone")},
+		{GpuModel: util.StringPtr("K80")},
+	} {
+		profile := p
+		assert.Error(t, profile.Validate(max))
+	}
+
+	empty := ResourceProfile{}
+	selector, err = empty.NodeSelectorMap("")
+	assert.NoError(t, err)
+	assert.Nil(t, selector)
+}
+
+func TestUserContainerUsage(t *testing.T) {
+	defaults := config.QuotaConfig{
+		JobCpu:    1000,
+		JobMemory: 2048,
+	}
+
+	for _, c := range []Course{
+		{Model: Model{ID: "course-profile"}, Name: "profile", Image: "image", Gpu: util.Int32Ptr(1), WritablePath: util.StringPtr(""),
+			ResourceProfile: ResourceProfile{CpuRequest: util.Int64Ptr(500), MemoryRequest: util.Int64Ptr(4096)}},
+		{Model: Model{ID: "course-default"}, Name: "default", Image: "image", Gpu: util.Int32Ptr(0), WritablePath: util.StringPtr("")},
+	} {
+		course := c
+		assert.NoError(t, Sqlite.Create(&course).Error)
+	}
+
+	createFixtures(t, time.Time{},
+		&Job{Model: Model{ID: "job-profile"}, OauthUser: OauthUser{User: "u-usage", Provider: GO_OAUTH}, CourseID: "course-profile", ClassroomID: util.StringPtr("room-usage")},
+		&Job{Model: Model{ID: "job-default"}, OauthUser: OauthUser{User: "u-usage", Provider: GO_OAUTH}, CourseID: "course-default", ClassroomID: util.StringPtr("room-usage")},
+	)
+	queued := QueuedJob{Model: Model{ID: "queue-usage"}, OauthUser: OauthUser{User: "u-usage", Provider: GO_OAUTH}, CourseID: "course-profile", Gpu: 1}
+	assert.NoError(t, queued.NewEntry(Sqlite))
+
+	usage, err := UserContainerUsage(Sqlite, "u-usage", GO_OAUTH, defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Jobs: 3, Gpu: 2, Cpu: 2000, Memory: 10240}, *usage)
+}
+
+func TestQuotaOfLimitOnlyProfile(t *testing.T) {
+	defaults := config.QuotaConfig{
+		JobCpu:    1000,
+		JobMemory: 2048,
+	}
+
+	limitOnly := ResourceProfile{CpuLimit: util.Int64Ptr(4000), MemoryLimit: util.Int64Ptr(8192)}
+	assert.Equal(t, int64(4000), limitOnly.QuotaCpu(defaults))
+	assert.Equal(t, int64(8192), limitOnly.QuotaMemory(defaults))
+
+	both := ResourceProfile{CpuRequest: util.Int64Ptr(500), CpuLimit: util.Int64Ptr(4000), MemoryRequest: util.Int64Ptr(0)}
+	assert.Equal(t, int64(500), both.QuotaCpu(defaults))
+	assert.Equal(t, int64(2048), both.QuotaMemory(defaults))
+}
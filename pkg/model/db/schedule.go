@@ -0,0 +1,90 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// status of scheduled job
+const (
+	SCHEDULE_PENDING  = "Pending"
+	SCHEDULE_LAUNCHED = "Launched"
+	SCHEDULE_STOPPED  = "Stopped"
+	SCHEDULE_FAILED   = "Failed"
+)
+
+// ScheduledJob is a request to launch container job at StartAt, and stop it at StopAt if StopAt is set.
+type ScheduledJob struct {
+	Model
+	OauthUser
+	CourseID    string     `gorm:"size:36;not null" json:"course_id"`
+	ClassroomID string     `gorm:"size:72" json:"classroom_id"`
+	StartAt     time.Time  `gorm:"not null;index" json:"startAt"`
+	StopAt      *time.Time `json:"stopAt,omitempty"`
+	Status      string     `gorm:"size:20;not null" json:"status"`
+	// id of launched job, or queued launch request
+	JobID   string `gorm:"size:72" json:"job_id,omitempty"`
+	Message string `gorm:"size:1000" json:"message,omitempty"`
+}
+
+func (ScheduledJob) TableName() string {
+	return "jobSchedule"
+}
+
+func (s *ScheduledJob) NewEntry(DB *gorm.DB) error {
+	if err := DB.Create(s).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ScheduledJob) Update(DB *gorm.DB) error {
+	if err := DB.Save(s).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ScheduledJob) Delete(DB *gorm.DB) error {
+	if err := DB.Unscoped().Delete(s).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func GetScheduledJob(DB *gorm.DB, id string) (*ScheduledJob, error) {
+	result := ScheduledJob{}
+	if err := DB.Where("id = ?", id).First(&result).Error; err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UserScheduledJobs returns scheduled jobs of user in order of start time.
+func UserScheduledJobs(DB *gorm.DB, user, provider string) ([]ScheduledJob, error) {
+	results := []ScheduledJob{}
+	if err := DB.Where("user = ? AND provider = ?", user, provider).Order("start_at").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DueLaunches returns pending scheduled jobs which should be launched at t.
+func DueLaunches(DB *gorm.DB, t time.Time) ([]ScheduledJob, error) {
+	results := []ScheduledJob{}
+	if err := DB.Where("status = ? AND start_at <= ?", SCHEDULE_PENDING, t).Order("start_at").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DueStops returns launched scheduled jobs which should be stopped at t.
+func DueStops(DB *gorm.DB, t time.Time) ([]ScheduledJob, error) {
+	results := []ScheduledJob{}
+	if err := DB.Where("status = ? AND stop_at IS NOT NULL AND stop_at <= ?", SCHEDULE_LAUNCHED, t).
+		Order("stop_at").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
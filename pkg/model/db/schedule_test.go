@@ -0,0 +1,44 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledJob(t *testing.T) {
+	start := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+	createFixtures(t, time.Time{},
+		&ScheduledJob{Model: Model{ID: "schedule-1"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH}, StartAt: start, StopAt: &stop, Status: SCHEDULE_PENDING},
+		&ScheduledJob{Model: Model{ID: "schedule-2"}, OauthUser: OauthUser{User: "s1", Provider: GO_OAUTH}, StartAt: start.Add(2 * time.Hour), Status: SCHEDULE_PENDING},
+		&ScheduledJob{Model: Model{ID: "schedule-3"}, OauthUser: OauthUser{User: "s2", Provider: GO_OAUTH}, StartAt: start.Add(-time.Hour), StopAt: &start, Status: SCHEDULE_LAUNCHED},
+	)
+
+	due, err := DueLaunches(Sqlite, start.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, "schedule-1", due[0].ID)
+
+	stops, err := DueStops(Sqlite, start)
+	assert.NoError(t, err)
+	assert.Len(t, stops, 1)
+	assert.Equal(t, "schedule-3", stops[0].ID)
+
+	due[0].Status = SCHEDULE_LAUNCHED
+	due[0].JobID = "job-1"
+	assert.NoError(t, due[0].Update(Sqlite))
+	stops, err = DueStops(Sqlite, stop)
+	assert.NoError(t, err)
+	assert.Len(t, stops, 2)
+
+	schedules, err := UserScheduledJobs(Sqlite, "s1", GO_OAUTH)
+	assert.NoError(t, err)
+	assert.Len(t, schedules, 2)
+	assert.Equal(t, "job-1", schedules[0].JobID)
+
+	assert.NoError(t, schedules[1].Delete(Sqlite))
+	_, err = GetScheduledJob(Sqlite, "schedule-2")
+	assert.Error(t, err)
+}
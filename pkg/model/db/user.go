@@ -6,6 +6,12 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
+const (
+	ROLE_STUDENT   = "student"
+	ROLE_TEACHER   = "teacher"
+	ROLE_SUPERUSER = "superuser"
+)
+
 type User struct {
 	User       string  `sql:"unique_index:idx_first_second;size:50;not null" json:"user,omitempty"`
 	Provider   *string `sql:"unique_index:idx_first_second;size:30;not null;default:'default-provider'" json:"-"`
@@ -105,6 +111,10 @@ func (u *User) FindUser(db *gorm.DB) (*User, error) {
 	return &result, nil
 }
 
+func (u *User) IsSuperuser() bool {
+	return u.Role == ROLE_SUPERUSER
+}
+
 func MaxUid(db *gorm.DB) (uint64, error) {
 
 	result := User{}
@@ -28,7 +28,8 @@ func TestMain(m *testing.M) {
 		return
 	}
 	Sqlite = db
-	Sqlite.AutoMigrate(&User{})
+	Sqlite.AutoMigrate(&User{}, &Quota{}, &Job{}, &QueuedJob{}, &Reservation{}, &ScheduledJob{}, &ImageCommit{}, &ImageRule{}, &Course{}, &EnvVar{}, &Audit{},
+		&ClassRoomStudentRelation{}, &ClassRoomTeacherRelation{}, &ClassRoomCourseRelation{})
 
 	// Start Testing
 	m.Run()
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// WorkspaceUse is use of writable volume of a user in a classroom. Job rows are removed when job is deleted,
+// so last use is derived from job audit, and volume is in use if user has running job of course with writable path.
+type WorkspaceUse struct {
+	LastUsedAt *time.Time
+	InUse      bool
+}
+
+// UserWorkspaceUse returns use of writable volume of user in each classroom.
+// Writable volume is owned by user name only, so jobs of all providers are counted.
+func UserWorkspaceUse(DB *gorm.DB, user string) (map[string]*WorkspaceUse, error) {
+	audits := []Audit{}
+	if err := DB.Unscoped().
+		Select(fmt.Sprintf("%s.*", Audit{}.TableName())).
+		Joins(fmt.Sprintf("JOIN %s ON %s.id = %s.course_id",
+			Course{}.TableName(), Course{}.TableName(), Audit{}.TableName())).
+		Where(fmt.Sprintf("%s.user = ? AND %s.writable_path <> ''", Audit{}.TableName(), Course{}.TableName()), user).
+		Find(&audits).Error; err != nil {
+		return nil, err
+	}
+
+	uses := map[string]*WorkspaceUse{}
+	use := func(classroomID *string) *WorkspaceUse {
+		if classroomID == nil {
+			return nil
+		}
+		if _, ok := uses[*classroomID]; !ok {
+			uses[*classroomID] = &WorkspaceUse{}
+		}
+		return uses[*classroomID]
+	}
+
+	for _, a := range audits {
+		u := use(a.ClassroomID)
+		if u == nil {
+			continue
+		}
+		last := a.CreatedAt
+		if a.DeletedAt != nil {
+			last = *a.DeletedAt
+		}
+		if u.LastUsedAt == nil || last.After(*u.LastUsedAt) {
+			u.LastUsedAt = &last
+		}
+	}
+
+	jobs := []Job{}
+	if err := DB.Select(fmt.Sprintf("%s.*", Job{}.TableName())).
+		Joins(fmt.Sprintf("JOIN %s ON %s.id = %s.course_id",
+			Course{}.TableName(), Course{}.TableName(), Job{}.TableName())).
+		Where(fmt.Sprintf("%s.user = ? AND %s.writable_path <> ''", Job{}.TableName(), Course{}.TableName()), user).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, j := range jobs {
+		if u := use(j.ClassroomID); u != nil {
+			u.InUse = true
+			u.LastUsedAt = &now
+		}
+	}
+
+	return uses, nil
+}
+
+// IsClassroomMember checks user of provider is student or teacher of classroom.
+func IsClassroomMember(DB *gorm.DB, classroomID, user, provider string) (bool, error) {
+	for _, table := range []string{ClassRoomStudentRelation{}.TableName(), ClassRoomTeacherRelation{}.TableName()} {
+		count := 0
+		if err := DB.Table(table).Where("classroom_id = ? AND user = ? AND provider = ?", classroomID, user, provider).Count(&count).Error; err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
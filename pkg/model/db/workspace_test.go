@@ -0,0 +1,56 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserWorkspaceUse(t *testing.T) {
+	for _, c := range []Course{
+		{Model: Model{ID: "course-writable"}, Name: "writable", Image: "image", Gpu: util.Int32Ptr(0), WritablePath: util.StringPtr("/work")},
+		{Model: Model{ID: "course-readonly"}, Name: "readonly", Image: "image", Gpu: util.Int32Ptr(0), WritablePath: util.StringPtr("")},
+	} {
+		course := c
+		assert.NoError(t, Sqlite.Create(&course).Error)
+	}
+
+	owner := OauthUser{User: "u-workspace", Provider: GO_OAUTH}
+	older := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	newer := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	createFixtures(t, time.Time{},
+		&Audit{Model: Model{ID: "audit-ws-1", DeletedAt: &older}, OauthUser: owner, CourseID: "course-writable", ClassroomID: util.StringPtr("room-a")},
+		&Audit{Model: Model{ID: "audit-ws-2", DeletedAt: &newer}, OauthUser: owner, CourseID: "course-writable", ClassroomID: util.StringPtr("room-a")},
+		&Audit{Model: Model{ID: "audit-ws-3", DeletedAt: &newer}, OauthUser: owner, CourseID: "course-readonly", ClassroomID: util.StringPtr("room-b")},
+	)
+	job := Job{Model: Model{ID: "job-ws"}, OauthUser: owner, CourseID: "course-writable", ClassroomID: util.StringPtr("room-c"), Status: "Ready"}
+	assert.NoError(t, job.NewEntry(Sqlite))
+
+	uses, err := UserWorkspaceUse(Sqlite, "u-workspace")
+	assert.NoError(t, err)
+	assert.Len(t, uses, 2)
+	assert.False(t, uses["room-a"].InUse)
+	assert.True(t, newer.Equal(*uses["room-a"].LastUsedAt))
+	assert.True(t, uses["room-c"].InUse)
+	assert.NotContains(t, uses, "room-b")
+}
+
+func TestIsClassroomMember(t *testing.T) {
+	student := ClassRoomStudentRelation{ClassRoomUser: ClassRoomUser{ClassroomID: "room-member", User: "u-student", Provider: GO_OAUTH}}
+	teacher := ClassRoomTeacherRelation{ClassRoomUser: ClassRoomUser{ClassroomID: "room-member", User: "u-teacher", Provider: GO_OAUTH}}
+	assert.NoError(t, Sqlite.Create(&student).Error)
+	assert.NoError(t, Sqlite.Create(&teacher).Error)
+
+	for user, expected := range map[string]bool{"u-student": true, "u-teacher": true, "u-stranger": false} {
+		ok, err := IsClassroomMember(Sqlite, "room-member", user, GO_OAUTH)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, user)
+	}
+
+	// same user name of other provider is not a member
+	ok, err := IsClassroomMember(Sqlite, "room-member", "u-student", "github-oauth")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -32,6 +32,7 @@ type TagInfo struct {
 	LastUpdatedUsername string  `json:"last_updater_username"`
 	Repository          int     `json:"repository"`
 	Name                string  `json:"name"`
+	Digest              string  `json:"digest"`
 	FullSize            int     `json:"full_size"`
 	V2                  bool    `json:"v2"`
 }
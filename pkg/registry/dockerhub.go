@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	img "github.com/nchc-ai/backend-api/pkg/model/image"
+)
+
+// DockerHub lists public images by Docker Hub repositories api.
+// Only image name start with consts.AiTrainImagePrefix is listed.
+type DockerHub struct {
+	base
+	url string
+}
+
+func NewDockerHub(cfg config.RegistryConfig) *DockerHub {
+	u := cfg.Url
+	if u == "" {
+		u = consts.BaseDockerHubUrl
+	}
+	if !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	return &DockerHub{
+		base: newBase(cfg),
+		url:  u,
+	}
+}
+
+// ListImages returns <namespace>/<name>:<tag> of images in namespace. Docker Hub api does not tell image labels,
+// so description is from repository and gpu is guessed from name.
+func (d *DockerHub) ListImages(namespace string) ([]Image, error) {
+	result := []Image{}
+
+	if namespace == "" {
+		return result, nil
+	}
+
+	repos, err := d.repositories(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repo := range repos {
+		tags, err := d.tags(namespace, repo.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tag := range tags {
+			image := Image{
+				Name:        fmt.Sprintf("%s/%s", namespace, repo.Name),
+				Tag:         tag.Name,
+				Digest:      tag.Digest,
+				Size:        int64(tag.FullSize),
+				LastUpdated: parseTime(tag.LastUpdated),
+				Description: repo.Description,
+				Gpu:         gpuFromName(repo.Name, tag.Name),
+			}
+			for _, i := range tag.Images {
+				if i.Architecture != "" && i.Architecture != "unknown" && !image.HasArchitecture(i.Architecture) {
+					image.Architectures = append(image.Architectures, i.Architecture)
+				}
+				// digest of single architecture image is digest of its manifest
+				if image.Digest == "" && len(tag.Images) == 1 {
+					image.Digest = i.Digest
+				}
+			}
+			result = append(result, image)
+		}
+	}
+
+	return result, nil
+}
+
+func (d *DockerHub) repositories(namespace string) ([]img.ImageInfo, error) {
+	result := []img.ImageInfo{}
+
+	nextURL := d.url + namespace
+	for nextURL != "" {
+		imgResult := img.ImageResult{}
+		if err := d.get(nextURL, &imgResult); err != nil {
+			return nil, err
+		}
+
+		for _, aa := range imgResult.Results {
+			if strings.HasPrefix(aa.Name, consts.AiTrainImagePrefix) {
+				result = append(result, aa)
+			}
+		}
+
+		nextURL = ""
+		if imgResult.Next != nil {
+			nextURL = *imgResult.Next
+		}
+	}
+	return result, nil
+}
+
+func (d *DockerHub) tags(namespace, name string) ([]img.TagInfo, error) {
+	result := []img.TagInfo{}
+
+	nextURL := d.url + strings.Join([]string{namespace, name, "tags"}, "/")
+	for nextURL != "" {
+		tagResult := img.TagResult{}
+		if err := d.get(nextURL, &tagResult); err != nil {
+			return nil, err
+		}
+
+		result = append(result, tagResult.Results...)
+
+		nextURL = ""
+		if tagResult.Next != nil {
+			nextURL = *tagResult.Next
+		}
+	}
+	return result, nil
+}
+
+// get decodes json response of url into v. Non-existing namespace is not an error, v is left empty.
+func (d *DockerHub) get(url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Cache-Control", "no-cache")
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return httpError(res)
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
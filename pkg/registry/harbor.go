@@ -0,0 +1,170 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+)
+
+const harborPageSize = 100
+
+// Harbor lists images of projects by Harbor v2.0 api, credentials of user or robot account are sent as basic auth.
+// Anonymous user can only list public projects.
+type Harbor struct {
+	base
+	host string
+	url  *url.URL
+}
+
+func NewHarbor(cfg config.RegistryConfig) (*Harbor, error) {
+	h, u, err := host(cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	return &Harbor{
+		base: newBase(cfg),
+		host: h,
+		url:  u,
+	}, nil
+}
+
+type harborRepository struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type harborArtifact struct {
+	Digest   string `json:"digest"`
+	Size     int64  `json:"size"`
+	PushTime string `json:"push_time"`
+	Tags     []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+	// image config of single architecture image
+	ExtraAttrs struct {
+		Architecture string `json:"architecture"`
+		Created      string `json:"created"`
+		Config       struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	} `json:"extra_attrs"`
+	// images of multi-architecture image
+	References []struct {
+		Platform struct {
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"references"`
+}
+
+// ListImages returns <host>/<project>/<name>:<tag> of tagged artifacts in project. Labels of multi-architecture
+// image are not read, since Harbor does not tell labels of index.
+func (h *Harbor) ListImages(project string) ([]Image, error) {
+	repos, err := h.projectRepositories(project)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Image{}
+	for _, repo := range repos {
+		artifacts, err := h.artifacts(project, repo.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range artifacts {
+			for _, tag := range a.Tags {
+				image := Image{
+					Name:        fmt.Sprintf("%s/%s", h.host, repo.Name),
+					Tag:         tag.Name,
+					Digest:      a.Digest,
+					Size:        a.Size,
+					LastUpdated: parseTime(a.ExtraAttrs.Created),
+					Description: repo.Description,
+				}
+				if image.LastUpdated == nil {
+					image.LastUpdated = parseTime(a.PushTime)
+				}
+				if a.ExtraAttrs.Architecture != "" {
+					image.Architectures = []string{a.ExtraAttrs.Architecture}
+				}
+				for _, r := range a.References {
+					arch := r.Platform.Architecture
+					if arch != "" && arch != "unknown" && !image.HasArchitecture(arch) {
+						image.Architectures = append(image.Architectures, arch)
+					}
+				}
+				image.applyLabels(a.ExtraAttrs.Config.Labels)
+				result = append(result, image)
+			}
+		}
+	}
+	return result, nil
+}
+
+// projectRepositories returns repositories in project, whose name is <project>/<name>. Non-existing project has no repository.
+func (h *Harbor) projectRepositories(project string) ([]harborRepository, error) {
+	result := []harborRepository{}
+	for page := 1; ; page++ {
+		repos := []harborRepository{}
+		err := h.get(fmt.Sprintf("/api/v2.0/projects/%s/repositories?page=%d&page_size=%d",
+			url.PathEscape(project), page, harborPageSize), &repos)
+		if isStatus(err, http.StatusNotFound) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, repos...)
+		if len(repos) < harborPageSize {
+			return result, nil
+		}
+	}
+}
+
+// artifacts returns artifacts with tags of repository, repo is full name returned by projectRepositories.
+func (h *Harbor) artifacts(project, repo string) ([]harborArtifact, error) {
+	// repository name in path excludes project, and slash in it is encoded twice
+	name := url.PathEscape(url.PathEscape(strings.TrimPrefix(repo, project+"/")))
+
+	result := []harborArtifact{}
+	for page := 1; ; page++ {
+		artifacts := []harborArtifact{}
+		err := h.get(fmt.Sprintf("/api/v2.0/projects/%s/repositories/%s/artifacts?with_tag=true&with_label=false&page=%d&page_size=%d",
+			url.PathEscape(project), name, page, harborPageSize), &artifacts)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, artifacts...)
+		if len(artifacts) < harborPageSize {
+			return result, nil
+		}
+	}
+}
+
+// get decodes json response of ref, which is relative to registry url, into v.
+func (h *Harbor) get(ref string, v interface{}) error {
+	u, err := h.url.Parse(ref)
+	if err != nil {
+		return err
+	}
+
+	req := newGetRequest(u, "application/json")
+	if h.cfg.Username != "" {
+		req.SetBasicAuth(h.cfg.Username, h.cfg.Password)
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return httpError(res)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/consts"
+)
+
+// Image is a tagged image in registry, metadata is filled as far as registry api tells.
+type Image struct {
+	// reference without tag, eg: nchcai/train or harbor.example.com/ai/pytorch
+	Name string `json:"name"`
+	Tag  string `json:"tag"`
+	// digest of manifest, or index of multi-architecture image
+	Digest        string     `json:"digest,omitempty"`
+	Size          int64      `json:"size,omitempty"` // bytes of compressed layers
+	Architectures []string   `json:"architectures,omitempty"`
+	LastUpdated   *time.Time `json:"lastUpdated,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	Gpu           bool       `json:"gpu"`
+}
+
+// Ref returns name:tag of image, or name@digest if image is referred by digest only.
+func (i *Image) Ref() string {
+	if i.Tag == "" {
+		return i.Pinned()
+	}
+	return fmt.Sprintf("%s:%s", i.Name, i.Tag)
+}
+
+// Pinned returns name@digest of image, which always pulls the same image even if tag is moved.
+// Empty string is returned if digest is unknown.
+func (i *Image) Pinned() string {
+	if i.Digest == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s@%s", i.Name, i.Digest)
+}
+
+// HasHost checks name of image is prefixed by a registry host rather than hosted on Docker Hub.
+func (i *Image) HasHost() bool {
+	return hasHost(i.Name)
+}
+
+// HasArchitecture checks image is built for arch.
+func (i *Image) HasArchitecture(arch string) bool {
+	for _, a := range i.Architectures {
+		if a == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// Image labels telling image is built for GPU, nvidia labels are set by nvidia/cuda base images.
+var gpuLabels = []string{
+	consts.ImageGpuLabel,
+	"com.nvidia.cuda.version",
+	"com.nvidia.volumes.needed",
+}
+
+const descriptionLabel = "org.opencontainers.image.description"
+
+// applyLabels fills description and gpu of image from labels of image config.
+func (i *Image) applyLabels(labels map[string]string) {
+	if d := labels[descriptionLabel]; d != "" {
+		i.Description = d
+	}
+	for _, l := range gpuLabels {
+		if v, ok := labels[l]; ok && v != "false" {
+			i.Gpu = true
+			return
+		}
+	}
+}
+
+var (
+	referenceHostRegexp = regexp.MustCompile(`^[a-zA-Z0-9.-]+(?::[0-9]+)?$`)
+	referenceNameRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*$`)
+	referenceTagRegexp  = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	digestRegexp        = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// ParseReference parses image reference name[:tag][@digest]. Tag is latest if neither tag nor digest is given.
+func ParseReference(ref string) (Image, error) {
+	result := Image{}
+	name := ref
+
+	if idx := strings.Index(name, "@"); idx >= 0 {
+		name, result.Digest = name[:idx], name[idx+1:]
+		if !digestRegexp.MatchString(result.Digest) {
+			return Image{}, fmt.Errorf("invalid digest {%s} of image {%s}", result.Digest, ref)
+		}
+	}
+
+	// colon after the last slash separates tag, colon before it is port of registry host
+	if idx := strings.LastIndex(name, ":"); idx > strings.LastIndex(name, "/") {
+		name, result.Tag = name[:idx], name[idx+1:]
+		if !referenceTagRegexp.MatchString(result.Tag) {
+			return Image{}, fmt.Errorf("invalid tag {%s} of image {%s}", result.Tag, ref)
+		}
+	} else if result.Digest == "" {
+		result.Tag = "latest"
+	}
+
+	// first component is registry host only if it looks like one, otherwise it is Docker Hub user
+	path := name
+	if hasHost(name) {
+		idx := strings.Index(name, "/")
+		if !referenceHostRegexp.MatchString(name[:idx]) {
+			return Image{}, fmt.Errorf("invalid host {%s} of image {%s}", name[:idx], ref)
+		}
+		path = name[idx+1:]
+	}
+	if len(name) > 255 || !referenceNameRegexp.MatchString(path) {
+		return Image{}, fmt.Errorf("invalid name {%s} of image {%s}", name, ref)
+	}
+	result.Name = name
+	return result, nil
+}
+
+// gpuFromName guesses image is built for GPU from its name and tag, used when registry does not tell labels.
+func gpuFromName(name, tag string) bool {
+	s := strings.ToLower(name + ":" + tag)
+	return strings.Contains(s, "gpu") || strings.Contains(s, "cuda")
+}
+
+func parseTime(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil || t.IsZero() {
+		return nil
+	}
+	return &t
+}
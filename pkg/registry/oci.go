@@ -0,0 +1,372 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	log "github.com/golang/glog"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+)
+
+const ociPageSize = 100
+
+// media types of manifest and index, docker media types are still used by many registries
+const (
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+var manifestAccept = strings.Join([]string{mediaTypeOCIIndex, mediaTypeDockerList, mediaTypeOCIManifest, mediaTypeDockerManifest}, ", ")
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+// ociManifest is either image manifest or index, index has manifests and image manifest has config and layers.
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+type ociImageConfig struct {
+	Architecture string `json:"architecture"`
+	Created      string `json:"created"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+var (
+	challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	nextLinkRegexp       = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+)
+
+// OCI lists images by OCI Distribution v2 api, i.e. /v2/_catalog and /v2/<name>/tags/list.
+// Credentials are sent as basic auth, or exchanged for bearer token, as challenged by registry.
+type OCI struct {
+	base
+	host string
+	url  *url.URL
+}
+
+func NewOCI(cfg config.RegistryConfig) (*OCI, error) {
+	h, u, err := host(cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	return &OCI{
+		base: newBase(cfg),
+		host: h,
+		url:  u,
+	}, nil
+}
+
+// ListImages returns <host>/<name>:<tag> of repository and repositories under its path.
+// Metadata is read from manifest and image config of each tag. Tag failed to inspect, eg: manifest of unsupported
+// media type, is skipped, and error is only returned if no tag can be inspected.
+func (o *OCI) ListImages(repository string) ([]Image, error) {
+	names, err := o.Catalog(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Image{}
+	var lastErr error
+	for _, name := range names {
+		tags, err := o.Tags(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			image, err := o.Inspect(name, tag)
+			if err != nil {
+				log.Warningf("Inspect image {%s:%s} of registry {%s} fail, skip it: %s", name, tag, o.Name(), err.Error())
+				lastErr = err
+				continue
+			}
+			result = append(result, *image)
+		}
+	}
+	if len(result) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return result, nil
+}
+
+// Catalog returns repository and repositories under its path in catalog. Many registries do not allow catalog,
+// repository itself is returned in that case.
+func (o *OCI) Catalog(repository string) ([]string, error) {
+	repository = strings.Trim(repository, "/")
+
+	result := []string{}
+	next := fmt.Sprintf("/v2/_catalog?n=%d", ociPageSize)
+	for next != "" {
+		catalog := struct {
+			Repositories []string `json:"repositories"`
+		}{}
+		link, err := o.get(next, &catalog)
+		if err != nil {
+			if repository != "" && isStatus(err, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound) {
+				return []string{repository}, nil
+			}
+			return nil, err
+		}
+
+		for _, name := range catalog.Repositories {
+			if repository == "" || name == repository || strings.HasPrefix(name, repository+"/") {
+				result = append(result, name)
+			}
+		}
+		next = link
+	}
+	return result, nil
+}
+
+// Tags returns tags of repository, non-existing repository has no tag.
+func (o *OCI) Tags(name string) ([]string, error) {
+	result := []string{}
+	next := fmt.Sprintf("/v2/%s/tags/list?n=%d", name, ociPageSize)
+	for next != "" {
+		tags := struct {
+			Tags []string `json:"tags"`
+		}{}
+		link, err := o.get(next, &tags)
+		if isStatus(err, http.StatusNotFound) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, tags.Tags...)
+		next = link
+	}
+	return result, nil
+}
+
+// get decodes json response of ref, which is relative to registry url, into v and returns next page link.
+func (o *OCI) get(ref string, v interface{}) (string, error) {
+	res, err := o.do(ref, "application/json")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", httpError(res)
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return "", err
+	}
+
+	if m := nextLinkRegexp.FindStringSubmatch(res.Header.Get("Link")); m != nil {
+		return m[1], nil
+	}
+	return "", nil
+}
+
+// do sends request of ref accepting media types, and sends again with credentials if it is challenged.
+func (o *OCI) do(ref string, accept string) (*http.Response, error) {
+	u, err := o.url.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := o.client.Do(newGetRequest(u, accept))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	scheme, params := parseChallenge(res.Header.Get("WWW-Authenticate"))
+	req := newGetRequest(u, accept)
+	switch scheme {
+	case "basic":
+		if o.cfg.Username == "" {
+			return res, nil
+		}
+		req.SetBasicAuth(o.cfg.Username, o.cfg.Password)
+	case "bearer":
+		token, err := o.token(params)
+		if err != nil {
+			res.Body.Close()
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	default:
+		return res, nil
+	}
+	res.Body.Close()
+
+	return o.client.Do(req)
+}
+
+// token requests bearer token from realm of challenge, credentials are sent if configured.
+func (o *OCI) token(params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid realm {%s} of registry {%s}", params["realm"], o.cfg.Name)
+	}
+
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if params[k] != "" {
+			q.Set(k, params[k])
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req := newGetRequest(realm, "application/json")
+	if o.cfg.Username != "" {
+		req.SetBasicAuth(o.cfg.Username, o.cfg.Password)
+	}
+	res, err := o.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", httpError(res)
+	}
+
+	result := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	if result.AccessToken != "" {
+		return result.AccessToken, nil
+	}
+	return "", fmt.Errorf("token of registry {%s} is empty", o.cfg.Name)
+}
+
+// parseChallenge parses WWW-Authenticate header, eg: Bearer realm="https://auth.example.com/token",service="registry"
+func parseChallenge(header string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) == 2 {
+		for _, m := range challengeParamRegexp.FindAllStringSubmatch(parts[1], -1) {
+			params[strings.ToLower(m[1])] = m[2]
+		}
+	}
+	return strings.ToLower(parts[0]), params
+}
+
+func newGetRequest(u *url.URL, accept string) *http.Request {
+	return &http.Request{
+		Method: "GET",
+		URL:    u,
+		Header: http.Header{"Accept": []string{accept}},
+		Host:   u.Host,
+	}
+}
+
+// Inspect reads digest, size, architectures, creation time and labels of image name:tag.
+// Size and labels of multi-architecture image are from its first image.
+func (o *OCI) Inspect(name, tag string) (*Image, error) {
+	image := Image{
+		Name: fmt.Sprintf("%s/%s", o.host, name),
+		Tag:  tag,
+	}
+
+	manifest, digest, err := o.manifest(name, tag)
+	if err != nil {
+		return nil, err
+	}
+	image.Digest = digest
+
+	if len(manifest.Manifests) > 0 {
+		first := ""
+		for _, m := range manifest.Manifests {
+			// attestations are listed with unknown platform
+			if m.Platform == nil || m.Platform.Architecture == "" || m.Platform.Architecture == "unknown" {
+				continue
+			}
+			if first == "" {
+				first = m.Digest
+			}
+			if !image.HasArchitecture(m.Platform.Architecture) {
+				image.Architectures = append(image.Architectures, m.Platform.Architecture)
+			}
+		}
+		if first == "" {
+			return &image, nil
+		}
+		if manifest, _, err = o.manifest(name, first); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, l := range manifest.Layers {
+		image.Size += l.Size
+	}
+
+	if manifest.Config.Digest == "" {
+		return &image, nil
+	}
+	config := ociImageConfig{}
+	if _, err := o.get(fmt.Sprintf("/v2/%s/blobs/%s", name, manifest.Config.Digest), &config); err != nil {
+		return nil, err
+	}
+	if len(image.Architectures) == 0 && config.Architecture != "" {
+		image.Architectures = []string{config.Architecture}
+	}
+	image.LastUpdated = parseTime(config.Created)
+	image.applyLabels(config.Config.Labels)
+
+	return &image, nil
+}
+
+// manifest returns manifest or index of reference, which is tag or digest, and its digest.
+func (o *OCI) manifest(name, reference string) (*ociManifest, string, error) {
+	res, err := o.do(fmt.Sprintf("/v2/%s/manifests/%s", name, reference), manifestAccept)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", httpError(res)
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	manifest := ociManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, "", err
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	}
+	return &manifest, digest, nil
+}
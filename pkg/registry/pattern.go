@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Image patterns select images in allowlist, eg:
+//   nchcai/train:v1                   the tag only
+//   nchcai/train                      any tag or digest of repository
+//   nchcai/train:v1.*                 tags matching glob
+//   nchcai/*                          repositories in nchcai, * does not cross /
+//   harbor.example.com/ai/**          repositories under harbor.example.com/ai at any depth
+//   nchcai/train@sha256:<64 hex>      the digest only
+//   docker.io/**                      any image on Docker Hub
+// Pattern with tag does not match image referred by digest only, since its tag is unknown.
+// Names of pattern and image on Docker Hub are normalized before matching, see NormalizeName.
+
+// dockerHubHosts are hosts of Docker Hub, which are omitted in familiar image names, eg: nchcai/train.
+var dockerHubHosts = []string{"docker.io/", "index.docker.io/", "registry-1.docker.io/", "registry.hub.docker.com/"}
+
+// ValidatePattern checks pattern is an image reference which may contain wildcards.
+func ValidatePattern(pattern string) error {
+	if strings.Contains(pattern, "@") && strings.Contains(pattern, "*") {
+		return fmt.Errorf("digest pattern {%s} cannot contain wildcard", pattern)
+	}
+
+	name, tag, digest := splitPattern(pattern)
+	if strings.Contains(strings.TrimSuffix(name, "/**"), "**") {
+		return fmt.Errorf("** is only allowed at the end of pattern {%s}", pattern)
+	}
+
+	// wildcards are replaced, so pattern is valid if what it looks like is a valid reference
+	ref := strings.NewReplacer("**", "x", "*", "x", "?", "x").Replace(name)
+	if tag != "" {
+		ref += ":" + strings.NewReplacer("*", "x", "?", "x").Replace(tag)
+	}
+	if digest != "" {
+		ref += "@" + digest
+	}
+	if _, err := ParseReference(ref); err != nil {
+		return fmt.Errorf("invalid image pattern {%s}: %s", pattern, err.Error())
+	}
+	if _, err := path.Match(name, ""); err != nil {
+		return fmt.Errorf("invalid image pattern {%s}: %s", pattern, err.Error())
+	}
+	return nil
+}
+
+// MatchPattern checks image matches pattern, invalid pattern matches nothing.
+func MatchPattern(pattern string, image Image) bool {
+	name, tag, digest := splitPattern(pattern)
+	name, imageName := NormalizeName(name), NormalizeName(image.Name)
+
+	if name == "**" {
+		// docker.io/** is any image on Docker Hub
+		if hasHost(imageName) {
+			return false
+		}
+	} else if strings.HasSuffix(name, "/**") {
+		if !strings.HasPrefix(imageName, strings.TrimSuffix(name, "**")) {
+			return false
+		}
+	} else if ok, err := path.Match(name, imageName); err != nil || !ok {
+		return false
+	}
+
+	if digest != "" {
+		return image.Digest == digest
+	}
+	if tag != "" {
+		if image.Tag == "" {
+			return false
+		}
+		ok, err := path.Match(tag, image.Tag)
+		return err == nil && ok
+	}
+	return true
+}
+
+// NormalizeName converts name of image or pattern on Docker Hub into familiar form, i.e. without host of Docker Hub,
+// and without library/ of official images, eg: docker.io/library/ubuntu is ubuntu, docker.io/nchcai/train is nchcai/train.
+func NormalizeName(name string) string {
+	for _, h := range dockerHubHosts {
+		if strings.HasPrefix(name, h) {
+			name = strings.TrimPrefix(name, h)
+			break
+		}
+	}
+
+	if rest := strings.TrimPrefix(name, "library/"); rest != name && !strings.Contains(rest, "/") {
+		// official images are single component names
+		if rest == "**" {
+			return "*"
+		}
+		return rest
+	}
+	return name
+}
+
+// hasHost checks first component of name is a registry host rather than Docker Hub user.
+func hasHost(name string) bool {
+	idx := strings.Index(name, "/")
+	if idx < 0 {
+		return false
+	}
+	first := name[:idx]
+	return strings.ContainsAny(first, ".:") || first == "localhost"
+}
+
+// splitPattern splits pattern into name, tag and digest, which are empty if not given.
+func splitPattern(pattern string) (string, string, string) {
+	name, tag, digest := pattern, "", ""
+	if idx := strings.Index(name, "@"); idx >= 0 {
+		name, digest = name[:idx], name[idx+1:]
+	}
+	if idx := strings.LastIndex(name, ":"); idx > strings.LastIndex(name, "/") {
+		name, tag = name[:idx], name[idx+1:]
+	}
+	return name, tag, digest
+}
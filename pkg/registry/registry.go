@@ -0,0 +1,164 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nchc-ai/backend-api/pkg/consts"
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+)
+
+// type of registry backend
+const (
+	TypeDockerHub = "dockerhub"
+	TypeOCI       = "oci"
+	TypeHarbor    = "harbor"
+)
+
+const requestTimeout = 30 * time.Second
+
+// Registry lists images in repositories of a container registry.
+type Registry interface {
+	// Name is name of registry in configuration
+	Name() string
+	// Repositories are repositories listed for everyone
+	Repositories() []string
+	// UserRepository checks repository of user profile is listed in this registry
+	UserRepository() bool
+	// ListImages returns every tagged image in repository, whose reference can be pulled by kubernetes as is.
+	// Meaning of repository depends on backend, it is namespace in Docker Hub, project in Harbor,
+	// and repository name or its parent path in OCI registry.
+	ListImages(repository string) ([]Image, error)
+}
+
+// New creates registry backend of cfg.Type.
+func New(cfg config.RegistryConfig) (Registry, error) {
+	switch cfg.Type {
+	case TypeDockerHub, "":
+		return NewDockerHub(cfg), nil
+	case TypeOCI:
+		if cfg.Url == "" {
+			return nil, fmt.Errorf("url of oci registry {%s} is empty", cfg.Name)
+		}
+		return NewOCI(cfg)
+	case TypeHarbor:
+		if cfg.Url == "" {
+			return nil, fmt.Errorf("url of harbor registry {%s} is empty", cfg.Name)
+		}
+		return NewHarbor(cfg)
+	default:
+		return nil, fmt.Errorf("unknown type {%s} of registry {%s}", cfg.Type, cfg.Name)
+	}
+}
+
+// NewAll creates all registries in configuration. If none is configured, Docker Hub with nchcai repository
+// and user repository is used, which is the original image catalog.
+func NewAll(cfgs []config.RegistryConfig) ([]Registry, error) {
+	if len(cfgs) == 0 {
+		cfgs = []config.RegistryConfig{DefaultConfig()}
+	}
+
+	result := []Registry{}
+	names := map[string]bool{}
+	for _, cfg := range cfgs {
+		if cfg.Name == "" {
+			return nil, errors.New("name of registry is empty")
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("registry {%s} is duplicated", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		r, err := New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// DefaultConfig is Docker Hub with nchcai repository and user repository.
+func DefaultConfig() config.RegistryConfig {
+	return config.RegistryConfig{
+		Name:           TypeDockerHub,
+		Type:           TypeDockerHub,
+		Repositories:   []string{consts.AiTrainUser},
+		UserRepository: true,
+	}
+}
+
+// base holds configuration shared by all backends.
+type base struct {
+	cfg    config.RegistryConfig
+	client *http.Client
+}
+
+func newBase(cfg config.RegistryConfig) base {
+	return base{
+		cfg:    cfg,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (b *base) Name() string {
+	return b.cfg.Name
+}
+
+func (b *base) Repositories() []string {
+	return b.cfg.Repositories
+}
+
+func (b *base) UserRepository() bool {
+	return b.cfg.UserRepository
+}
+
+// host returns host of registry url, which is prefix of image reference.
+func host(rawURL string) (string, *url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(rawURL, "/"))
+	if err != nil {
+		return "", nil, err
+	}
+	if u.Host == "" {
+		return "", nil, fmt.Errorf("host of registry url {%s} is empty", rawURL)
+	}
+	return u.Host, u, nil
+}
+
+// statusError is unexpected http status returned by registry.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+func httpError(res *http.Response) error {
+	return &statusError{
+		code: res.StatusCode,
+		msg:  fmt.Sprintf("%s %s: %s", res.Request.Method, res.Request.URL.Redacted(), res.Status),
+	}
+}
+
+// isStatus checks err is statusError of one of codes.
+func isStatus(err error, codes ...int) bool {
+	var se *statusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	for _, code := range codes {
+		if se.code == code {
+			return true
+		}
+	}
+	return false
+}
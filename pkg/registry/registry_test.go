@@ -0,0 +1,379 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nchc-ai/backend-api/pkg/model/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func refs(images []Image) []string {
+	result := []string{}
+	for _, i := range images {
+		result = append(result, i.Ref())
+	}
+	return result
+}
+
+// serveManifest serves manifests and image config of OCI registry, tag multi is an index of amd64 and arm64 images.
+func serveManifest(w http.ResponseWriter, r *http.Request) bool {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/manifests/multi"):
+		w.Header().Set("Docker-Content-Digest", "sha256:"+strings.Repeat("a", 64))
+		writeJSON(w, map[string]interface{}{
+			"mediaType": mediaTypeOCIIndex,
+			"manifests": []map[string]interface{}{
+				{"digest": "sha256:amd", "platform": map[string]string{"architecture": "amd64", "os": "linux"}},
+				{"digest": "sha256:arm", "platform": map[string]string{"architecture": "arm64", "os": "linux"}},
+				{"digest": "sha256:att", "platform": map[string]string{"architecture": "unknown", "os": "unknown"}},
+			},
+		})
+	case strings.Contains(r.URL.Path, "/manifests/"):
+		// digest is computed from body without Docker-Content-Digest
+		writeJSON(w, map[string]interface{}{
+			"mediaType": mediaTypeOCIManifest,
+			"config":    map[string]interface{}{"digest": "sha256:config", "size": 10},
+			"layers":    []map[string]interface{}{{"size": 100}, {"size": 200}},
+		})
+	case strings.HasSuffix(r.URL.Path, "/blobs/sha256:config"):
+		writeJSON(w, map[string]interface{}{
+			"architecture": "amd64",
+			"created":      "2020-01-02T03:04:05Z",
+			"config": map[string]interface{}{
+				"Labels": map[string]string{"com.nvidia.cuda.version": "10.1", descriptionLabel: "cuda image"},
+			},
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+func TestNewAll(t *testing.T) {
+	registries, err := NewAll(nil)
+	assert.NoError(t, err)
+	assert.Len(t, registries, 1)
+	assert.Equal(t, []string{"nchcai"}, registries[0].Repositories())
+	assert.True(t, registries[0].UserRepository())
+
+	_, err = NewAll([]config.RegistryConfig{{Name: "a"}, {Name: "a"}})
+	assert.Error(t, err)
+
+	_, err = NewAll([]config.RegistryConfig{{Name: "a", Type: TypeOCI}})
+	assert.Error(t, err)
+
+	_, err = NewAll([]config.RegistryConfig{{Name: "a", Type: "quay"}})
+	assert.Error(t, err)
+
+	registries, err = NewAll([]config.RegistryConfig{
+		{Name: "hub"},
+		{Name: "private", Type: TypeOCI, Url: "registry.local:5000"},
+		{Name: "harbor", Type: TypeHarbor, Url: "https://harbor.local"},
+	})
+	assert.NoError(t, err)
+	assert.IsType(t, &DockerHub{}, registries[0])
+	assert.Equal(t, "registry.local:5000", registries[1].(*OCI).host)
+	assert.Equal(t, "harbor.local", registries[2].(*Harbor).host)
+}
+
+func TestDockerHub(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ns" && r.URL.Query().Get("page") == "":
+			next := srv.URL + "/ns?page=2"
+			writeJSON(w, map[string]interface{}{"next": next, "results": []map[string]string{{"name": "train", "description": "training image"}, {"name": "other"}}})
+		case r.URL.Path == "/ns":
+			writeJSON(w, map[string]interface{}{"next": nil, "results": []map[string]string{{"name": "train-gpu"}}})
+		case r.URL.Path == "/ns/train/tags":
+			writeJSON(w, map[string]interface{}{"results": []map[string]interface{}{
+				{"name": "latest", "digest": "sha256:list", "full_size": 300, "last_updated": "2020-05-06T07:08:09.123456Z", "images": []map[string]interface{}{
+					{"architecture": "amd64", "digest": "sha256:amd"}, {"architecture": "arm64", "digest": "sha256:arm"}, {"architecture": "unknown"},
+				}},
+				{"name": "v1"},
+			}})
+		case r.URL.Path == "/ns/train-gpu/tags":
+			writeJSON(w, map[string]interface{}{"results": []map[string]interface{}{
+				{"name": "cuda", "images": []map[string]interface{}{{"architecture": "amd64", "digest": "sha256:single"}}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	hub := NewDockerHub(config.RegistryConfig{Name: "hub", Url: srv.URL})
+	images, err := hub.ListImages("ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ns/train:latest", "ns/train:v1", "ns/train-gpu:cuda"}, refs(images))
+	assert.Equal(t, "training image", images[0].Description)
+	assert.Equal(t, int64(300), images[0].Size)
+	assert.Equal(t, []string{"amd64", "arm64"}, images[0].Architectures)
+	assert.Equal(t, "ns/train@sha256:list", images[0].Pinned())
+	assert.Equal(t, 2020, images[0].LastUpdated.Year())
+	assert.False(t, images[0].Gpu)
+	assert.Equal(t, "ns/train-gpu@sha256:single", images[2].Pinned())
+	assert.True(t, images[2].Gpu)
+
+	// non-existing namespace
+	images, err = hub.ListImages("nobody")
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestOCIBearer(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "robot" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			writeJSON(w, map[string]string{"token": "t-" + r.URL.Query().Get("scope")})
+			return
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer t-") {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="%s"`, srv.URL, r.URL.Path))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/_catalog":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/_catalog?last=course%2Fpytorch&n=100>; rel="next"`)
+				writeJSON(w, map[string][]string{"repositories": {"course", "course/pytorch"}})
+				return
+			}
+			writeJSON(w, map[string][]string{"repositories": {"course/tf", "courses", "other/x"}})
+		case "/v2/course/tags/list":
+			writeJSON(w, map[string][]string{"tags": {"base"}})
+		case "/v2/course/pytorch/tags/list":
+			writeJSON(w, map[string][]string{"tags": {"1.0", "multi"}})
+		case "/v2/course/tf/tags/list":
+			writeJSON(w, map[string][]string{"tags": nil})
+		default:
+			if !serveManifest(w, r) {
+				http.NotFound(w, r)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	oci, err := NewOCI(config.RegistryConfig{Name: "private", Url: srv.URL, Username: "robot", Password: "secret"})
+	assert.NoError(t, err)
+
+	h := strings.TrimPrefix(srv.URL, "http://")
+	images, err := oci.ListImages("course")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{h + "/course:base", h + "/course/pytorch:1.0", h + "/course/pytorch:multi"}, refs(images))
+
+	single := images[0]
+	assert.True(t, strings.HasPrefix(single.Digest, "sha256:"))
+	assert.Equal(t, int64(300), single.Size)
+	assert.Equal(t, []string{"amd64"}, single.Architectures)
+	assert.Equal(t, "cuda image", single.Description)
+	assert.True(t, single.Gpu)
+	assert.Equal(t, 2020, single.LastUpdated.Year())
+
+	multi := images[2]
+	assert.Equal(t, h+"/course/pytorch@sha256:"+strings.Repeat("a", 64), multi.Pinned())
+	assert.Equal(t, []string{"amd64", "arm64"}, multi.Architectures)
+	assert.Equal(t, int64(300), multi.Size)
+
+	// token is rejected without credentials
+	anonymous, _ := NewOCI(config.RegistryConfig{Name: "private", Url: srv.URL})
+	_, err = anonymous.ListImages("course")
+	assert.True(t, isStatus(err, http.StatusUnauthorized))
+}
+
+func TestOCIBasicWithoutCatalog(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "u" || pass != "p" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/_catalog":
+			w.WriteHeader(http.StatusForbidden)
+		case "/v2/team/app/tags/list":
+			writeJSON(w, map[string][]string{"tags": {"v1", "broken"}})
+		case "/v2/team/app/manifests/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			if !serveManifest(w, r) {
+				http.NotFound(w, r)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	oci, err := NewOCI(config.RegistryConfig{Name: "private", Url: srv.URL, Username: "u", Password: "p"})
+	assert.NoError(t, err)
+
+	// tag failed to inspect is skipped
+	h := strings.TrimPrefix(srv.URL, "http://")
+	images, err := oci.ListImages("team/app")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{h + "/team/app:v1"}, refs(images))
+
+	images, err = oci.ListImages("team/none")
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestHarbor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "robot$ai" || pass != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.EscapedPath() {
+		case "/api/v2.0/projects/ai/repositories":
+			repos := []map[string]string{}
+			if r.URL.Query().Get("page") == "1" {
+				for i := 0; i < harborPageSize-1; i++ {
+					repos = append(repos, map[string]string{"name": fmt.Sprintf("ai/empty%d", i)})
+				}
+				repos = append(repos, map[string]string{"name": "ai/team/app", "description": "team app"})
+			} else {
+				repos = append(repos, map[string]string{"name": "ai/base"})
+			}
+			writeJSON(w, repos)
+		case "/api/v2.0/projects/ai/repositories/team%252Fapp/artifacts":
+			writeJSON(w, []map[string]interface{}{
+				{"digest": "sha256:1", "size": 1024, "push_time": "2021-01-01T00:00:00.000Z", "tags": []map[string]string{{"name": "v1"}, {"name": "latest"}},
+					"extra_attrs": map[string]interface{}{
+						"architecture": "amd64", "created": "2020-12-31T00:00:00Z",
+						"config": map[string]interface{}{"Labels": map[string]string{"nchc.ai/gpu": "true"}},
+					}},
+				{"digest": "sha256:2", "tags": nil},
+			})
+		case "/api/v2.0/projects/ai/repositories/base/artifacts":
+			writeJSON(w, []map[string]interface{}{{"digest": "sha256:3", "push_time": "2021-02-01T00:00:00.000Z", "tags": []map[string]string{{"name": "v2"}},
+				"references": []map[string]interface{}{
+					{"platform": map[string]string{"architecture": "amd64"}}, {"platform": map[string]string{"architecture": "arm64"}},
+				}}})
+		default:
+			if strings.HasSuffix(r.URL.Path, "/artifacts") {
+				writeJSON(w, []interface{}{})
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	harbor, err := NewHarbor(config.RegistryConfig{Name: "harbor", Url: srv.URL, Username: "robot$ai", Password: "token"})
+	assert.NoError(t, err)
+
+	h := strings.TrimPrefix(srv.URL, "http://")
+	images, err := harbor.ListImages("ai")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{h + "/ai/team/app:v1", h + "/ai/team/app:latest", h + "/ai/base:v2"}, refs(images))
+	assert.Equal(t, "team app", images[0].Description)
+	assert.Equal(t, int64(1024), images[0].Size)
+	assert.Equal(t, []string{"amd64"}, images[0].Architectures)
+	assert.Equal(t, 2020, images[0].LastUpdated.Year())
+	assert.True(t, images[0].Gpu)
+	assert.Equal(t, h+"/ai/base@sha256:3", images[2].Pinned())
+	assert.Equal(t, []string{"amd64", "arm64"}, images[2].Architectures)
+	assert.Equal(t, 2021, images[2].LastUpdated.Year())
+	assert.False(t, images[2].Gpu)
+
+	// non-existing project
+	images, err = harbor.ListImages("none")
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("0", 64)
+	for ref, expected := range map[string]Image{
+		"ubuntu":                                 {Name: "ubuntu", Tag: "latest"},
+		"nchcai/train:v1.0":                      {Name: "nchcai/train", Tag: "v1.0"},
+		"registry.local:5000/ai/pytorch":         {Name: "registry.local:5000/ai/pytorch", Tag: "latest"},
+		"registry.local:5000/ai/pytorch:2.0-gpu": {Name: "registry.local:5000/ai/pytorch", Tag: "2.0-gpu"},
+		"nchcai/train@" + digest:                 {Name: "nchcai/train", Digest: digest},
+		"nchcai/train:v1@" + digest:              {Name: "nchcai/train", Tag: "v1", Digest: digest},
+	} {
+		image, err := ParseReference(ref)
+		assert.NoError(t, err, ref)
+		assert.Equal(t, expected, image, ref)
+	}
+
+	for _, ref := range []string{"", "Ubuntu", "nchcai/train:", "nchcai/train@sha256:123", "nchcai//train", "NCHCAI/train", "harbor_example.com/train", "nchcai/train:v1 && ls"} {
+		_, err := ParseReference(ref)
+		assert.Error(t, err, ref)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("c", 64)
+	tagged := Image{Name: "nchcai/train", Tag: "v1.2", Digest: digest}
+	pinned := Image{Name: "harbor.local:443/ai/team/app", Digest: digest}
+
+	for pattern, expected := range map[string][]bool{
+		"nchcai/train":                           {true, false},
+		"nchcai/train:v1.2":                      {true, false},
+		"nchcai/train:v1.*":                      {true, false},
+		"nchcai/train:v2":                        {false, false},
+		"nchcai/*":                               {true, false},
+		"nchcai/train@" + digest:                 {true, false},
+		"harbor.local:443/ai/*":                  {false, false},
+		"harbor.local:443/ai/**":                 {false, true},
+		"harbor.local:443/ai/team/app:v1":        {false, false},
+		"harbor.local:443/ai/team/app@" + digest: {false, true},
+	} {
+		assert.NoError(t, ValidatePattern(pattern), pattern)
+		assert.Equal(t, expected[0], MatchPattern(pattern, tagged), pattern)
+		assert.Equal(t, expected[1], MatchPattern(pattern, pinned), pattern)
+	}
+
+	for _, pattern := range []string{"", "nchcai/**/train", "nchcai/*@" + digest, "nchcai/train@sha256:1", "nchcai/[", "nchcai/Train*"} {
+		assert.Error(t, ValidatePattern(pattern), pattern)
+	}
+}
+
+func TestMatchPatternDockerHub(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		image   string
+		match   bool
+	}{
+		{"docker.io/nchcai/train", "nchcai/train:v1", true},
+		{"nchcai/train", "docker.io/nchcai/train:v1", true},
+		{"index.docker.io/nchcai/*", "nchcai/train:v1", true},
+		{"ubuntu", "docker.io/library/ubuntu:22.04", true},
+		{"docker.io/library/ubuntu:22.*", "ubuntu:22.04", true},
+		{"library/*", "ubuntu", true},
+		{"docker.io/library/**", "nchcai/train", false},
+		{"docker.io/**", "nchcai/train", true},
+		{"docker.io/**", "ubuntu", true},
+		{"docker.io/**", "harbor.local/ai/app", false},
+		{"docker.io/nchcai/train", "harbor.local/nchcai/train", false},
+	} {
+		image, err := ParseReference(c.image)
+		assert.NoError(t, err, c.image)
+		assert.NoError(t, ValidatePattern(c.pattern), c.pattern)
+		assert.Equal(t, c.match, MatchPattern(c.pattern, image), "%s %s", c.pattern, c.image)
+	}
+}
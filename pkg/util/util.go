@@ -55,4 +55,8 @@ func GetConfig(isOutOfCluster bool, kubeConfigPath string) (*rest.Config, error)
 
 func Int32Ptr(i int32) *int32 { return &i }
 
+func Int64Ptr(i int64) *int64 { return &i }
+
+func IntPtr(i int) *int { return &i }
+
 func StringPtr(s string) *string { return &s }